@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// agentsMDFile is the cross-tool agent instructions file at the target root
+const agentsMDFile = "AGENTS.md"
+
+// Modes accepted by --agents-md
+const (
+	AgentsMDSync    = "sync"
+	AgentsMDPointer = "pointer"
+)
+
+// agentsPointerSection is the CLAUDE.md section that imports AGENTS.md in pointer mode
+const agentsPointerSection = "agents-md"
+
+// agentsMDHeader starts a newly created AGENTS.md
+const agentsMDHeader = `# AGENTS.md
+
+Instructions for AI coding agents working in this repository.
+`
+
+// agentsPointerBody makes Claude Code load AGENTS.md through an @import
+const agentsPointerBody = `Project instructions live in AGENTS.md so that every coding agent shares them:
+
+@AGENTS.md
+`
+
+// validateAgentsMDMode checks the --agents-md value
+func validateAgentsMDMode(mode string) error {
+	switch mode {
+	case "", AgentsMDSync, AgentsMDPointer:
+		return nil
+	default:
+		return fmt.Errorf("unknown --agents-md mode %q (expected %s or %s)", mode, AgentsMDSync, AgentsMDPointer)
+	}
+}
+
+// queueSections schedules managed sections for the memory files; they are
+// written once by writeMemoryFiles at the end of the run
+func (e *Engine) queueSections(sections ...ManagedSection) {
+	e.sections = append(e.sections, sections...)
+}
+
+// writeMemoryFiles writes the queued managed sections to CLAUDE.md and, in
+// --agents-md mode, keeps AGENTS.md consistent with it:
+//   - sync: every managed section appears in both files
+//   - pointer: AGENTS.md holds the sections and CLAUDE.md only imports it
+func (e *Engine) writeMemoryFiles() error {
+	mode := e.config.AgentsMD
+	if len(e.sections) == 0 && mode == "" {
+		return nil
+	}
+
+	claudePath := filepath.Join(e.config.TargetDir, claudeMDFile)
+	agentsPath := filepath.Join(e.config.TargetDir, agentsMDFile)
+
+	// Sections managed in either file are carried over to the other
+	var shared []ManagedSection
+	if mode != "" {
+		for _, path := range []string{agentsPath, claudePath} {
+			if !e.fs.Exists(path) {
+				continue
+			}
+			data, err := e.fs.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			for _, section := range managedSections(string(data)) {
+				if section.Name != agentsPointerSection {
+					shared = mergeSections(shared, []ManagedSection{section})
+				}
+			}
+		}
+	}
+	shared = mergeSections(shared, e.sections)
+
+	// Without --agents-md only the queued sections are written to CLAUDE.md
+	claudeSections := e.sections
+	if mode == AgentsMDSync {
+		claudeSections = shared
+	}
+
+	err := e.updateFile(claudePath, 0644, func(existing []byte) ([]byte, error) {
+		doc := string(existing)
+		if existing == nil {
+			doc = claudeMDHeader
+		}
+		if mode == AgentsMDPointer {
+			for _, section := range shared {
+				doc = removeManagedSection(doc, section.Name)
+			}
+			return []byte(upsertManagedSection(doc, agentsPointerSection, agentsPointerBody)), nil
+		}
+		doc = removeManagedSection(doc, agentsPointerSection)
+		for _, section := range claudeSections {
+			doc = upsertManagedSection(doc, section.Name, section.Body)
+		}
+		return []byte(doc), nil
+	})
+	if err != nil || mode == "" {
+		return err
+	}
+
+	return e.updateFile(agentsPath, 0644, func(existing []byte) ([]byte, error) {
+		doc := string(existing)
+		if existing == nil {
+			doc = agentsMDHeader
+		}
+		for _, section := range shared {
+			doc = upsertManagedSection(doc, section.Name, section.Body)
+		}
+		return []byte(doc), nil
+	})
+}
+
+// mergeSections overlays updates on base by name, appending new sections
+func mergeSections(base, updates []ManagedSection) []ManagedSection {
+	merged := append([]ManagedSection{}, base...)
+	for _, update := range updates {
+		replaced := false
+		for i := range merged {
+			if merged[i].Name == update.Name {
+				merged[i] = update
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, update)
+		}
+	}
+	return merged
+}
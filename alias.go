@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// expandAlias replaces an alias of the user configuration at the start of
+// args with the arguments it stands for. Commands win over aliases of the
+// same name, and expansion happens once, so aliases cannot refer to each
+// other.
+func expandAlias(args []string) ([]string, error) {
+	if len(args) == 0 || findCommand(args[0]) != nil {
+		return args, nil
+	}
+	user, err := loadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	value, ok := user.Aliases[args[0]]
+	if !ok {
+		return args, nil
+	}
+	expanded, err := splitArgs(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alias %s: %w", args[0], err)
+	}
+	if len(expanded) == 0 {
+		return nil, fmt.Errorf("alias %s is empty", args[0])
+	}
+	return append(expanded, args[1:]...), nil
+}
+
+// splitArgs splits s into arguments as a POSIX shell would, honoring single
+// and double quotes and backslash escapes but expanding nothing
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// aliasNames returns the aliases of the user configuration, sorted, for
+// the usage message; an unreadable configuration has none
+func aliasNames() ([]string, map[string]string) {
+	user, err := loadUserConfig()
+	if err != nil {
+		return nil, nil
+	}
+	names := make([]string, 0, len(user.Aliases))
+	for name := range user.Aliases {
+		if findCommand(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, user.Aliases
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ProjectInfo describes what AnalyzeProject learned about a repository
+type ProjectInfo struct {
+	Name           string
+	Languages      []string
+	ModulePath     string
+	PackageManager string
+	BuildCommands  []string
+	TestCommands   []string
+	LintCommands   []string
+	Directories    []string
+}
+
+// PrimaryLanguage returns the first detected language, or an empty string
+func (p *ProjectInfo) PrimaryLanguage() string {
+	if len(p.Languages) == 0 {
+		return ""
+	}
+	return p.Languages[0]
+}
+
+// firstOf returns the first command of a list, or an empty string
+func firstOf(commands []string) string {
+	if len(commands) == 0 {
+		return ""
+	}
+	return commands[0]
+}
+
+// TemplateVars returns the facts templates can use without declaring
+// variables, e.g. {{ .ModulePath }}; undetected facts are empty strings
+func (p *ProjectInfo) TemplateVars() map[string]interface{} {
+	return map[string]interface{}{
+		"ProjectName":     p.Name,
+		"ModulePath":      p.ModulePath,
+		"PackageManager":  p.PackageManager,
+		"PrimaryLanguage": p.PrimaryLanguage(),
+		"Languages":       p.Languages,
+		"BuildCommand":    firstOf(p.BuildCommands),
+		"TestCommand":     firstOf(p.TestCommands),
+		"LintCommand":     firstOf(p.LintCommands),
+	}
+}
+
+// skippedDirs are never listed in the directory layout
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"dist":         true,
+	"build":        true,
+	"__pycache__":  true,
+}
+
+// makeTargetPattern matches a Makefile rule name at the start of a line
+var makeTargetPattern = regexp.MustCompile(`^([A-Za-z0-9_.-]+)\s*:([^=]|$)`)
+
+// tomlNamePattern matches a `name = "..."` line in Cargo.toml or pyproject.toml
+var tomlNamePattern = regexp.MustCompile(`^\s*name\s*=\s*"([^"]+)"`)
+
+// AnalyzeProject inspects dir for manifests and build files
+func AnalyzeProject(dir string) *ProjectInfo {
+	info := &ProjectInfo{Name: filepath.Base(dir)}
+
+	// Makefile targets take precedence over language defaults
+	if targets := readMakeTargets(filepath.Join(dir, "Makefile")); len(targets) > 0 {
+		for _, target := range []string{"build", "test", "lint"} {
+			if !targets[target] {
+				continue
+			}
+			command := "make " + target
+			switch target {
+			case "build":
+				info.BuildCommands = append(info.BuildCommands, command)
+			case "test":
+				info.TestCommands = append(info.TestCommands, command)
+			case "lint":
+				info.LintCommands = append(info.LintCommands, command)
+			}
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		info.Languages = append(info.Languages, "Go")
+		info.ModulePath = goModulePath(data)
+		if info.PackageManager == "" {
+			info.PackageManager = "go"
+		}
+		info.BuildCommands = append(info.BuildCommands, "go build ./...")
+		info.TestCommands = append(info.TestCommands, "go test ./...")
+		info.LintCommands = append(info.LintCommands, "go vet ./...")
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		analyzePackageJSON(dir, data, info)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "Cargo.toml")); err == nil {
+		info.Languages = append(info.Languages, "Rust")
+		if name := tomlName(data); name != "" && info.ModulePath == "" {
+			info.ModulePath = name
+		}
+		if info.PackageManager == "" {
+			info.PackageManager = "cargo"
+		}
+		info.BuildCommands = append(info.BuildCommands, "cargo build")
+		info.TestCommands = append(info.TestCommands, "cargo test")
+		info.LintCommands = append(info.LintCommands, "cargo clippy")
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "pyproject.toml")); err == nil {
+		info.Languages = append(info.Languages, "Python")
+		if name := tomlName(data); name != "" && info.ModulePath == "" {
+			info.ModulePath = name
+		}
+		manager := "pip"
+		runner := ""
+		switch {
+		case fileExists(filepath.Join(dir, "uv.lock")):
+			manager, runner = "uv", "uv run "
+		case fileExists(filepath.Join(dir, "poetry.lock")):
+			manager, runner = "poetry", "poetry run "
+		case fileExists(filepath.Join(dir, "pdm.lock")):
+			manager, runner = "pdm", "pdm run "
+		}
+		if info.PackageManager == "" {
+			info.PackageManager = manager
+		}
+		info.TestCommands = append(info.TestCommands, runner+"pytest")
+	}
+
+	info.Directories = listTopLevelDirs(dir)
+	return info
+}
+
+// analyzePackageJSON records the name, package manager and scripts of a Node project
+func analyzePackageJSON(dir string, data []byte, info *ProjectInfo) {
+	var pkg struct {
+		Name    string            `json:"name"`
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return
+	}
+
+	language := "JavaScript"
+	if fileExists(filepath.Join(dir, "tsconfig.json")) {
+		language = "TypeScript"
+	}
+	info.Languages = append(info.Languages, language)
+	if pkg.Name != "" && info.ModulePath == "" {
+		info.ModulePath = pkg.Name
+	}
+
+	manager := "npm"
+	switch {
+	case fileExists(filepath.Join(dir, "pnpm-lock.yaml")):
+		manager = "pnpm"
+	case fileExists(filepath.Join(dir, "yarn.lock")):
+		manager = "yarn"
+	case fileExists(filepath.Join(dir, "bun.lockb")), fileExists(filepath.Join(dir, "bun.lock")):
+		manager = "bun"
+	}
+	if info.PackageManager == "" {
+		info.PackageManager = manager
+	}
+
+	for _, script := range []string{"build", "test", "lint"} {
+		if _, ok := pkg.Scripts[script]; !ok {
+			continue
+		}
+		command := manager + " run " + script
+		switch script {
+		case "build":
+			info.BuildCommands = append(info.BuildCommands, command)
+		case "test":
+			info.TestCommands = append(info.TestCommands, command)
+		case "lint":
+			info.LintCommands = append(info.LintCommands, command)
+		}
+	}
+}
+
+// goModulePath extracts the module path from go.mod content
+func goModulePath(data []byte) string {
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+		}
+	}
+	return ""
+}
+
+// tomlName returns the first `name = "..."` value in a TOML manifest
+func tomlName(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		if m := tomlNamePattern.FindStringSubmatch(line); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// readMakeTargets returns the rule names defined in a Makefile
+func readMakeTargets(path string) map[string]bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	targets := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if m := makeTargetPattern.FindStringSubmatch(line); m != nil {
+			targets[m[1]] = true
+		}
+	}
+	return targets
+}
+
+// listTopLevelDirs returns the sorted visible top-level directories of dir
+func listTopLevelDirs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") || skippedDirs[name] {
+			continue
+		}
+		dirs = append(dirs, name)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// fileExists reports whether path exists on the local filesystem
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
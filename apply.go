@@ -0,0 +1,389 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FilePatch is the change a patch makes to one file. The hashes are the git
+// object names of the file before and after, as recorded on the index line.
+type FilePatch struct {
+	Path    string
+	New     bool
+	Deleted bool
+	OldMode string
+	NewMode string
+	OldHash string
+	NewHash string
+	Hunks   []diffHunk
+}
+
+// parsePatch reads a patch in the format --emit-patch and git diff write.
+// Renames, copies and binary changes are not supported.
+func parsePatch(data []byte) ([]*FilePatch, error) {
+	var patches []*FilePatch
+	var fp *FilePatch
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	n := 0
+	next := func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		n++
+		return strings.TrimSuffix(scanner.Text(), "\r"), true
+	}
+	for line, ok := next(); ok; line, ok = next() {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			name, err := patchHeaderPath(strings.TrimPrefix(line, "diff --git "))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			fp = &FilePatch{Path: name}
+			patches = append(patches, fp)
+		case fp == nil:
+			// Text before the first file, such as a commit message
+		case strings.HasPrefix(line, "new file mode "):
+			fp.New, fp.NewMode = true, strings.TrimPrefix(line, "new file mode ")
+		case strings.HasPrefix(line, "deleted file mode "):
+			fp.Deleted, fp.OldMode = true, strings.TrimPrefix(line, "deleted file mode ")
+		case strings.HasPrefix(line, "old mode "):
+			fp.OldMode = strings.TrimPrefix(line, "old mode ")
+		case strings.HasPrefix(line, "new mode "):
+			fp.NewMode = strings.TrimPrefix(line, "new mode ")
+		case strings.HasPrefix(line, "index "):
+			hashes, mode, _ := strings.Cut(strings.TrimPrefix(line, "index "), " ")
+			fp.OldHash, fp.NewHash, _ = strings.Cut(hashes, "..")
+			if mode != "" {
+				fp.OldMode, fp.NewMode = mode, mode
+			}
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+		case strings.HasPrefix(line, "@@ "):
+			hunk, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			for old, new := hunk.OldCount, hunk.NewCount; old > 0 || new > 0; {
+				text, ok := next()
+				if !ok {
+					return nil, fmt.Errorf("line %d: hunk of %s ends early", n, fp.Path)
+				}
+				if text == "" {
+					// Some editors strip the space of empty context lines
+					text = " "
+				}
+				switch text[0] {
+				case ' ':
+					old--
+					new--
+				case '-':
+					old--
+				case '+':
+					new--
+				case '\\':
+					markNoNewline(hunk.Lines)
+					continue
+				default:
+					return nil, fmt.Errorf("line %d: unexpected %q in a hunk of %s", n, text, fp.Path)
+				}
+				hunk.Lines = append(hunk.Lines, diffLine{text[0], text[1:]})
+			}
+			fp.Hunks = append(fp.Hunks, hunk)
+		case strings.HasPrefix(line, `\`):
+			// No newline at end of file, after the last line of a hunk
+			if len(fp.Hunks) > 0 {
+				markNoNewline(fp.Hunks[len(fp.Hunks)-1].Lines)
+			}
+		case strings.HasPrefix(line, "rename "), strings.HasPrefix(line, "copy "), strings.HasPrefix(line, "similarity index "):
+			return nil, fmt.Errorf("line %d: %s is renamed or copied, which cc-init apply does not support", n, fp.Path)
+		case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+			return nil, fmt.Errorf("line %d: %s is binary, which cc-init apply does not support", n, fp.Path)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(patches) == 0 {
+		return nil, fmt.Errorf("no changes found; expected a patch written by --emit-patch or git diff")
+	}
+	return patches, nil
+}
+
+// patchHeaderPath returns the path of a "diff --git a/<path> b/<path>" line,
+// which must stay inside the target
+func patchHeaderPath(header string) (string, error) {
+	half := len(header) / 2
+	if len(header)%2 == 0 || header[half] != ' ' || !strings.HasPrefix(header, "a/") || header[half+1:half+3] != "b/" || header[2:half] != header[half+3:] {
+		return "", fmt.Errorf("unsupported diff header %q", "diff --git "+header)
+	}
+	name := header[2:half]
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("%s is outside the target", name)
+	}
+	return name, nil
+}
+
+// parseHunkHeader parses "@@ -a,b +c,d @@"; an omitted count is 1
+func parseHunkHeader(line string) (diffHunk, error) {
+	var hunk diffHunk
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[3] != "@@" || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return hunk, fmt.Errorf("invalid hunk header %q", line)
+	}
+	var err error
+	if hunk.OldStart, hunk.OldCount, err = parseHunkRange(fields[1][1:]); err == nil {
+		hunk.NewStart, hunk.NewCount, err = parseHunkRange(fields[2][1:])
+	}
+	if err != nil {
+		return hunk, fmt.Errorf("invalid hunk header %q", line)
+	}
+	return hunk, nil
+}
+
+// parseHunkRange parses the "start,count" of a hunk header
+func parseHunkRange(s string) (start, count int, err error) {
+	from, length, found := strings.Cut(s, ",")
+	if start, err = strconv.Atoi(from); err != nil {
+		return 0, 0, err
+	}
+	count = 1
+	if found {
+		count, err = strconv.Atoi(length)
+	}
+	return start, count, err
+}
+
+// markNoNewline marks the last line of a hunk as missing its newline
+func markNoNewline(lines []diffLine) {
+	if len(lines) > 0 {
+		lines[len(lines)-1].Text += noNewline
+	}
+}
+
+// applyHunks applies the hunks of a patch to the lines of a file, checking
+// every context and removed line
+func applyHunks(old []string, hunks []diffHunk) ([]string, error) {
+	var out []string
+	pos := 0
+	for _, hunk := range hunks {
+		start := hunk.OldStart - 1
+		if hunk.OldCount == 0 {
+			start = hunk.OldStart
+		}
+		if start < pos || start > len(old) {
+			return nil, fmt.Errorf("%s does not apply", hunk.header())
+		}
+		out = append(out, old[pos:start]...)
+		i := start
+		for _, line := range hunk.Lines {
+			if line.Kind == '+' {
+				out = append(out, line.Text)
+				continue
+			}
+			if i >= len(old) || old[i] != line.Text {
+				return nil, fmt.Errorf("%s does not apply", hunk.header())
+			}
+			if line.Kind == ' ' {
+				out = append(out, line.Text)
+			}
+			i++
+		}
+		pos = i
+	}
+	return append(out, old[pos:]...), nil
+}
+
+// joinPatchLines is the reverse of patchLines
+func joinPatchLines(lines []string) []byte {
+	var b bytes.Buffer
+	for _, line := range lines {
+		if text, missing := strings.CutSuffix(line, noNewline); missing {
+			b.WriteString(text)
+			continue
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.Bytes()
+}
+
+// sameObject reports whether content is the git object named by hash, which
+// may be abbreviated to no fewer than 7 digits
+func sameObject(content []byte, hash string) bool {
+	return len(hash) >= 7 && strings.HasPrefix(gitBlobHash(content), hash)
+}
+
+// patchResult is a file of a patch checked against the target: the content
+// it has now and the content the patch gives it
+type patchResult struct {
+	patch   *FilePatch
+	target  string
+	existed bool
+	old     []byte
+	new     []byte
+}
+
+// checkPatch works out the result of fp on the target. It fails when the
+// file is not the one the patch was made from, so applying never merges.
+func (e *Engine) checkPatch(fp *FilePatch) (*patchResult, error) {
+	result := &patchResult{patch: fp, target: filepath.Join(e.config.TargetDir, filepath.FromSlash(fp.Path))}
+	if info, err := e.fs.Stat(result.target); err == nil {
+		if info.IsDir() {
+			return nil, fmt.Errorf("path exists but is a directory")
+		}
+		if result.old, err = e.fs.ReadFile(result.target); err != nil {
+			return nil, err
+		}
+		result.existed = true
+	}
+	switch {
+	case fp.New && result.existed:
+		return nil, fmt.Errorf("already exists")
+	case !fp.New && !result.existed:
+		return nil, fmt.Errorf("no longer exists")
+	case !fp.New && fp.OldHash != "" && !sameObject(result.old, fp.OldHash):
+		return nil, fmt.Errorf("changed since the patch was made")
+	}
+	lines, err := applyHunks(patchLines(result.old), fp.Hunks)
+	if err != nil {
+		return nil, err
+	}
+	result.new = joinPatchLines(lines)
+	if fp.Deleted && len(result.new) > 0 {
+		return nil, fmt.Errorf("changed since the patch was made")
+	}
+	if !fp.Deleted && fp.NewHash != "" && strings.Trim(fp.NewHash, "0") != "" && !sameObject(result.new, fp.NewHash) {
+		return nil, fmt.Errorf("does not end up as the patch recorded")
+	}
+	return result, nil
+}
+
+// applyResult writes a checked file, backing up what it replaces or removes
+// and recording it in the journal
+func (e *Engine) applyResult(r *patchResult) error {
+	fp := r.patch
+	if fp.Deleted {
+		if !e.config.DryRun {
+			if _, err := e.backupFile(JournalRemoved, fp.Path, r.old); err != nil {
+				return fmt.Errorf("failed to back up %s: %w", fp.Path, err)
+			}
+		}
+		if err := e.fs.Remove(r.target); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", fp.Path, err)
+		}
+		if !e.config.DryRun {
+			e.logger.FileRemoved(fp.Path)
+		}
+		return nil
+	}
+
+	mode := os.FileMode(0644)
+	if fp.NewMode == "100755" {
+		mode = 0755
+	}
+	if r.existed && !bytes.Equal(r.old, r.new) {
+		if !e.config.DryRun {
+			if _, err := e.backupFile(JournalReplaced, fp.Path, r.old); err != nil {
+				return fmt.Errorf("failed to back up %s: %w", fp.Path, err)
+			}
+		}
+		if err := e.fs.WriteFile(r.target, r.new, mode); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", fp.Path, err)
+		}
+	} else if !r.existed {
+		if err := e.fs.WriteFile(r.target, r.new, mode); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", fp.Path, err)
+		}
+		e.journal = append(e.journal, JournalEntry{Action: JournalCreated, Path: fp.Path, Digest: contentDigest(r.new)})
+	}
+	if fp.NewMode != "" && fp.NewMode != fp.OldMode {
+		if err := e.fs.Chmod(r.target, mode); err != nil {
+			return fmt.Errorf("failed to set mode of %s: %w", fp.Path, err)
+		}
+	}
+	if err := e.fixTimes(r.target); err != nil {
+		return err
+	}
+	if r.existed {
+		e.logger.FileUpdated(fp.Path)
+	} else {
+		e.logger.FileCreated(fp.Path)
+	}
+	return nil
+}
+
+// runApply implements `cc-init apply --patch <file>`: it checks that every
+// file of a patch written by --emit-patch is still as it was when the patch
+// was made, then applies the whole patch, or nothing
+func runApply(args []string) error {
+	config := &Config{}
+	fs := newCommandFlagSet(findCommand("apply"), config)
+	patchFile := fs.String("patch", "", tr("Patch written by --emit-patch or git diff"))
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if *patchFile == "" || len(positional) > 0 {
+		fs.Usage()
+		return fmt.Errorf("expected: cc-init apply --patch <file>")
+	}
+	data, err := os.ReadFile(*patchFile)
+	if err != nil {
+		return fmt.Errorf("failed to read patch: %w", err)
+	}
+	patches, err := parsePatch(data)
+	if err != nil {
+		return fmt.Errorf("invalid patch %s: %w", *patchFile, err)
+	}
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	engine, err := NewEngine(templateFiles(), config)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	results := make([]*patchResult, 0, len(patches))
+	stale := 0
+	for _, fp := range patches {
+		result, err := engine.checkPatch(fp)
+		if err != nil {
+			engine.logger.Error("%s: %v", fp.Path, err)
+			stale++
+			continue
+		}
+		results = append(results, result)
+	}
+	if stale > 0 {
+		return fmt.Errorf("%s no longer applies to %d %s; nothing was changed", *patchFile, stale, pluralize("file", stale))
+	}
+
+	for _, result := range results {
+		if err := engine.applyResult(result); err != nil {
+			return err
+		}
+	}
+	if err := engine.writeJournal(); err != nil {
+		return err
+	}
+	if !config.DryRun {
+		backedUp := 0
+		for _, entry := range engine.journal {
+			if entry.Backup != "" {
+				backedUp++
+			}
+		}
+		if backedUp > 0 {
+			engine.logger.Info("Backed up the replaced and removed files to %s", engine.backupRunDir())
+		}
+		engine.logger.Success("Applied %s to %d %s", *patchFile, len(results), pluralize("file", len(results)))
+	}
+	return nil
+}
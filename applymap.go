@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// targetMapFile is the committed map read by `cc-init apply-map`, relative
+// to the repository root
+const targetMapFile = ".cc-init/targets.yaml"
+
+// TargetMap lists the subdirectories of a monorepo and how each is
+// configured
+type TargetMap struct {
+	// Flags are cc-init flags applied to every target
+	Flags   []string       `yaml:"flags"`
+	Targets []MappedTarget `yaml:"targets"`
+}
+
+// MappedTarget is one subdirectory of a TargetMap
+type MappedTarget struct {
+	// Path is relative to the repository root; "." is the root itself
+	Path string `yaml:"path"`
+	// Set is the embedded template set, as for --set
+	Set string `yaml:"set"`
+	// TemplateDir is a template pack, relative to the repository root
+	TemplateDir string `yaml:"template_dir"`
+	// Vars answer the variables of the pack, as for --var
+	Vars map[string]string `yaml:"vars"`
+	// Flags are added to the map flags for this target
+	Flags []string `yaml:"flags"`
+}
+
+// loadTargetMap reads and checks a target map
+func loadTargetMap(path string) (*TargetMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m TargetMap
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if len(m.Targets) == 0 {
+		return nil, fmt.Errorf("%s lists no targets", path)
+	}
+	seen := map[string]bool{}
+	for i, target := range m.Targets {
+		if target.Path == "" {
+			return nil, fmt.Errorf("%s: target %d has no path", path, i+1)
+		}
+		p := filepath.Clean(filepath.FromSlash(target.Path))
+		if !filepath.IsLocal(p) && p != "." {
+			return nil, fmt.Errorf("%s: target path %q is outside the repository", path, target.Path)
+		}
+		if seen[p] {
+			return nil, fmt.Errorf("%s: target %s is listed twice", path, target.Path)
+		}
+		seen[p] = true
+		if target.Set != "" && target.TemplateDir != "" {
+			return nil, fmt.Errorf("%s: target %s sets both set and template_dir", path, target.Path)
+		}
+		if err := validateTemplateSet(target.Set); err != nil {
+			return nil, fmt.Errorf("%s: target %s: %w", path, target.Path, err)
+		}
+	}
+	return &m, nil
+}
+
+// args returns the cc-init arguments of one target below root
+func (t MappedTarget) args(root string) []string {
+	args := []string{"--target", filepath.Join(root, filepath.FromSlash(t.Path))}
+	if t.Set != "" {
+		args = append(args, "--set", t.Set)
+	}
+	if t.TemplateDir != "" {
+		dir := t.TemplateDir
+		if !isTemplateURL(dir) && !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, filepath.FromSlash(dir))
+		}
+		args = append(args, "--template-dir", dir)
+	}
+	names := make([]string, 0, len(t.Vars))
+	for name := range t.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "--var", name+"="+t.Vars[name])
+	}
+	return args
+}
+
+// runApplyMap implements `cc-init apply-map`
+func runApplyMap(args []string) error {
+	cmd := findCommand("apply-map")
+	config := &Config{}
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.StringVar(&config.TargetDir, "target", "", tr("Repository root (default: the git repository root, else the current directory)"))
+	fs.StringVar(&config.TargetDir, "t", "", tr("Repository root (shorthand)"))
+	mapFile := fs.String("map", "", tr("Target map (default: .cc-init/targets.yaml in the repository root)"))
+	noColor := fs.Bool("no-color", false, tr("Disable colored output"))
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags after -- are passed to every run.\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	// Flags after -- are cc-init flags for every target
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := applyConfigDefaults(fs); err != nil {
+		return err
+	}
+	extra := fs.Args()
+
+	root := config.TargetDir
+	if root == "" {
+		dir, err := defaultTargetDir(config)
+		if err != nil {
+			return err
+		}
+		root = dir
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("invalid target directory: %w", err)
+	}
+	path := *mapFile
+	if path == "" {
+		path = filepath.Join(root, filepath.FromSlash(targetMapFile))
+	}
+	m, err := loadTargetMap(path)
+	if err != nil {
+		return err
+	}
+
+	// Run this binary so every cc-init flag works exactly as on the command line
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	logger := NewLogger(false, *noColor)
+	failed := 0
+	for i, target := range m.Targets {
+		logger.Info("[%d/%d] %s", i+1, len(m.Targets), target.Path)
+		run := append(append(append(target.args(root), m.Flags...), target.Flags...), extra...)
+		if *noColor {
+			run = append(run, "--no-color")
+		}
+		c := exec.Command(self, run...)
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := c.Run(); err != nil {
+			logger.Error("%s: %v", target.Path, err)
+			failed++
+		}
+		logger.Blank()
+	}
+
+	logger.Info("Applied the map to %d %s: %d failed", len(m.Targets), pluralize("target", len(m.Targets)), failed)
+	if failed > 0 {
+		return fmt.Errorf("apply-map failed for %d %s", failed, pluralize("target", failed))
+	}
+	return nil
+}
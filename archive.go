@@ -0,0 +1,284 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// archiveEntry is a file or directory captured by ArchiveFileSystem
+type archiveEntry struct {
+	content []byte
+	mode    os.FileMode
+	dir     bool
+	uid     int
+	gid     int
+	mtime   time.Time
+}
+
+// ArchiveFileSystem reads through to another FileSystem but captures writes
+// in memory and, on Close, stores them in a .tar, .tar.gz/.tgz or .zip
+// archive with paths relative to root. Merges with existing files therefore
+// behave exactly as they would on disk.
+type ArchiveFileSystem struct {
+	wrapped FileSystem
+	root    string
+	output  string
+	logger  *Logger
+	mtime   time.Time
+	entries map[string]*archiveEntry
+}
+
+// validateArchivePath checks that the --output-archive name has a supported
+// extension
+func validateArchivePath(name string) error {
+	for _, ext := range []string{".tar", ".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(name, ext) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported archive %q (expected .tar, .tar.gz, .tgz or .zip)", name)
+}
+
+// NewArchiveFileSystem creates a new ArchiveFileSystem; entries without an
+// explicit modification time get mtime, or the current time if it is zero
+func NewArchiveFileSystem(wrapped FileSystem, root, output string, mtime time.Time, logger *Logger) *ArchiveFileSystem {
+	if mtime.IsZero() {
+		mtime = time.Now()
+	}
+	return &ArchiveFileSystem{
+		wrapped: wrapped,
+		root:    root,
+		output:  output,
+		logger:  logger,
+		mtime:   mtime,
+		entries: map[string]*archiveEntry{},
+	}
+}
+
+// Exists reports captured paths as well as existing ones
+func (fs *ArchiveFileSystem) Exists(p string) bool {
+	return fs.entries[p] != nil || fs.wrapped.Exists(p)
+}
+
+// CreateDir captures a directory
+func (fs *ArchiveFileSystem) CreateDir(p string, perm os.FileMode) error {
+	if info, err := fs.Stat(p); err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("path exists but is not a directory: %s", p)
+		}
+		return nil
+	}
+	fs.entries[p] = &archiveEntry{mode: perm, dir: true, uid: -1, gid: -1}
+	return nil
+}
+
+// CreateFile captures a file unless it already exists
+func (fs *ArchiveFileSystem) CreateFile(p string, content []byte, perm os.FileMode) error {
+	if info, err := fs.Stat(p); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("path exists but is a directory: %s", p)
+		}
+		return nil
+	}
+	return fs.WriteFile(p, content, perm)
+}
+
+// WriteFile captures a file, replacing any captured content
+func (fs *ArchiveFileSystem) WriteFile(p string, content []byte, perm os.FileMode) error {
+	fs.entries[p] = &archiveEntry{content: append([]byte(nil), content...), mode: perm, uid: -1, gid: -1}
+	return nil
+}
+
+// ReadFile returns captured content, falling back to the wrapped filesystem
+func (fs *ArchiveFileSystem) ReadFile(p string) ([]byte, error) {
+	if entry := fs.entries[p]; entry != nil && !entry.dir {
+		return entry.content, nil
+	}
+	return fs.wrapped.ReadFile(p)
+}
+
+// Walk delegates to the wrapped filesystem
+func (fs *ArchiveFileSystem) Walk(root string, fn WalkFunc) error {
+	return fs.wrapped.Walk(root, fn)
+}
+
+// Stat returns info for captured paths, falling back to the wrapped filesystem
+func (fs *ArchiveFileSystem) Stat(p string) (fs.FileInfo, error) {
+	if entry := fs.entries[p]; entry != nil {
+		return &archiveFileInfo{name: filepath.Base(p), entry: entry}, nil
+	}
+	return fs.wrapped.Stat(p)
+}
+
+// Chmod sets the mode of a captured path
+func (fs *ArchiveFileSystem) Chmod(p string, mode os.FileMode) error {
+	if entry := fs.entries[p]; entry != nil {
+		entry.mode = mode
+	}
+	return nil
+}
+
+// Chown sets the owner of a captured path
+func (fs *ArchiveFileSystem) Chown(p string, uid, gid int) error {
+	if entry := fs.entries[p]; entry != nil {
+		entry.uid, entry.gid = uid, gid
+	}
+	return nil
+}
+
+// Chtimes sets the modification time of a captured path
+func (fs *ArchiveFileSystem) Chtimes(p string, mtime time.Time) error {
+	if entry := fs.entries[p]; entry != nil {
+		entry.mtime = mtime
+	}
+	return nil
+}
+
+// Remove leaves a path out of the archive; files outside it are untouched
+func (fs *ArchiveFileSystem) Remove(p string) error {
+	delete(fs.entries, p)
+	return nil
+}
+
+// Close writes the captured paths to the archive
+func (fs *ArchiveFileSystem) Close() error {
+	names, entries := fs.archiveEntries()
+
+	file, err := os.Create(fs.output)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	if strings.HasSuffix(fs.output, ".zip") {
+		err = writeZip(file, names, entries)
+	} else {
+		err = writeTar(file, fs.output, names, entries)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write archive %s: %w", fs.output, err)
+	}
+	fs.logger.Success("Wrote %d %s to %s", len(names), pluralize("entry", len(names)), fs.output)
+	return nil
+}
+
+// archiveEntries returns the captured paths relative to root in archive
+// order, adding the parent directories they were written into
+func (fs *ArchiveFileSystem) archiveEntries() ([]string, map[string]*archiveEntry) {
+	entries := map[string]*archiveEntry{}
+	for p, entry := range fs.entries {
+		rel, err := filepath.Rel(fs.root, p)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if entry.mtime.IsZero() {
+			entry.mtime = fs.mtime
+		}
+		entries[rel] = entry
+		for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+			if entries[dir] == nil {
+				entries[dir] = &archiveEntry{mode: 0755, dir: true, uid: -1, gid: -1, mtime: fs.mtime}
+			}
+		}
+	}
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, entries
+}
+
+// writeTar writes entries as a tar archive, gzip-compressed for .tar.gz/.tgz
+func writeTar(w io.Writer, output string, names []string, entries map[string]*archiveEntry) error {
+	if !strings.HasSuffix(output, ".tar") {
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		w = gz
+	}
+	tw := tar.NewWriter(w)
+	for _, name := range names {
+		entry := entries[name]
+		header := &tar.Header{
+			Name:    name,
+			Mode:    int64(entry.mode.Perm()),
+			ModTime: entry.mtime,
+			Size:    int64(len(entry.content)),
+		}
+		if entry.uid >= 0 {
+			header.Uid = entry.uid
+		}
+		if entry.gid >= 0 {
+			header.Gid = entry.gid
+		}
+		if entry.dir {
+			header.Typeflag = tar.TypeDir
+			header.Name += "/"
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !entry.dir {
+			if _, err := tw.Write(entry.content); err != nil {
+				return err
+			}
+		}
+	}
+	return tw.Close()
+}
+
+// writeZip writes entries as a zip archive
+func writeZip(w io.Writer, names []string, entries map[string]*archiveEntry) error {
+	zw := zip.NewWriter(w)
+	for _, name := range names {
+		entry := entries[name]
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: entry.mtime}
+		header.SetMode(entry.mode.Perm())
+		if entry.dir {
+			header.Name += "/"
+			header.Method = zip.Store
+			header.SetMode(fs.ModeDir | entry.mode.Perm())
+		}
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(entry.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// archiveFileInfo is the fs.FileInfo of a captured path
+type archiveFileInfo struct {
+	name  string
+	entry *archiveEntry
+}
+
+func (i *archiveFileInfo) Name() string       { return i.name }
+func (i *archiveFileInfo) Size() int64        { return int64(len(i.entry.content)) }
+func (i *archiveFileInfo) ModTime() time.Time { return i.entry.mtime }
+func (i *archiveFileInfo) IsDir() bool        { return i.entry.dir }
+func (i *archiveFileInfo) Sys() interface{}   { return nil }
+
+// Mode returns the captured permissions, with the directory bit for directories
+func (i *archiveFileInfo) Mode() fs.FileMode {
+	if i.entry.dir {
+		return fs.ModeDir | i.entry.mode
+	}
+	return i.entry.mode
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// auditLogEnv names the environment variable that sets the audit log when
+// --audit-log is not given, so fleets can enable it centrally
+const auditLogEnv = "CC_INIT_AUDIT_LOG"
+
+// AuditRecord is one line of the audit log, written per run that changed
+// anything
+type AuditRecord struct {
+	Time      string      `json:"timestamp"`
+	User      string      `json:"user"`
+	Host      string      `json:"host"`
+	Version   string      `json:"version"`
+	Templates string      `json:"templates"`
+	Target    string      `json:"target"`
+	Files     []AuditFile `json:"files"`
+}
+
+// AuditFile is a file a run created or updated, with its resulting content
+// digest
+type AuditFile struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Digest string `json:"digest"`
+}
+
+// currentUser returns the name of the user running cc-init
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// writeAudit appends a record of the files this run created or updated to
+// the audit log, if one is configured
+func (e *Engine) writeAudit() error {
+	if e.config.AuditLog == "" || e.config.DryRun {
+		return nil
+	}
+
+	var files []AuditFile
+	for _, rec := range e.stats.Records {
+		if rec.IsDir || (rec.Action != ActionCreated && rec.Action != ActionUpdated) {
+			continue
+		}
+		p := rec.Path
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(e.config.TargetDir, filepath.FromSlash(p))
+		}
+		data, err := e.fs.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read %s for the audit log: %w", rec.Path, err)
+		}
+		files = append(files, AuditFile{Path: rec.Path, Action: rec.Action, Digest: contentDigest(data)})
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	digest, err := e.tmpl.Digest()
+	if err != nil {
+		return err
+	}
+	host, _ := os.Hostname()
+	target := e.config.TargetDir
+	if e.config.Remote != nil {
+		target = e.config.Remote.String()
+	}
+	line, err := json.Marshal(AuditRecord{
+		Time:      time.Now().UTC().Format(time.RFC3339),
+		User:      currentUser(),
+		Host:      host,
+		Version:   version,
+		Templates: digest,
+		Target:    target,
+		Files:     files,
+	})
+	if err != nil {
+		return err
+	}
+	if err := appendFile(e.config.AuditLog, string(line)+"\n"); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// backupDir holds a directory per run with the files that run removed or
+// replaced, relative to the target
+const backupDir = ".claude/.backup"
+
+// journalFile lists what a run did to the files in its backup directory
+const journalFile = "journal.json"
+
+// Journal actions
+const (
+	JournalCreated  = "created"
+	JournalRemoved  = "removed"
+	JournalReplaced = "replaced"
+)
+
+// Journal is the record of a run kept in its backup directory, enough to put
+// every file back by hand or with a future undo
+type Journal struct {
+	Run     string         `json:"run"`
+	Version string         `json:"version"`
+	Entries []JournalEntry `json:"entries"`
+}
+
+// JournalEntry is one file a run created, removed or replaced. Backup is
+// relative to the run's backup directory and empty for created files; Digest
+// is that of the backed-up content, or of the created file.
+type JournalEntry struct {
+	Action string `json:"action"`
+	Path   string `json:"path"`
+	Backup string `json:"backup,omitempty"`
+	Digest string `json:"digest"`
+}
+
+// backupRunLayout names the backup directory of a run after the UTC time it
+// started, so directories sort by age
+const backupRunLayout = "20060102T150405Z"
+
+// backupRunDir returns the backup directory of this run
+func (e *Engine) backupRunDir() string {
+	if e.backupRun == "" {
+		e.backupRun = time.Now().UTC().Format(backupRunLayout)
+		// Two runs within a second get their own directories
+		base := e.backupRun
+		for n := 2; e.fs.Exists(filepath.Join(e.config.TargetDir, filepath.FromSlash(path.Join(backupDir, e.backupRun)))); n++ {
+			e.backupRun = fmt.Sprintf("%s-%d", base, n)
+		}
+	}
+	return path.Join(backupDir, e.backupRun)
+}
+
+// backupFile copies the content of rel, a path relative to the target, into
+// the backup directory of this run and adds it to the journal
+func (e *Engine) backupFile(action, rel string, data []byte) (string, error) {
+	dir := e.backupRunDir()
+	backup := path.Join(dir, rel)
+	target := filepath.Join(e.config.TargetDir, filepath.FromSlash(backup))
+	if err := e.fs.WriteFile(target, data, 0600); err != nil {
+		return "", err
+	}
+	// Backups may hold anything a user put in .claude; keep them out of git
+	if err := e.ensureIgnored(backupDir); err != nil {
+		return "", err
+	}
+	e.journal = append(e.journal, JournalEntry{Action: action, Path: rel, Backup: rel, Digest: contentDigest(data)})
+	return backup, nil
+}
+
+// trashFile keeps, with --trash, the content of a file about to be
+// overwritten in the backup directory of the run and says where it went.
+// The lockfile and other files cc-init rewrites on every run are not kept.
+func (e *Engine) trashFile(targetPath string, existing []byte) error {
+	if !e.config.Trash || e.config.DryRun {
+		return nil
+	}
+	rel := filepath.ToSlash(e.formatPath(targetPath))
+	if filepath.IsAbs(rel) || lockExcluded(rel) {
+		return nil
+	}
+	backup, err := e.backupFile(JournalReplaced, rel, existing)
+	if err != nil {
+		return fmt.Errorf("failed to move %s to the trash: %w", rel, err)
+	}
+	e.logger.Info("Moved the previous %s to %s", rel, backup)
+	return nil
+}
+
+// writeJournal saves the journal of this run next to its backups
+func (e *Engine) writeJournal() error {
+	if len(e.journal) == 0 || e.config.DryRun {
+		return nil
+	}
+	if err := e.ensureIgnored(backupDir); err != nil {
+		return err
+	}
+	target := filepath.Join(e.config.TargetDir, filepath.FromSlash(path.Join(e.backupRunDir(), journalFile)))
+	data, err := json.MarshalIndent(Journal{Run: e.backupRun, Version: version, Entries: e.journal}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return e.fs.WriteFile(target, append(data, '\n'), 0644)
+}
+
+// Retention applied when .cc-init.yaml sets no backups policy
+const (
+	defaultBackupKeep   = 10
+	defaultBackupMaxAge = "30d"
+)
+
+// BackupPolicy limits the runs kept under .claude/.backup. Keep is the number
+// of most recent runs, MaxAge a duration such as 30d or 12h and MaxSize a
+// total such as 50MB. Unset fields take the defaults; a negative Keep, a
+// MaxAge of 0 and an empty MaxSize impose no limit.
+type BackupPolicy struct {
+	Keep    int    `yaml:"keep" json:"keep,omitempty"`
+	MaxAge  string `yaml:"max_age" json:"max_age,omitempty"`
+	MaxSize string `yaml:"max_size" json:"max_size,omitempty"`
+}
+
+// parseAge parses a duration that may also be given in days, e.g. 30d
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q (expected e.g. 30d or 12h)", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (expected e.g. 30d or 12h)", s)
+	}
+	return d, nil
+}
+
+// sizeUnits are the suffixes accepted by parseSize, largest first
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+
+// parseSize parses a byte count such as 512KB, 50MB or 1GB
+func parseSize(s string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if rest, ok := strings.CutSuffix(upper, u.suffix); ok {
+			upper, unit = strings.TrimSpace(rest), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 50MB)", s)
+	}
+	return n * unit, nil
+}
+
+// formatSize formats a byte count with the largest unit that fits
+func formatSize(n int64) string {
+	for _, u := range sizeUnits[:3] {
+		if n >= u.bytes {
+			return strconv.FormatFloat(float64(n)/float64(u.bytes), 'f', 1, 64) + u.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10) + "B"
+}
+
+// retention resolves the policy into its limits, taking the defaults for
+// unset fields; zero limits are not enforced
+func (p *BackupPolicy) retention() (keep int, maxAge time.Duration, maxSize int64, err error) {
+	policy := BackupPolicy{}
+	if p != nil {
+		policy = *p
+	}
+	keep = policy.Keep
+	if keep == 0 {
+		keep = defaultBackupKeep
+	}
+	if policy.MaxAge == "" {
+		policy.MaxAge = defaultBackupMaxAge
+	}
+	if maxAge, err = parseAge(policy.MaxAge); err != nil {
+		return 0, 0, 0, fmt.Errorf("backups.max_age: %w", err)
+	}
+	if policy.MaxSize != "" {
+		if maxSize, err = parseSize(policy.MaxSize); err != nil {
+			return 0, 0, 0, fmt.Errorf("backups.max_size: %w", err)
+		}
+	}
+	return keep, maxAge, maxSize, nil
+}
+
+// BackupRun is the backup directory of one run
+type BackupRun struct {
+	Name    string
+	Started time.Time
+	Files   int
+	Size    int64
+	// paths are every file and directory below the run, parents first
+	paths []string
+}
+
+// listBackupRuns returns the runs under .claude/.backup, newest first.
+// Directories not named like a run are left alone.
+func (e *Engine) listBackupRuns() ([]BackupRun, error) {
+	root := filepath.Join(e.config.TargetDir, filepath.FromSlash(backupDir))
+	if !e.fs.Exists(root) {
+		return nil, nil
+	}
+	runs := map[string]*BackupRun{}
+	err := e.fs.Walk(root, func(p string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		name, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+		run := runs[name]
+		if run == nil {
+			if !info.IsDir() || len(name) < len(backupRunLayout) {
+				return nil
+			}
+			started, err := time.Parse(backupRunLayout, name[:len(backupRunLayout)])
+			if err != nil {
+				return filepath.SkipDir
+			}
+			run = &BackupRun{Name: name, Started: started}
+			runs[name] = run
+		}
+		run.paths = append(run.paths, p)
+		if !info.IsDir() {
+			run.Files++
+			run.Size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := make([]BackupRun, 0, len(runs))
+	for _, run := range runs {
+		list = append(list, *run)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name > list[j].Name })
+	return list, nil
+}
+
+// pruneBackups removes the backup runs that fall outside the policy and
+// returns them. The run in progress is never removed.
+func (e *Engine) pruneBackups(policy *BackupPolicy) ([]BackupRun, error) {
+	keep, maxAge, maxSize, err := policy.retention()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", projectConfigFile, err)
+	}
+	runs, err := e.listBackupRuns()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var total int64
+	var removed []BackupRun
+	for i, run := range runs {
+		total += run.Size
+		expired := (keep > 0 && i >= keep) ||
+			(maxAge > 0 && now.Sub(run.Started) > maxAge) ||
+			(maxSize > 0 && total > maxSize)
+		if !expired || run.Name == e.backupRun {
+			continue
+		}
+		for j := len(run.paths) - 1; j >= 0; j-- {
+			if err := e.fs.Remove(run.paths[j]); err != nil {
+				return removed, fmt.Errorf("failed to remove backup %s: %w", run.Name, err)
+			}
+		}
+		removed = append(removed, run)
+	}
+	return removed, nil
+}
+
+// rotateBackups applies the backups policy of .cc-init.yaml after a run
+func (e *Engine) rotateBackups() error {
+	if e.config.OutputArchive != "" || e.config.EmitPatch != "" {
+		return nil
+	}
+	project, err := e.loadProjectConfig()
+	if err != nil {
+		return err
+	}
+	removed, err := e.pruneBackups(project.Backups)
+	e.logRemovedBackups(removed)
+	return err
+}
+
+// logRemovedBackups reports the backup runs removed by pruneBackups
+func (e *Engine) logRemovedBackups(removed []BackupRun) {
+	if len(removed) == 0 {
+		return
+	}
+	var size int64
+	for _, run := range removed {
+		size += run.Size
+	}
+	e.logger.Info("Removed %d old backup %s (%s) from %s", len(removed), pluralize("run", len(removed)), formatSize(size), backupDir)
+}
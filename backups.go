@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+)
+
+// runBackups implements `cc-init backups list` and `cc-init backups prune`:
+// the runs kept under .claude/.backup, and removing those outside the
+// retention policy of .cc-init.yaml or of the flags
+func runBackups(args []string) error {
+	config := &Config{}
+	fs := newCommandFlagSet(findCommand("backups"), config)
+	keep := fs.Int("keep", 0, tr("Keep this many most recent runs; negative keeps any number (default: .cc-init.yaml, else 10)"))
+	maxAge := fs.String("max-age", "", tr("Remove runs older than this, e.g. 30d or 12h; 0 keeps any age (default: .cc-init.yaml, else 30d)"))
+	maxSize := fs.String("max-size", "", tr("Remove the oldest runs beyond this total size, e.g. 50MB (default: .cc-init.yaml, else no limit)"))
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || (positional[0] != "list" && positional[0] != "prune") {
+		fs.Usage()
+		return fmt.Errorf("expected: cc-init backups list|prune")
+	}
+	if *maxAge != "" {
+		if _, err := parseAge(*maxAge); err != nil {
+			return fmt.Errorf("--max-age: %w", err)
+		}
+	}
+	if *maxSize != "" {
+		if _, err := parseSize(*maxSize); err != nil {
+			return fmt.Errorf("--max-size: %w", err)
+		}
+	}
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	engine, err := NewEngine(templateFiles(), config)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	if positional[0] == "list" {
+		runs, err := engine.listBackupRuns()
+		if err != nil {
+			return err
+		}
+		if len(runs) == 0 {
+			engine.logger.Info("No backups in %s", backupDir)
+			return nil
+		}
+		for _, run := range runs {
+			fmt.Printf("%s  %s  %d %s  %s\n", run.Name, run.Started.Local().Format("2006-01-02 15:04"), run.Files, pluralize("file", run.Files), formatSize(run.Size))
+		}
+		return nil
+	}
+
+	project, err := engine.loadProjectConfig()
+	if err != nil {
+		return err
+	}
+	policy := BackupPolicy{}
+	if project.Backups != nil {
+		policy = *project.Backups
+	}
+	if *keep != 0 {
+		policy.Keep = *keep
+	}
+	if *maxAge != "" {
+		policy.MaxAge = *maxAge
+	}
+	if *maxSize != "" {
+		policy.MaxSize = *maxSize
+	}
+	removed, err := engine.pruneBackups(&policy)
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		engine.logger.Info("No backups to remove")
+	}
+	engine.logRemovedBackups(removed)
+	return nil
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Entries of a bundle, a tar.gz archive that carries a template pack to
+// machines that cannot reach any template source
+const (
+	bundleManifestPath  = "bundle.json"
+	bundleSignaturePath = "bundle.json.sig"
+	bundlePolicyPath    = "policy.yaml"
+)
+
+// bundleFormat is the version of the bundle layout; install refuses newer ones
+const bundleFormat = 1
+
+// defaultBundleFile is where `cc-init bundle create` writes without -o
+const defaultBundleFile = "templates.ccb"
+
+// BundleManifest describes a bundle: the index of its pack, the digest of
+// the pack archive, which a signature of the manifest thereby covers, the
+// source the pack was bundled from and the policy it carries, if any
+type BundleManifest struct {
+	Format        int       `json:"format"`
+	Created       time.Time `json:"created"`
+	Pack          PackIndex `json:"pack"`
+	ArchiveDigest string    `json:"archive_digest"`
+	Source        string    `json:"source"`
+	Policy        string    `json:"policy,omitempty"`
+}
+
+// loadSigningKey reads an Ed25519 private key in PKCS #8 PEM, as written by
+// `openssl genpkey -algorithm ed25519`
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(expandHome(path))
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key %s: %w", path, err)
+	}
+	signer, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 private key", path)
+	}
+	return signer, nil
+}
+
+// createBundle packs the templates of templateDir or set, and the policy in
+// effect, into a bundle at output, signing its manifest with signKey when
+// given
+func createBundle(output, templateDir, set, signKey string, logger *Logger) error {
+	policy, err := loadPolicy(logger)
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		if err := policy.checkSource(templateDir); err != nil {
+			return err
+		}
+	}
+	source, err := templateSourceURI(templateDir)
+	if err != nil {
+		return err
+	}
+	if isTemplateURL(templateDir) {
+		if templateDir, err = fetchTemplatePack(templateDir); err != nil {
+			return fmt.Errorf("failed to fetch templates: %w", err)
+		}
+	}
+	pack, err := openPack(templateDir, set)
+	if err != nil {
+		return err
+	}
+	index, archive, err := buildPackArchive(pack)
+	if err != nil {
+		return fmt.Errorf("failed to pack templates: %w", err)
+	}
+
+	epoch := time.Unix(0, 0)
+	entries := map[string]*archiveEntry{
+		packArchivePath: {content: archive, mode: 0644, uid: -1, gid: -1, mtime: epoch},
+	}
+	manifest := BundleManifest{Format: bundleFormat, Created: time.Now().UTC(), Pack: *index, ArchiveDigest: contentDigest(archive), Source: source}
+	if policy != nil {
+		// The copy loadPolicy just verified, so offline machines can enforce it
+		dir, err := userCacheDir()
+		if err != nil {
+			return err
+		}
+		for _, name := range []string{policyCacheFile, policyCacheFile + policySignatureSuffix} {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return fmt.Errorf("failed to read the verified policy: %w", err)
+			}
+			entries[bundlePolicyPath+strings.TrimPrefix(name, policyCacheFile)] = &archiveEntry{content: data, mode: 0644, uid: -1, gid: -1, mtime: epoch}
+		}
+		manifest.Policy = os.Getenv(policyURLEnv)
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	manifestJSON = append(manifestJSON, '\n')
+	entries[bundleManifestPath] = &archiveEntry{content: manifestJSON, mode: 0644, uid: -1, gid: -1, mtime: epoch}
+	if signKey != "" {
+		key, err := loadSigningKey(signKey)
+		if err != nil {
+			return err
+		}
+		signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, manifestJSON))
+		entries[bundleSignaturePath] = &archiveEntry{content: []byte(signature + "\n"), mode: 0644, uid: -1, gid: -1, mtime: epoch}
+	}
+
+	// The manifest comes first so install can read it before the templates
+	names := []string{bundleManifestPath}
+	for _, name := range []string{bundleSignaturePath, bundlePolicyPath, bundlePolicyPath + policySignatureSuffix, packArchivePath} {
+		if entries[name] != nil {
+			names = append(names, name)
+		}
+	}
+	var out bytes.Buffer
+	if err := writeTar(&out, output, names, entries); err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	logger.Success("Bundled %d %s (%s) from %s into %s", len(index.Files), pluralize("template", len(index.Files)), index.Digest, source, output)
+	if signKey == "" {
+		logger.Warning("The bundle is not signed; pass --sign-key so installs can verify it")
+	}
+	return nil
+}
+
+// installBundle verifies the bundle at path and stores its pack in the
+// template cache, returning the directory of the pack. key verifies the
+// signature of the manifest; without it an unsigned bundle is accepted with
+// a warning. A carried policy becomes the cached copy that runs fall back
+// to while its URL cannot be reached.
+func installBundle(path string, key ed25519.PublicKey, logger *Logger) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	tmp, err := os.MkdirTemp("", "cc-init-bundle-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	if err := extractTarGz(file, tmp); err != nil {
+		return "", fmt.Errorf("failed to extract %s: %w", path, err)
+	}
+	read := func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(tmp, name))
+	}
+
+	manifestJSON, err := read(bundleManifestPath)
+	if err != nil {
+		return "", fmt.Errorf("%s is not a cc-init bundle: %w", path, err)
+	}
+	signature, err := read(bundleSignaturePath)
+	verified := false
+	switch {
+	case err == nil && key != nil:
+		if !ed25519.Verify(key, manifestJSON, decodeSignature(signature)) {
+			return "", fmt.Errorf("the signature of %s does not verify", path)
+		}
+		verified = true
+	case key != nil:
+		return "", fmt.Errorf("%s is not signed, but a key to verify it was given", path)
+	case err == nil:
+		logger.Warning("%s is signed, but no key was given to verify it; pass --key", path)
+	default:
+		logger.Warning("%s is not signed; its templates are only checked against its own manifest", path)
+	}
+	var manifest BundleManifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return "", fmt.Errorf("invalid %s in %s: %w", bundleManifestPath, path, err)
+	}
+	if manifest.Format > bundleFormat {
+		return "", fmt.Errorf("%s has bundle format %d; this cc-init reads format %d or older, upgrade it", path, manifest.Format, bundleFormat)
+	}
+
+	archive, err := read(packArchivePath)
+	if err != nil {
+		return "", fmt.Errorf("%s holds no templates: %w", path, err)
+	}
+	if digest := contentDigest(archive); digest != manifest.ArchiveDigest {
+		return "", fmt.Errorf("the templates in %s do not match its manifest: got %s, want %s", path, digest, manifest.ArchiveDigest)
+	}
+	cache, err := templateCacheDir()
+	if err != nil {
+		return "", err
+	}
+	// A verified pack keeps the source it was bundled from, which the policy
+	// judges; an unverified one is only as trusted as the bundle file
+	source := manifest.Source
+	if !verified {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+		source = fileURL(abs)
+	}
+	dir, err := storeTemplatePack(cache, source, path, &manifest.Pack, bytes.NewReader(archive))
+	if err != nil {
+		return "", err
+	}
+	logger.Success("Installed %d %s (%s) into %s", len(manifest.Pack.Files), pluralize("template", len(manifest.Pack.Files)), manifest.Pack.Digest, dir)
+
+	if manifest.Policy != "" {
+		policy, err := read(bundlePolicyPath)
+		if err != nil {
+			return "", fmt.Errorf("%s names a policy but does not carry it: %w", path, err)
+		}
+		policySignature, err := read(bundlePolicyPath + policySignatureSuffix)
+		if err != nil {
+			return "", fmt.Errorf("%s carries a policy without its signature: %w", path, err)
+		}
+		// Runs verify the policy with CC_INIT_POLICY_KEY whenever they read it
+		cacheDir, err := userCacheDir()
+		if err != nil {
+			return "", err
+		}
+		if err := cachePolicy(filepath.Join(cacheDir, policyCacheFile), policy, policySignature); err != nil {
+			return "", fmt.Errorf("failed to install the policy: %w", err)
+		}
+		logger.Info("Installed the policy of %s; set %s=%s to enforce it", manifest.Policy, policyURLEnv, manifest.Policy)
+	}
+	return dir, nil
+}
+
+// runBundle implements `cc-init bundle create|install`: a single archive
+// that carries templates to machines without access to any template source
+func runBundle(args []string) error {
+	cmd := findCommand("bundle")
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	var output string
+	fs.StringVar(&output, "output", defaultBundleFile, tr("With create, the bundle to write"))
+	fs.StringVar(&output, "o", defaultBundleFile, tr("With create, the bundle to write (shorthand)"))
+	templateDir := fs.String("template-dir", "", tr("With create, the template pack to bundle: a directory laid out like .claude or the URL of cc-init serve (default: the embedded templates)"))
+	set := fs.String("set", "", tr("With create, the embedded template set to bundle: ")+strings.Join(templateSetNames(), ", "))
+	signKey := fs.String("sign-key", "", tr("With create, an Ed25519 private key in PEM to sign the bundle with"))
+	key := fs.String("key", "", tr("With install, the base64 Ed25519 public key to verify the bundle with (default: $CC_INIT_POLICY_KEY)"))
+	noDefault := fs.Bool("no-default", false, tr("With install, only store the templates instead of making them the default --template-dir"))
+	noColor := fs.Bool("no-color", false, tr("Disable colored output"))
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	logger := NewLogger(false, *noColor)
+	switch {
+	case len(positional) == 1 && positional[0] == "create":
+		return createBundle(output, *templateDir, *set, *signKey, logger)
+	case len(positional) == 2 && positional[0] == "install":
+		var publicKey ed25519.PublicKey
+		if encoded := *key; encoded != "" {
+			if publicKey, err = decodePublicKey(encoded, "--key"); err != nil {
+				return err
+			}
+		} else if encoded := os.Getenv(policyKeyEnv); encoded != "" {
+			if publicKey, err = decodePublicKey(encoded, policyKeyEnv); err != nil {
+				return err
+			}
+		}
+		dir, err := installBundle(positional[1], publicKey, logger)
+		if err != nil {
+			return err
+		}
+		if *noDefault {
+			logger.Info("Use it with: cc-init --template-dir %s", dir)
+			return nil
+		}
+		path, err := setUserDefault("template-dir", dir)
+		if err != nil {
+			return err
+		}
+		logger.Info("Runs now use these templates by default (template-dir in %s)", path)
+		return nil
+	}
+	fs.Usage()
+	return fmt.Errorf("expected: cc-init bundle create or cc-init bundle install <file>")
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// cacheEntryExt is the extension of the record kept next to each cached pack
+const cacheEntryExt = ".json"
+
+// CacheEntry records where a cached pack came from and when it was last
+// used. It is stored next to the pack as <digest>.json, since a file inside
+// the pack would count as a template. Ref is the version of the pack.
+type CacheEntry struct {
+	Source   string    `json:"source"`
+	Ref      string    `json:"ref,omitempty"`
+	Digest   string    `json:"digest"`
+	Fetched  time.Time `json:"fetched"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// touchCacheEntry records that the pack stored as sum was just used. The
+// cache works without the record, so failures are ignored.
+func touchCacheEntry(cache, sum, source string, index *PackIndex) {
+	path := filepath.Join(cache, sum+cacheEntryExt)
+	now := time.Now().UTC()
+	entry := CacheEntry{Fetched: now}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &entry)
+	}
+	entry.Source, entry.Ref, entry.Digest, entry.LastUsed = source, index.PackVersion, index.Digest, now
+	if data, err := json.MarshalIndent(entry, "", "  "); err == nil {
+		os.WriteFile(path, append(data, '\n'), 0644)
+	}
+}
+
+// cachedPackSource returns the source recorded for the pack in dir, or ""
+// when dir is not a pack of the template cache
+func cachedPackSource(dir string) string {
+	cache, err := templateCacheDir()
+	if err != nil || filepath.Dir(dir) != filepath.Clean(cache) {
+		return ""
+	}
+	var entry CacheEntry
+	data, err := os.ReadFile(dir + cacheEntryExt)
+	if err != nil || json.Unmarshal(data, &entry) != nil {
+		return ""
+	}
+	return entry.Source
+}
+
+// CachedPack is a pack in the template cache and its size on disk
+type CachedPack struct {
+	CacheEntry
+	Size int64
+	dir  string
+}
+
+// listCache returns the packs in the template cache, most recently used
+// first. Packs stored before records were kept show when they were stored.
+func listCache(cache string) ([]CachedPack, error) {
+	entries, err := os.ReadDir(cache)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var packs []CachedPack
+	for _, entry := range entries {
+		// Downloads in progress are dot directories
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		pack := CachedPack{dir: filepath.Join(cache, entry.Name())}
+		if data, err := os.ReadFile(pack.dir + cacheEntryExt); err == nil {
+			json.Unmarshal(data, &pack.CacheEntry)
+		}
+		if pack.Digest == "" {
+			pack.Digest = "sha256:" + entry.Name()
+		}
+		if pack.LastUsed.IsZero() {
+			if info, err := entry.Info(); err == nil {
+				pack.LastUsed = info.ModTime()
+			}
+		}
+		err := filepath.WalkDir(pack.dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			info, err := d.Info()
+			if err == nil {
+				pack.Size += info.Size()
+			}
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, pack)
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].LastUsed.After(packs[j].LastUsed) })
+	return packs, nil
+}
+
+// removeCachedPack deletes a pack and its record from the cache
+func removeCachedPack(pack CachedPack) error {
+	if err := os.RemoveAll(pack.dir); err != nil {
+		return err
+	}
+	if err := os.Remove(pack.dir + cacheEntryExt); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// runCache implements `cc-init cache ls|path|clean`: the template packs
+// downloaded from HTTP template sources
+func runCache(args []string) error {
+	cmd := findCommand("cache")
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	olderThan := fs.String("older-than", "", tr("With clean, only remove packs last used longer ago than this, e.g. 30d or 12h"))
+	noColor := fs.Bool("no-color", false, tr("Disable colored output"))
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || (positional[0] != "ls" && positional[0] != "path" && positional[0] != "clean") {
+		fs.Usage()
+		return fmt.Errorf("expected: cc-init cache ls|path|clean")
+	}
+	var maxAge time.Duration
+	if *olderThan != "" {
+		if positional[0] != "clean" {
+			return fmt.Errorf("--older-than only applies to cc-init cache clean")
+		}
+		if maxAge, err = parseAge(*olderThan); err != nil {
+			return fmt.Errorf("--older-than: %w", err)
+		}
+	}
+
+	cache, err := templateCacheDir()
+	if err != nil {
+		return err
+	}
+	if positional[0] == "path" {
+		fmt.Println(cache)
+		return nil
+	}
+	logger := NewLogger(false, *noColor)
+	packs, err := listCache(cache)
+	if err != nil {
+		return err
+	}
+
+	if positional[0] == "ls" {
+		if len(packs) == 0 {
+			logger.Info("The template cache %s is empty", cache)
+			return nil
+		}
+		rows := make([][]string, 0, len(packs))
+		for _, pack := range packs {
+			source, ref := pack.Source, pack.Ref
+			if source == "" {
+				source = "-"
+			}
+			if ref == "" {
+				ref = "-"
+			}
+			_, sum, _ := strings.Cut(pack.Digest, ":")
+			rows = append(rows, []string{sum[:min(12, len(sum))], source, ref, formatSize(pack.Size), pack.LastUsed.Local().Format("2006-01-02 15:04")})
+		}
+		writeTable(os.Stdout, []string{tr("DIGEST"), tr("SOURCE"), tr("REF"), tr("SIZE"), tr("LAST USED")}, rows)
+		return nil
+	}
+
+	now := time.Now()
+	removed := 0
+	var size int64
+	for _, pack := range packs {
+		if maxAge > 0 && now.Sub(pack.LastUsed) <= maxAge {
+			continue
+		}
+		if err := removeCachedPack(pack); err != nil {
+			return fmt.Errorf("failed to remove %s from the cache: %w", pack.Digest, err)
+		}
+		removed++
+		size += pack.Size
+	}
+	if removed == 0 {
+		logger.Info("Nothing to remove from %s", cache)
+		return nil
+	}
+	logger.Success("Removed %d cached %s (%s) from %s", removed, pluralize("pack", removed), formatSize(size), cache)
+	return nil
+}
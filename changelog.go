@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// changesBetween returns the changelog entries of the versions after from up
+// to and including to, newest first; an empty from selects every version up
+// to to
+func (m *PackManifest) changesBetween(from, to string) []ChangelogEntry {
+	var entries []ChangelogEntry
+	for _, entry := range m.Changelog {
+		if (from == "" || compareVersions(entry.Version, from) > 0) && compareVersions(entry.Version, to) <= 0 {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return compareVersions(entries[i].Version, entries[j].Version) > 0
+	})
+	return entries
+}
+
+// writeChangelog prints changelog entries as a version heading followed by
+// one line per change
+func writeChangelog(w io.Writer, entries []ChangelogEntry) {
+	for i, entry := range entries {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if entry.Date != "" {
+			fmt.Fprintf(w, "%s (%s)\n", entry.Version, entry.Date)
+		} else {
+			fmt.Fprintln(w, entry.Version)
+		}
+		for _, change := range entry.Changes {
+			fmt.Fprintf(w, "  - %s\n", strings.TrimSpace(change))
+		}
+	}
+}
+
+// announceChanges logs the changelog entries an update brings, so a run that
+// rewrites files says why
+func (e *Engine) announceChanges() {
+	if e.manifest == nil || e.manifest.Version == "" {
+		return
+	}
+	lock, err := e.sharedLock()
+	if err != nil || lock.PackVersion == "" || compareVersions(lock.PackVersion, e.manifest.Version) >= 0 {
+		return
+	}
+	entries := e.manifest.changesBetween(lock.PackVersion, e.manifest.Version)
+	if len(entries) == 0 {
+		return
+	}
+	e.logger.Info("Updating the templates from %s to %s:", lock.PackVersion, e.manifest.Version)
+	for _, entry := range entries {
+		for _, change := range entry.Changes {
+			e.logger.Info("  %s: %s", entry.Version, strings.TrimSpace(change))
+		}
+	}
+}
+
+// runChangelog implements `cc-init changelog`: it prints the changelog
+// entries between the pack version in the lockfile and the version of the
+// pack about to be installed
+func runChangelog(args []string) error {
+	config := &Config{}
+	fs := newCommandFlagSet(findCommand("changelog"), config)
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %v", positional)
+	}
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	engine, err := NewEngine(templateFiles(), config)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+	manifest := engine.manifest
+	if manifest == nil || manifest.Version == "" {
+		return fmt.Errorf("the templates have no version in %s, so they have no changelog", packManifestFile)
+	}
+
+	installed := ""
+	if lock, err := engine.sharedLock(); err == nil {
+		installed = lock.PackVersion
+	}
+	switch cmp := compareVersions(installed, manifest.Version); {
+	case installed == "":
+		engine.logger.Info("No pack version recorded in %s; showing every change up to %s", lockFile, manifest.Version)
+		writeChangelog(os.Stdout, manifest.changesBetween("", manifest.Version))
+	case cmp == 0:
+		engine.logger.Success("Already at version %s", manifest.Version)
+	case cmp > 0:
+		engine.logger.Warning("Installing %s would downgrade the templates from %s and undo these changes:", manifest.Version, installed)
+		writeChangelog(os.Stdout, manifest.changesBetween(manifest.Version, installed))
+	default:
+		entries := manifest.changesBetween(installed, manifest.Version)
+		if len(entries) == 0 {
+			engine.logger.Info("The changelog has no entries between %s and %s", installed, manifest.Version)
+			return nil
+		}
+		writeChangelog(os.Stdout, entries)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Rules evaluated by `cc-init check`
+const (
+	RuleRequiredFiles   = "required-files"
+	RuleMinVersion      = "min-version"
+	RuleTemplateVersion = "template-version"
+	RuleDrift           = "drift"
+	RulePolicy          = "policy"
+)
+
+// checkRules lists the rules in the order they run
+var checkRules = []string{RuleRequiredFiles, RuleMinVersion, RuleTemplateVersion, RuleDrift, RulePolicy}
+
+// Severities a rule can be configured with in .cc-init.yaml
+const (
+	SeverityError = "error"
+	SeverityWarn  = "warn"
+	SeverityOff   = "off"
+)
+
+// defaultSeverity is the severity of rules .cc-init.yaml does not mention
+var defaultSeverity = map[string]string{
+	RuleRequiredFiles:   SeverityError,
+	RuleMinVersion:      SeverityError,
+	RuleTemplateVersion: SeverityError,
+	RuleDrift:           SeverityWarn,
+	RulePolicy:          SeverityError,
+}
+
+// ErrCheckFailed is returned when `cc-init check` finds error-level problems
+var ErrCheckFailed = errors.New("check failed")
+
+// CheckFinding is one problem found by a check rule
+type CheckFinding struct {
+	Rule    string
+	Message string
+}
+
+// validateSeverities checks the severity map of .cc-init.yaml
+func validateSeverities(severity map[string]string) error {
+	for rule, level := range severity {
+		if rule == RulePolicy {
+			return fmt.Errorf("the %s rule enforces the organization policy and cannot be configured in %s", RulePolicy, projectConfigFile)
+		}
+		if _, ok := defaultSeverity[rule]; !ok {
+			return fmt.Errorf("unknown check rule %q in %s (expected one of: %s)", rule, projectConfigFile, strings.Join(checkRules, ", "))
+		}
+		switch level {
+		case SeverityError, SeverityWarn, SeverityOff:
+		default:
+			return fmt.Errorf("invalid severity %q for %s in %s (expected error, warn or off)", level, rule, projectConfigFile)
+		}
+	}
+	return nil
+}
+
+// compareVersions compares dotted version numbers such as 0.1.0 and v1.2;
+// missing or non-numeric parts count as zero
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// templateVersion returns the version of the templates this run installs:
+// the version in pack.yaml, else the cc-init version
+func (e *Engine) templateVersion() string {
+	if e.manifest != nil && e.manifest.Version != "" {
+		return e.manifest.Version
+	}
+	return version
+}
+
+// installedTemplateVersion returns the version of the templates a lockfile
+// records, in the terms of templateVersion
+func installedTemplateVersion(lock *Lock) string {
+	if lock.PackVersion != "" {
+		return lock.PackVersion
+	}
+	return lock.Version
+}
+
+// enforceTemplatePin stops a run that would install templates other than
+// the version .cc-init.yaml pins, or only warns when the template-version
+// rule is set to warn
+func (e *Engine) enforceTemplatePin() error {
+	project, err := e.loadProjectConfig()
+	if err != nil {
+		return err
+	}
+	if project.TemplateVersion == "" {
+		return nil
+	}
+	if err := validateSeverities(project.Severity); err != nil {
+		return err
+	}
+	current := e.templateVersion()
+	if compareVersions(current, project.TemplateVersion) == 0 {
+		return nil
+	}
+	switch project.severity(RuleTemplateVersion) {
+	case SeverityOff:
+		return nil
+	case SeverityWarn:
+		e.logger.Warning("Installing templates version %s, but %s pins %s", current, projectConfigFile, project.TemplateVersion)
+		return nil
+	}
+	return fmt.Errorf("the templates are version %s but %s pins %s; use the pinned templates or change template_version", current, projectConfigFile, project.TemplateVersion)
+}
+
+// requiredFiles returns the files a target must contain: the list from
+// .cc-init.yaml, or every template file
+func (e *Engine) requiredFiles(project *ProjectConfig) ([]string, error) {
+	if project.Required != nil {
+		return project.Required, nil
+	}
+	var files []string
+	err := e.tmpl.Walk(func(p string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		if rel := templateTarget(p); !e.manifest.packOnly(p) && !lockExcluded(rel) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// runChecks evaluates every rule that is not switched off
+func (e *Engine) runChecks(project *ProjectConfig) (map[string][]CheckFinding, error) {
+	findings := map[string][]CheckFinding{}
+	add := func(rule, format string, args ...interface{}) {
+		findings[rule] = append(findings[rule], CheckFinding{Rule: rule, Message: fmt.Sprintf(tr(format), args...)})
+	}
+
+	required, err := e.requiredFiles(project)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range required {
+		if !e.fs.Exists(filepath.Join(e.config.TargetDir, filepath.FromSlash(rel))) {
+			add(RuleRequiredFiles, "Required file is missing: %s", rel)
+		}
+	}
+
+	lock, err := e.readLock()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if project.MinVersion != "" {
+		switch {
+		case lock == nil:
+			add(RuleMinVersion, "No %s to read the installed version from; cc-init %s or newer is required", lockFile, project.MinVersion)
+		case compareVersions(lock.Version, project.MinVersion) < 0:
+			add(RuleMinVersion, "Templates are from cc-init %s; %s or newer is required", lock.Version, project.MinVersion)
+		}
+	}
+
+	if project.TemplateVersion != "" {
+		switch {
+		case lock == nil:
+			add(RuleTemplateVersion, "No %s to read the installed template version from; %s is pinned", lockFile, project.TemplateVersion)
+		case compareVersions(installedTemplateVersion(lock), project.TemplateVersion) != 0:
+			add(RuleTemplateVersion, "Templates are at version %s; %s pins %s", installedTemplateVersion(lock), projectConfigFile, project.TemplateVersion)
+		}
+	}
+
+	if policy := e.config.Policy; policy != nil {
+		if lock != nil && policy.MinTemplateVersion != "" && compareVersions(installedTemplateVersion(lock), policy.MinTemplateVersion) < 0 {
+			add(RulePolicy, "Templates are at version %s; the policy requires %s or newer", installedTemplateVersion(lock), policy.MinTemplateVersion)
+		}
+		missing, err := e.missingPolicyDenyRules()
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range missing {
+			add(RulePolicy, "settings.json does not deny %s, which the policy requires", rule)
+		}
+	}
+
+	if lock != nil {
+		drift, err := e.checkLock()
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range drift {
+			if d.Kind == DriftOutdated {
+				add(RuleDrift, "%s: written by a different cc-init version", d.Path)
+			} else {
+				add(RuleDrift, "%s: %s", d.Path, tr(d.Kind))
+			}
+		}
+	}
+	return findings, nil
+}
+
+// runCheck implements `cc-init check`
+func runCheck(args []string) error {
+	config := &Config{}
+	fs := newCommandFlagSet(findCommand("check"), config)
+	warnOnly := fs.Bool("warn-only", false, tr("Report problems as warnings and always exit 0"))
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %v", positional)
+	}
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	engine, err := NewEngine(templateFiles(), config)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	project, err := engine.loadProjectConfig()
+	if err != nil {
+		return err
+	}
+	if err := validateSeverities(project.Severity); err != nil {
+		return err
+	}
+	findings, err := engine.runChecks(project)
+	if err != nil {
+		return err
+	}
+
+	errorCount := 0
+	warnCount := 0
+	for _, rule := range checkRules {
+		severity := project.severity(rule)
+		if severity == SeverityOff {
+			continue
+		}
+		if *warnOnly {
+			severity = SeverityWarn
+		}
+		for _, f := range findings[rule] {
+			if severity == SeverityError {
+				engine.logger.Error("[%s] %s", rule, f.Message)
+				errorCount++
+			} else {
+				engine.logger.Warning("[%s] %s", rule, f.Message)
+				warnCount++
+			}
+		}
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("%w: %d %s", ErrCheckFailed, errorCount, pluralize("problem", errorCount))
+	}
+	if warnCount == 0 {
+		engine.logger.Success("All checks passed")
+	}
+	return nil
+}
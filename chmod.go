@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// chmodRule sets the mode of paths matching a pattern (--chmod PATTERN=MODE)
+type chmodRule struct {
+	pattern string
+	mode    fs.FileMode
+}
+
+// parseChmodRules parses --chmod values such as ".claude/hooks/*.sh=0700"
+func parseChmodRules(values []string) ([]chmodRule, error) {
+	var rules []chmodRule
+	for _, value := range values {
+		pattern, modeText, ok := strings.Cut(value, "=")
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("invalid --chmod %q (expected PATTERN=MODE)", value)
+		}
+		mode, err := strconv.ParseUint(modeText, 8, 32)
+		if err != nil || mode > 0777 {
+			return nil, fmt.Errorf("invalid --chmod mode %q (expected octal such as 0640)", modeText)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid --chmod pattern %q: %w", pattern, err)
+		}
+		rules = append(rules, chmodRule{pattern, fs.FileMode(mode)})
+	}
+	return rules, nil
+}
+
+// matchPathPattern reports whether a gitignore-style pattern matches a
+// slash-separated path relative to the target. Patterns without a slash match
+// the base name at any depth.
+func matchPathPattern(pattern, rel string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+	pattern = strings.TrimPrefix(pattern, "**/")
+	ok, _ := path.Match(pattern, rel)
+	return ok
+}
+
+// resolveMode applies the --chmod rules to the default mode of targetPath.
+// exact reports whether the mode must be set as is, bypassing the umask.
+func (e *Engine) resolveMode(targetPath string, mode fs.FileMode) (resolved fs.FileMode, exact bool) {
+	// validateConfig has already checked the rules
+	rules, _ := parseChmodRules(e.config.Chmod)
+	rel := filepath.ToSlash(e.formatPath(targetPath))
+	for _, rule := range rules {
+		if matchPathPattern(rule.pattern, rel) {
+			mode, exact = rule.mode, true
+		}
+	}
+	return mode, exact || !e.config.RespectUmask
+}
+
+// fixMode sets the exact mode of a path cc-init just wrote when --chmod or
+// --respect-umask=false asks for it; otherwise the umask applied on creation
+// stands
+func (e *Engine) fixMode(targetPath string, mode fs.FileMode) error {
+	mode, exact := e.resolveMode(targetPath, mode)
+	if !exact {
+		return nil
+	}
+	if err := e.fs.Chmod(targetPath, mode); err != nil {
+		return fmt.Errorf("failed to set mode of %s: %w", targetPath, err)
+	}
+	return nil
+}
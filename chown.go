@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// lookupOwner resolves a --chown value of the form user, user:group or
+// :group, by name or numeric id, to a uid and gid; -1 leaves that part
+// unchanged
+func lookupOwner(spec string) (uid, gid int, err error) {
+	uid, gid = -1, -1
+	if runtime.GOOS == "windows" {
+		// Ownership is not a uid/gid pair there; the engine warns instead
+		return uid, gid, nil
+	}
+
+	userName, groupName, _ := strings.Cut(spec, ":")
+	if userName == "" && groupName == "" {
+		return uid, gid, fmt.Errorf("invalid --chown %q (expected user, user:group or :group)", spec)
+	}
+	if userName != "" {
+		if uid, err = strconv.Atoi(userName); err != nil {
+			u, lookupErr := user.Lookup(userName)
+			if lookupErr != nil {
+				return -1, -1, fmt.Errorf("invalid --chown user: %w", lookupErr)
+			}
+			uid, _ = strconv.Atoi(u.Uid)
+		}
+	}
+	if groupName != "" {
+		if gid, err = strconv.Atoi(groupName); err != nil {
+			g, lookupErr := user.LookupGroup(groupName)
+			if lookupErr != nil {
+				return -1, -1, fmt.Errorf("invalid --chown group: %w", lookupErr)
+			}
+			gid, _ = strconv.Atoi(g.Gid)
+		}
+	}
+	return uid, gid, nil
+}
+
+// missingParents returns the directories between the target directory and
+// targetPath that do not exist yet, outermost first, so that they can be
+// handed over together with the file written into them
+func (e *Engine) missingParents(targetPath string) []string {
+	if e.config.Chown == "" {
+		return nil
+	}
+	var missing []string
+	for dir := filepath.Dir(targetPath); dir != e.config.TargetDir && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if e.fs.Exists(dir) {
+			break
+		}
+		missing = append([]string{dir}, missing...)
+	}
+	return missing
+}
+
+// fixOwner hands paths cc-init created over to the --chown owner. Failing
+// because of missing privileges or platform support only warns, once.
+func (e *Engine) fixOwner(paths ...string) error {
+	if e.config.Chown == "" || e.chownFailed {
+		return nil
+	}
+	// validateConfig has already resolved the owner once
+	uid, gid, _ := lookupOwner(e.config.Chown)
+	if uid == -1 && gid == -1 {
+		e.chownFailed = true
+		e.logger.Warning("--chown is not supported on %s; keeping the default owner", runtime.GOOS)
+		return nil
+	}
+	for _, path := range paths {
+		if err := e.fs.Chown(path, uid, gid); err != nil {
+			if errors.Is(err, errors.ErrUnsupported) || errors.Is(err, fs.ErrPermission) {
+				e.chownFailed = true
+				e.logger.Warning("Cannot change owner of generated files: %v", err)
+				return nil
+			}
+			return fmt.Errorf("failed to change owner of %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Process exit codes
+const (
+	ExitOK      = 0
+	ExitError   = 1
+	ExitChanges = 2
+)
+
+// ciReportFile is where --ci writes the JSON summary unless --report-file
+// names another file
+const ciReportFile = "cc-init-report.json"
+
+// ErrChangesPending is returned by a --ci dry run that would have changed
+// the target, so pipelines can gate on the exit code
+var ErrChangesPending = errors.New("dry run found changes to make")
+
+// exitCode maps the error of a run to the process exit code
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrChangesPending), errors.Is(err, ErrCheckFailed):
+		return ExitChanges
+	default:
+		return ExitError
+	}
+}
+
+// applyCIDefaults turns --ci into the individual settings it stands for:
+// plain uncolored output and a JSON summary file. cc-init never prompts or
+// checks for updates, so there is nothing else to switch off.
+func applyCIDefaults(config *Config) {
+	if !config.CI {
+		return
+	}
+	config.NoColor = true
+	config.ASCII = true
+	if config.ReportFile == "" {
+		config.ReportFile = ciReportFile
+	}
+}
+
+// changesPending reports whether a run created, updated or removed anything
+func changesPending(stats Statistics) bool {
+	return stats.FilesCreated+stats.FilesUpdated+stats.FilesRemoved+stats.DirsCreated > 0
+}
+
+// ReportFileReporter runs another reporter, then also writes the JSON
+// summary to a file for later pipeline steps
+type ReportFileReporter struct {
+	Reporter
+	path   string
+	dryRun bool
+}
+
+// Report runs the wrapped reporter and writes the JSON summary, except in a
+// dry run, which writes nothing
+func (r *ReportFileReporter) Report(report *Report) error {
+	if err := r.Reporter.Report(report); err != nil || r.dryRun {
+		return err
+	}
+	file, err := os.Create(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	if err := (&JSONReporter{writer: file}).Report(report); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return file.Close()
+}
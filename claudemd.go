@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// claudeMDFile is the project memory file at the target root
+const claudeMDFile = "CLAUDE.md"
+
+// projectSection is the managed CLAUDE.md section written by --claude-md
+const projectSection = "project"
+
+// claudeMDHeader starts a newly created CLAUDE.md
+const claudeMDHeader = `# CLAUDE.md
+
+This file provides guidance to Claude Code (claude.ai/code) when working with code in this repository.
+`
+
+// projectSectionTemplate renders the analyzed project facts
+var projectSectionTemplate = template.Must(template.New("project").Funcs(template.FuncMap{"join": strings.Join}).Parse(`## Project Overview
+
+{{if .PrimaryLanguage}}{{.Name}} is a {{join .Languages ", "}} project{{else}}{{.Name}}{{end}}.
+{{- if or .ModulePath .PackageManager}}
+{{if .ModulePath}}
+- **Module**: ` + "`{{.ModulePath}}`" + `
+{{- end}}
+{{- if .PackageManager}}
+- **Package manager**: {{.PackageManager}}
+{{- end}}
+{{- end}}
+{{- if .BuildCommands}}
+
+## Build
+
+` + "```bash" + `
+{{range .BuildCommands}}{{.}}
+{{end}}` + "```" + `
+{{- end}}
+{{- if .TestCommands}}
+
+## Test
+
+` + "```bash" + `
+{{range .TestCommands}}{{.}}
+{{end}}` + "```" + `
+{{- end}}
+{{- if .LintCommands}}
+
+## Lint
+
+` + "```bash" + `
+{{range .LintCommands}}{{.}}
+{{end}}` + "```" + `
+{{- end}}
+{{- if .Directories}}
+
+## Layout
+{{range .Directories}}
+- ` + "`{{.}}/`" + `
+{{- end}}
+{{- end}}
+
+## Conventions
+
+- Run the test command above before considering a change complete.
+- Match the style of the surrounding code; keep changes focused.
+`))
+
+// sectionMarkers returns the begin/end comments delimiting a managed section
+func sectionMarkers(name string) (string, string) {
+	return fmt.Sprintf("<!-- cc-init:begin %s -->", name), fmt.Sprintf("<!-- cc-init:end %s -->", name)
+}
+
+// upsertManagedSection replaces the named managed section in doc, or appends
+// it when the document has none; content outside the markers is preserved
+func upsertManagedSection(doc, name, body string) string {
+	begin, end := sectionMarkers(name)
+	section := begin + "\n" + strings.TrimRight(body, "\n") + "\n" + end + "\n"
+
+	start := strings.Index(doc, begin)
+	if start >= 0 {
+		if stop := strings.Index(doc[start:], end); stop >= 0 {
+			stop += start + len(end)
+			if stop < len(doc) && doc[stop] == '\n' {
+				stop++
+			}
+			return doc[:start] + section + doc[stop:]
+		}
+	}
+
+	if doc != "" && !strings.HasSuffix(doc, "\n") {
+		doc += "\n"
+	}
+	if doc != "" {
+		doc += "\n"
+	}
+	return doc + section
+}
+
+// ManagedSection is a block of CLAUDE.md content owned by cc-init
+type ManagedSection struct {
+	Name string
+	Body string
+}
+
+// removeManagedSection deletes the named managed section from doc
+func removeManagedSection(doc, name string) string {
+	begin, end := sectionMarkers(name)
+	start := strings.Index(doc, begin)
+	if start < 0 {
+		return doc
+	}
+	stop := strings.Index(doc[start:], end)
+	if stop < 0 {
+		return doc
+	}
+	stop += start + len(end)
+	if stop < len(doc) && doc[stop] == '\n' {
+		stop++
+	}
+	// Drop the blank line that upsertManagedSection inserted before the section
+	if strings.HasSuffix(doc[:start], "\n\n") {
+		start--
+	}
+	return doc[:start] + doc[stop:]
+}
+
+// managedSections returns every managed section in doc, in document order
+func managedSections(doc string) []ManagedSection {
+	var sections []ManagedSection
+	rest := doc
+	for {
+		start := strings.Index(rest, "<!-- cc-init:begin ")
+		if start < 0 {
+			return sections
+		}
+		rest = rest[start+len("<!-- cc-init:begin "):]
+		stop := strings.Index(rest, " -->")
+		if stop < 0 {
+			return sections
+		}
+		name := rest[:stop]
+		if body, ok := managedSection(doc, name); ok {
+			sections = append(sections, ManagedSection{Name: name, Body: body})
+		}
+	}
+}
+
+// managedSection returns the body of the named managed section, if present
+func managedSection(doc, name string) (string, bool) {
+	begin, end := sectionMarkers(name)
+	start := strings.Index(doc, begin)
+	if start < 0 {
+		return "", false
+	}
+	rest := doc[start+len(begin):]
+	stop := strings.Index(rest, end)
+	if stop < 0 {
+		return "", false
+	}
+	return strings.Trim(rest[:stop], "\n"), true
+}
+
+// renderProjectSection renders the CLAUDE.md section for an analyzed project
+func renderProjectSection(info *ProjectInfo) (string, error) {
+	var buf bytes.Buffer
+	if err := projectSectionTemplate.Execute(&buf, info); err != nil {
+		return "", fmt.Errorf("failed to render project section: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// generateClaudeMD writes the project analysis into CLAUDE.md
+func (e *Engine) generateClaudeMD() error {
+	if !e.config.ClaudeMD {
+		return nil
+	}
+
+	info := AnalyzeProject(e.config.TargetDir)
+	e.logger.Debug("Detected languages: %v", info.Languages)
+
+	body, err := renderProjectSection(info)
+	if err != nil {
+		return err
+	}
+	e.queueSections(ManagedSection{Name: projectSection, Body: body})
+	return nil
+}
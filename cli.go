@@ -3,20 +3,193 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 const version = "0.1.0"
 
 // Config holds the CLI configuration
 type Config struct {
-	TargetDir   string
-	DryRun      bool
-	Verbose     bool
-	NoColor     bool
-	ShowHelp    bool
-	ShowVersion bool
+	TargetDir        string
+	NoGitRoot        bool
+	AtGitRoot        bool
+	Submodules       bool
+	TemplateDir      string
+	TemplateSource   string
+	TemplateSet      string
+	Watch            bool
+	Vars             []string
+	AnswersFile      string
+	Answers          *Answers
+	Yes              bool
+	AllowEnv         []string
+	AllowExec        bool
+	Seed             string
+	SecretScan       string
+	Overwrite        bool
+	DryRun           bool
+	Verbose          bool
+	ShowTimings      bool
+	NoColor          bool
+	ASCII            bool
+	Theme            string
+	ShowHelp         bool
+	ShowVersion      bool
+	ReportFormat     string
+	ReportFile       string
+	CI               bool
+	SummaryFormat    string
+	LogLevel         string
+	LogFormat        string
+	LogFile          string
+	AuditLog         string
+	NotifyURL        string
+	Allow            []string
+	Deny             []string
+	MCPServers       []string
+	Hooks            []string
+	NoAutodetect     bool
+	HookPlatform     string
+	Shell            string
+	ClaudeMD         bool
+	Workspaces       bool
+	LocalOverrides   bool
+	PermissionPreset string
+	Preset           string
+	Statusline       string
+	OutputStyles     []string
+	Devcontainer     bool
+	AgentsMD         string
+	ImportMode       string
+	LineEndings      string
+	RespectUmask     bool
+	Chmod            []string
+	Chown            string
+	Mtime            string
+	Retries          int
+	RetryDelay       time.Duration
+	Remote           *RemoteTarget
+	OutputArchive    string
+	EmitPatch        string
+	Migrate          bool
+	Prune            bool
+	Trash            bool
+	Update           bool
+	MergeTool        string
+	Conflict         string
+	ManagedSettings  string
+	Policy           *Policy
+}
+
+// stringListFlag is a repeatable string flag
+type stringListFlag []string
+
+// String returns the flag values joined by commas
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set appends a value each time the flag is given
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// commaListFlag is a repeatable flag whose values may also be comma-separated
+type commaListFlag []string
+
+// String returns the flag values joined by commas
+func (f *commaListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set splits value on commas and appends the non-empty parts
+func (f *commaListFlag) Set(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			*f = append(*f, part)
+		}
+	}
+	return nil
+}
+
+// defineInitFlags defines the flags of an init run on fs
+func defineInitFlags(fs *flag.FlagSet, config *Config) {
+	defineWriteFlags(fs, config)
+	fs.BoolVar(&config.Submodules, "include-submodules", false, tr("Also initialize the checked-out git submodules of the target"))
+	fs.BoolVar(&config.Watch, "watch", false, tr("Apply --template-dir again, overwriting earlier output, whenever the pack changes"))
+	fs.BoolVar(&config.ShowVersion, "version", false, tr("Show version information"))
+	fs.Var((*stringListFlag)(&config.Allow), "allow", tr("Permission rule to allow in settings.json, or @group (repeatable)"))
+	fs.Var((*stringListFlag)(&config.Deny), "deny", tr("Permission rule to deny in settings.json, or @group (repeatable)"))
+	fs.StringVar(&config.Preset, "preset", "", tr("Framework preset to layer on the templates: ")+strings.Join(frameworkPresetNames(), ", "))
+	fs.StringVar(&config.PermissionPreset, "permissions", "", tr("Permission policy preset: ")+strings.Join(permissionPresetNames(), ", "))
+	fs.Var((*commaListFlag)(&config.MCPServers), "mcp", tr("Comma-separated MCP servers to add to .mcp.json"))
+	fs.BoolVar(&config.ClaudeMD, "claude-md", false, tr("Generate a project section in CLAUDE.md from repository analysis"))
+	fs.BoolVar(&config.Workspaces, "workspaces", false, tr("List the packages of a go.work, pnpm, npm or Cargo workspace in CLAUDE.md and give each package its own CLAUDE.md"))
+	fs.StringVar(&config.ImportMode, "import", "", tr("Configurations of other assistants found in the target: ask (default), auto (convert them) or off"))
+	fs.StringVar(&config.AgentsMD, "agents-md", "", tr("Also maintain AGENTS.md: sync (mirror sections) or pointer (CLAUDE.md imports AGENTS.md)"))
+	fs.BoolVar(&config.Devcontainer, "devcontainer", false, tr("Generate .devcontainer/ configured for Claude Code"))
+	fs.BoolVar(&config.Migrate, "migrate", false, tr("Rewrite deprecated keys in .claude/settings.json to their current form"))
+	fs.BoolVar(&config.Prune, "prune", false, tr("Remove managed files the templates no longer produce, after asking; removed files are backed up"))
+	fs.BoolVar(&config.LocalOverrides, "local", false, tr("Create example CLAUDE.local.md and settings.local.json and gitignore them"))
+	fs.Var((*commaListFlag)(&config.Hooks), "hooks", tr("Comma-separated hook presets to install and wire into settings.json"))
+	fs.StringVar(&config.HookPlatform, "hook-platform", HookPlatformAuto, tr("Hook scripts to install: auto (this OS), unix (bash), windows (PowerShell) or both"))
+	fs.BoolVar(&config.NoAutodetect, "no-autodetect", false, tr("Do not add the hook presets of the languages detected in the target"))
+	fs.Var((*commaListFlag)(&config.OutputStyles), "output-styles", tr("Comma-separated output styles to install into .claude/output-styles"))
+	fs.StringVar(&config.Statusline, "statusline", "", tr("Install a status line script: ")+strings.Join(statuslineStyleNames(), ", "))
+}
+
+// defineWriteFlags defines the flags shared by init runs and the subcommands
+// that write into a target directory
+func defineWriteFlags(fs *flag.FlagSet, config *Config) {
+	fs.StringVar(&config.TargetDir, "target", "", tr("Target directory (default: the git repository root, else the current directory)"))
+	fs.StringVar(&config.TargetDir, "t", "", tr("Target directory (shorthand)"))
+	fs.BoolVar(&config.NoGitRoot, "no-git-root", false, tr("Without -t, use the current directory instead of the git repository root"))
+	fs.StringVar(&config.TemplateDir, "template-dir", "", tr("Template pack laid out like .claude, or the URL of cc-init serve, to use instead of the embedded templates"))
+	fs.StringVar(&config.TemplateSet, "set", "", tr("Embedded template set: ")+strings.Join(templateSetNames(), ", ")+tr(" (default: the set recorded in the lockfile, else full)"))
+	fs.Var((*stringListFlag)(&config.Vars), "var", tr("Value of a template pack variable as NAME=VALUE (repeatable)"))
+	fs.StringVar(&config.AnswersFile, "answers", "", tr("YAML file answering the questions of the template pack"))
+	fs.BoolVar(&config.Yes, "yes", false, tr("Never ask; take defaults for questions not answered by --var or --answers"))
+	fs.BoolVar(&config.Yes, "y", false, tr("Never ask (shorthand)"))
+	fs.Var((*commaListFlag)(&config.AllowEnv), "allow-env", tr("Comma-separated environment variables, or patterns such as ACME_*, that templates may read with env"))
+	fs.BoolVar(&config.AllowExec, "allow-exec", false, tr("Allow the template pack to compute variables by running commands"))
+	fs.StringVar(&config.Shell, "shell", "", tr("Shell that templates render for: bash, zsh, fish or pwsh (default: detected from $SHELL)"))
+	fs.StringVar(&config.Seed, "seed", "", tr("Seed for uuid and randomToken in templates, for reproducible output"))
+	fs.StringVar(&config.SecretScan, "secret-scan", SecretScanWarn, tr("What to do when written content looks like a credential: warn, error (refuse the file) or off"))
+	fs.StringVar(&config.ManagedSettings, "managed-settings", ManagedWarn, tr("What to do with settings that the managed settings of this machine override: warn, strip (leave them out of settings.json) or off"))
+	fs.BoolVar(&config.Update, "update", false, tr("Bring existing template files up to date, merging the template changes into files edited since"))
+	fs.StringVar(&config.MergeTool, "merge-tool", os.Getenv(mergeToolEnv), tr("Command that resolves --update conflicts, with $BASE, $LOCAL, $REMOTE and $MERGED as for git (default $CC_INIT_MERGE_TOOL, $VISUAL, $EDITOR)"))
+	fs.StringVar(&config.Conflict, "conflict", "", tr("How --update settles conflicting hunks: ours, theirs, union (both, local first) or markers (default: ask in a terminal, skip otherwise)"))
+	fs.BoolVar(&config.Trash, "trash", false, tr("Move files about to be overwritten into .claude/.backup/<run> instead of replacing them in place"))
+	fs.BoolVar(&config.DryRun, "dry-run", false, tr("Preview operations without making changes"))
+	fs.StringVar(&config.OutputArchive, "output-archive", "", tr("Write the result to a .tar, .tar.gz or .zip archive instead of the target"))
+	fs.StringVar(&config.EmitPatch, "emit-patch", "", tr("Write the changes as a patch for git apply instead of making them"))
+	fs.StringVar(&config.LineEndings, "line-endings", "", tr("Line endings of written files: lf, crlf or auto (.gitattributes, then OS)"))
+	fs.BoolVar(&config.RespectUmask, "respect-umask", true, tr("Apply the umask to file modes; false sets modes exactly"))
+	fs.Var((*stringListFlag)(&config.Chmod), "chmod", tr("Mode for matching paths as PATTERN=MODE, e.g. '*.sh=0700' (repeatable)"))
+	fs.StringVar(&config.Chown, "chown", "", tr("Owner of created files as user[:group], e.g. when running as root"))
+	fs.StringVar(&config.Mtime, "mtime", "", tr("Modification time of written files: release, RFC 3339 or Unix seconds (default $SOURCE_DATE_EPOCH)"))
+	fs.IntVar(&config.Retries, "retries", defaultRetries, tr("Retries for file operations that fail with transient errors such as ESTALE or EIO"))
+	fs.DurationVar(&config.RetryDelay, "retry-delay", defaultRetryDelay, tr("Delay before the first retry; doubles after each attempt"))
+	fs.BoolVar(&config.Verbose, "verbose", false, tr("Enable verbose output (same as --log-level debug)"))
+	fs.BoolVar(&config.Verbose, "v", false, tr("Enable verbose output (shorthand)"))
+	fs.BoolVar(&config.ShowTimings, "vv", false, tr("Enable verbose output with timing statistics"))
+	fs.StringVar(&config.LogLevel, "log-level", "", tr("Minimum log level: ")+strings.Join(logLevels, ", ")+tr(" (default info)"))
+	fs.BoolVar(&config.NoColor, "no-color", false, tr("Disable colored output"))
+	fs.BoolVar(&config.ASCII, "ascii", false, tr("Use ASCII symbols instead of Unicode ones"))
+	fs.StringVar(&config.Theme, "theme", "", tr("JSON file overriding the symbols and colors of console output"))
+	fs.StringVar(&config.ReportFormat, "report", ReportConsole, tr("Summary format: ")+strings.Join(reportFormats, ", "))
+	fs.StringVar(&config.ReportFile, "report-file", "", tr("Also write the JSON summary to this file"))
+	fs.BoolVar(&config.CI, "ci", false, tr("Automation defaults: plain output, JSON summary file, exit code 2 when --dry-run finds changes"))
+	fs.StringVar(&config.SummaryFormat, "summary-format", "", tr("Go template for the summary, or @file; overrides --report"))
+	fs.StringVar(&config.LogFormat, "log-format", LogFormatText, tr("Log output format: ")+strings.Join(logFormats, ", "))
+	fs.StringVar(&config.LogFile, "log-file", "", tr("Also append log output to this file"))
+	fs.StringVar(&config.NotifyURL, "notify-url", os.Getenv(notifyURLEnv), tr("POST the JSON report to this URL when done (default $CC_INIT_NOTIFY_URL)"))
+	fs.StringVar(&config.AuditLog, "audit-log", os.Getenv(auditLogEnv), tr("Append a JSON record of every change to this file (default $CC_INIT_AUDIT_LOG)"))
 }
 
 // parseFlags parses command-line flags and returns the configuration
@@ -24,29 +197,57 @@ func parseFlags() *Config {
 	config := &Config{}
 
 	// Define flags
-	flag.StringVar(&config.TargetDir, "target", ".", "Target directory for initialization")
-	flag.StringVar(&config.TargetDir, "t", ".", "Target directory for initialization (shorthand)")
-	flag.BoolVar(&config.DryRun, "dry-run", false, "Preview operations without making changes")
-	flag.BoolVar(&config.Verbose, "verbose", false, "Enable verbose output")
-	flag.BoolVar(&config.Verbose, "v", false, "Enable verbose output (shorthand)")
-	flag.BoolVar(&config.NoColor, "no-color", false, "Disable colored output")
-	flag.BoolVar(&config.ShowVersion, "version", false, "Show version information")
+	defineInitFlags(flag.CommandLine, config)
 
 	// Custom usage function
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "cc-init - Initialize Claude Code configuration\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fmt.Fprint(os.Stderr, tr("cc-init - Initialize Claude Code configuration\n\n"))
+		fmt.Fprintf(os.Stderr, tr("Usage: %s [flags]\n"), os.Args[0])
+		fmt.Fprintf(os.Stderr, tr("       %s <command> [args] [flags]\n"), os.Args[0])
+		printCommands()
+		fmt.Fprintf(os.Stderr, "\n")
+		fmt.Fprint(os.Stderr, tr("Flags:\n"))
 		flag.PrintDefaults()
-		fmt.Fprintf(os.Stderr, "\nExamples:\n")
-		fmt.Fprintf(os.Stderr, "  %s                    # Initialize in current directory\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  %s -t ./myproject     # Initialize in ./myproject\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  %s --dry-run          # Preview what would be created\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  %s -v                 # Show detailed output\n", os.Args[0])
+		fmt.Fprint(os.Stderr, tr("\nExamples:\n"))
+		fmt.Fprintf(os.Stderr, tr("  %s                    # Initialize in current directory\n"), os.Args[0])
+		fmt.Fprintf(os.Stderr, tr("  %s -t ./myproject     # Initialize in ./myproject\n"), os.Args[0])
+		fmt.Fprintf(os.Stderr, tr("  %s --dry-run          # Preview what would be created\n"), os.Args[0])
+		fmt.Fprintf(os.Stderr, tr("  %s -v                 # Show detailed output\n"), os.Args[0])
+		fmt.Fprintf(os.Stderr, tr("  %s --report json      # Print a JSON summary\n"), os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --allow 'Bash(go test:*)' --deny 'Read(.env)'\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --mcp context7,playwright\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --hooks gofmt,protect-env\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --permissions strict\n", os.Args[0])
+		fmt.Fprint(os.Stderr, tr("\nPermission presets:\n"))
+		for _, name := range permissionPresetNames() {
+			fmt.Fprintf(os.Stderr, "  %-18s %s\n", name, permissionPresets[name].Description)
+		}
+		fmt.Fprintf(os.Stderr, tr("Permission rule groups: %s\n"), strings.Join(ruleGroupNames(), ", "))
+		fmt.Fprintf(os.Stderr, tr("MCP servers: %s\n"), strings.Join(mcpServerNames(), ", "))
+		fmt.Fprint(os.Stderr, tr("Statusline styles:\n"))
+		for _, name := range statuslineStyleNames() {
+			fmt.Fprintf(os.Stderr, "  %-18s %s\n", name, statuslineStyles[name].Description)
+		}
+		fmt.Fprint(os.Stderr, tr("Output styles:\n"))
+		for _, name := range outputStyleNames() {
+			fmt.Fprintf(os.Stderr, "  %-18s %s\n", name, outputStyleDescription(name))
+		}
+		fmt.Fprint(os.Stderr, tr("Framework presets:\n"))
+		for _, name := range frameworkPresetNames() {
+			fmt.Fprintf(os.Stderr, "  %-18s %s\n", name, frameworkPresets[name].Description)
+		}
+		fmt.Fprint(os.Stderr, tr("Hook presets:\n"))
+		for _, name := range hookPresetNames() {
+			fmt.Fprintf(os.Stderr, "  %-18s %s\n", name, hookCatalog[name].Description)
+		}
 	}
 
-	// Parse flags
+	// Parse flags, then fill in the defaults of the configuration layers
 	flag.Parse()
+	if err := applyConfigDefaults(flag.CommandLine); err != nil {
+		fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+		os.Exit(1)
+	}
 
 	// Handle help flag
 	if flag.NArg() > 0 && flag.Arg(0) == "help" {
@@ -58,12 +259,288 @@ func parseFlags() *Config {
 
 // validateConfig validates the configuration
 func validateConfig(config *Config) error {
-	// Convert target directory to absolute path
-	absPath, err := filepath.Abs(config.TargetDir)
+	applyCIDefaults(config)
+
+	// Without -t, use the root of the enclosing git work tree
+	if config.TargetDir == "" {
+		dir, err := defaultTargetDir(config)
+		if err != nil {
+			return fmt.Errorf("invalid target directory: %w", err)
+		}
+		config.TargetDir = dir
+	}
+
+	// Remote targets are handled over SFTP, docker exec or the S3 API
+	remote, isRemote, err := parseRemoteTarget(config.TargetDir)
+	if err != nil {
+		return err
+	}
+	if isRemote {
+		config.Remote = remote
+		config.TargetDir = remote.Path
+		if config.ClaudeMD {
+			return fmt.Errorf("--claude-md analyzes a local checkout and does not support remote targets")
+		}
+		if config.Workspaces {
+			return fmt.Errorf("--workspaces analyzes a local checkout and does not support remote targets")
+		}
+	} else {
+		// Convert target directory to absolute path
+		absPath, err := filepath.Abs(config.TargetDir)
+		if err != nil {
+			return fmt.Errorf("invalid target directory: %w", err)
+		}
+		config.TargetDir = absPath
+		if insideGitDir(config.TargetDir) {
+			return fmt.Errorf("target directory is inside a .git directory: %s", config.TargetDir)
+		}
+	}
+	if config.Submodules && config.Remote != nil {
+		return fmt.Errorf("--include-submodules does not support remote targets")
+	}
+
+	// Check the template set
+	if err := validateTemplateSet(config.TemplateSet); err != nil {
+		return err
+	}
+	if config.TemplateSet != "" && config.TemplateDir != "" {
+		return fmt.Errorf("--set selects embedded templates and cannot be combined with --template-dir")
+	}
+
+	// Load the organization policy and refuse the template sources it
+	// does not allow
+	policy, err := loadPolicy(NewLogger(config.Verbose, config.NoColor))
 	if err != nil {
-		return fmt.Errorf("invalid target directory: %w", err)
+		return err
+	}
+	if policy != nil {
+		if err := policy.checkSource(config.TemplateDir); err != nil {
+			return err
+		}
+	}
+	config.Policy = policy
+
+	// Download a pack from an HTTP template source; the rest of cc-init
+	// then uses the cached copy like any local pack
+	if isTemplateURL(config.TemplateDir) {
+		if config.Watch {
+			return fmt.Errorf("--watch needs a local --template-dir")
+		}
+		dir, err := fetchTemplatePack(config.TemplateDir)
+		if err != nil {
+			return fmt.Errorf("failed to fetch templates: %w", err)
+		}
+		config.TemplateSource = config.TemplateDir
+		config.TemplateDir = dir
+	}
+
+	// Check the template pack
+	if config.TemplateDir != "" {
+		absPath, err := filepath.Abs(config.TemplateDir)
+		if err != nil {
+			return fmt.Errorf("invalid template directory: %w", err)
+		}
+		if info, err := os.Stat(absPath); err != nil || !info.IsDir() {
+			return fmt.Errorf("template directory does not exist: %s", config.TemplateDir)
+		}
+		config.TemplateDir = absPath
+		if config.TemplateSource == "" {
+			config.TemplateSource = "file://" + filepath.ToSlash(absPath)
+		}
+	}
+	if config.Watch && config.TemplateDir == "" {
+		return fmt.Errorf("--watch needs --template-dir; the embedded templates never change")
+	}
+
+	// Check the report format
+	if err := validateReportFormat(config.ReportFormat); err != nil {
+		return err
+	}
+
+	// Check the summary template
+	if config.SummaryFormat != "" {
+		if _, err := parseSummaryTemplate(config.SummaryFormat); err != nil {
+			return err
+		}
+	}
+
+	// Resolve the log level; --verbose is shorthand for debug and -vv adds
+	// timing statistics
+	if config.ShowTimings {
+		config.Verbose = true
+	}
+	if config.LogLevel == "" {
+		config.LogLevel = LogLevelInfo
+		if config.Verbose {
+			config.LogLevel = LogLevelDebug
+		}
+	}
+	if err := validateLogLevel(config.LogLevel); err != nil {
+		return err
+	}
+	config.Verbose = config.LogLevel == LogLevelDebug
+
+	// Check the log format
+	if err := validateLogFormat(config.LogFormat); err != nil {
+		return err
+	}
+
+	// Check permission preset and rule syntax
+	if err := validatePermissionPreset(config.PermissionPreset); err != nil {
+		return err
+	}
+	if _, err := expandPermissionRules(config.Allow); err != nil {
+		return err
+	}
+	if _, err := expandPermissionRules(config.Deny); err != nil {
+		return err
+	}
+
+	// Check the framework preset
+	if err := validateFrameworkPreset(config.Preset); err != nil {
+		return err
+	}
+
+	// Check MCP server names
+	if err := validateMCPServers(config.MCPServers); err != nil {
+		return err
+	}
+
+	// Check hook preset names
+	if err := validateHookPresets(config.Hooks); err != nil {
+		return err
+	}
+	if err := validateHookPlatform(config.HookPlatform); err != nil {
+		return err
+	}
+
+	// Check the shell of templates
+	if err := validateShell(config.Shell); err != nil {
+		return err
+	}
+
+	// Check chmod rules
+	if _, err := parseChmodRules(config.Chmod); err != nil {
+		return err
+	}
+
+	// Check the owner for --chown
+	if config.Chown != "" {
+		if _, _, err := lookupOwner(config.Chown); err != nil {
+			return err
+		}
+	}
+
+	// Check the archive output
+	if config.OutputArchive != "" {
+		if err := validateArchivePath(config.OutputArchive); err != nil {
+			return err
+		}
+		if config.DryRun {
+			return fmt.Errorf("--output-archive already leaves the target untouched; drop --dry-run")
+		}
+		if config.Prune {
+			return fmt.Errorf("--prune removes files from the target and cannot be combined with --output-archive")
+		}
+		if config.Trash {
+			return fmt.Errorf("--trash keeps files the target would lose and cannot be combined with --output-archive")
+		}
+	}
+
+	// Check the patch output
+	if config.EmitPatch != "" {
+		if config.OutputArchive != "" {
+			return fmt.Errorf("--emit-patch and --output-archive both replace writing to the target; pick one")
+		}
+		if config.DryRun {
+			return fmt.Errorf("--emit-patch already leaves the target untouched; drop --dry-run")
+		}
+		if config.Prune {
+			return fmt.Errorf("--prune backs up removed files in the target and cannot be combined with --emit-patch")
+		}
+		if config.Trash {
+			return fmt.Errorf("--trash keeps files the target would lose and cannot be combined with --emit-patch")
+		}
+	}
+
+	// Check the webhook URL
+	if config.NotifyURL != "" {
+		if u, err := url.Parse(config.NotifyURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid --notify-url %q (expected an http or https URL)", config.NotifyURL)
+		}
+	}
+
+	// Check the retry policy
+	if err := validateRetryPolicy(config.Retries, config.RetryDelay); err != nil {
+		return err
+	}
+
+	// Check the fixed modification time
+	if _, err := resolveMtime(config.Mtime); err != nil {
+		return err
+	}
+
+	// Check the template variables
+	if _, err := parseVarAssignments(config.Vars); err != nil {
+		return err
+	}
+
+	// Read the answers file; its conflict strategy applies without --conflict
+	if config.AnswersFile != "" {
+		answers, err := loadAnswers(config.AnswersFile)
+		if err != nil {
+			return err
+		}
+		config.Answers = answers
+		if config.Conflict == "" {
+			config.Conflict = answers.Conflict
+		}
+	}
+
+	// Check the secret scan policy
+	if err := validateSecretScan(config.SecretScan); err != nil {
+		return err
+	}
+
+	// Check line ending mode
+	if err := validateLineEndings(config.LineEndings); err != nil {
+		return err
+	}
+
+	// Check import mode
+	if err := validateImportMode(config.ImportMode); err != nil {
+		return err
+	}
+
+	// Check the conflict strategy
+	if err := validateConflictStrategy(config.Conflict); err != nil {
+		return err
+	}
+
+	// Check the managed settings mode
+	if err := validateManagedMode(config.ManagedSettings); err != nil {
+		return err
+	}
+
+	// Check AGENTS.md mode
+	if err := validateAgentsMDMode(config.AgentsMD); err != nil {
+		return err
+	}
+
+	// Check output style names
+	if err := validateOutputStyles(config.OutputStyles); err != nil {
+		return err
+	}
+
+	// Check statusline style
+	if err := validateStatuslineStyle(config.Statusline); err != nil {
+		return err
+	}
+
+	// NewEngine checks a remote directory once it has connected
+	if config.Remote != nil {
+		return nil
 	}
-	config.TargetDir = absPath
 
 	// Check if target directory exists
 	info, err := os.Stat(config.TargetDir)
@@ -79,8 +556,8 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("target path is not a directory: %s", config.TargetDir)
 	}
 
-	// Check write permissions (unless dry-run)
-	if !config.DryRun {
+	// Check write permissions (unless nothing is written there)
+	if !config.DryRun && config.OutputArchive == "" && config.EmitPatch == "" {
 		// Try to create a temporary file to test write permissions
 		testFile := filepath.Join(config.TargetDir, ".cc-init-test")
 		f, err := os.Create(testFile)
@@ -92,4 +569,4 @@ func validateConfig(config *Config) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
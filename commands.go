@@ -0,0 +1,213 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Command is a cc-init subcommand, e.g. `cc-init add workflow claude-review`
+type Command struct {
+	Name    string
+	Usage   string
+	Summary string
+	Run     func(args []string) error
+}
+
+// commandTable returns the available subcommands
+func commandTable() []*Command {
+	return []*Command{
+		{
+			Name:    "add",
+			Usage:   "add workflow <name> [flags]",
+			Summary: tr("Add optional scaffolding such as GitHub workflows"),
+			Run:     runAdd,
+		},
+		{
+			Name:    "apply",
+			Usage:   "apply --patch <file> [flags]",
+			Summary: tr("Apply a patch written by --emit-patch, if every file is still as it was when the patch was made"),
+			Run:     runApply,
+		},
+		{
+			Name:    "apply-map",
+			Usage:   "apply-map [--map <file>] [-- <flags>]",
+			Summary: tr("Configure every subdirectory listed in .cc-init/targets.yaml in one run"),
+			Run:     runApplyMap,
+		},
+		{
+			Name:    "backups",
+			Usage:   "backups list|prune [flags]",
+			Summary: tr("List the backups under .claude/.backup or remove those outside the retention policy"),
+			Run:     runBackups,
+		},
+		{
+			Name:    "bundle",
+			Usage:   "bundle create [-o <file.ccb>] [flags] | bundle install <file.ccb> [flags]",
+			Summary: tr("Pack templates into one archive, or install such an archive as the template source of a machine without network access"),
+			Run:     runBundle,
+		},
+		{
+			Name:    "cache",
+			Usage:   "cache ls|path|clean [--older-than <age>]",
+			Summary: tr("List, locate or clean the template packs downloaded from HTTP template sources"),
+			Run:     runCache,
+		},
+		{
+			Name:    "changelog",
+			Usage:   "changelog [flags]",
+			Summary: tr("Show the changelog entries between the installed pack version and the one about to be installed"),
+			Run:     runChangelog,
+		},
+		{
+			Name:    "check",
+			Usage:   "check [--warn-only] [flags]",
+			Summary: tr("Fail when required files are missing or templates are older than .cc-init.yaml allows"),
+			Run:     runCheck,
+		},
+		{
+			Name:    "config",
+			Usage:   "config check|init [-t <dir>]",
+			Summary: tr("Check the organization, user and project configuration, or write .cc-init.yaml by answering a few questions"),
+			Run:     runConfig,
+		},
+		{
+			Name:    "export",
+			Usage:   "export --format <format> [flags]",
+			Summary: fmt.Sprintf(tr("Render CLAUDE.md sections and commands for another assistant (%s)"), strings.Join(exporterNames(), ", ")),
+			Run:     runExport,
+		},
+		{
+			Name:    "hash",
+			Usage:   "hash [--set <set>] [dir|remote]",
+			Summary: tr("Print the content digest of the embedded templates or a template directory"),
+			Run:     runHash,
+		},
+		{
+			Name:    "hook-mode",
+			Usage:   "hook-mode [flags]",
+			Summary: tr("Fail when .claude drifted from its lockfile, for pre-commit and husky"),
+			Run:     runHookMode,
+		},
+		{
+			Name:    "import",
+			Usage:   "import <source> [flags]",
+			Summary: fmt.Sprintf(tr("Convert another assistant's rules (%s) into Claude config"), strings.Join(importerNames(), ", ")),
+			Run:     runImport,
+		},
+		{
+			Name:    "lint",
+			Usage:   "lint [--template-dir <dir>]",
+			Summary: tr("Check a template pack for invalid settings, frontmatter and risky permissions"),
+			Run:     runLint,
+		},
+		{
+			Name:    "new-pack",
+			Usage:   "new-pack <dir> [--name <name>]",
+			Summary: tr("Create a template pack with an example agent, command, hook and snapshot test"),
+			Run:     runNewPack,
+		},
+		{
+			Name:    "paths",
+			Usage:   "paths [config|telemetry|cache|templates|state]",
+			Summary: tr("Print where cc-init keeps its user configuration, caches and state"),
+			Run:     runPaths,
+		},
+		{
+			Name:    "rollout",
+			Usage:   "rollout --repos <file> [--push] [--pr]",
+			Summary: tr("Apply cc-init to many repositories on a branch and open pull requests"),
+			Run:     runRollout,
+		},
+		{
+			Name:    "serve",
+			Usage:   "serve [--addr :8080] [--template-dir <dir>]",
+			Summary: tr("Share the templates over HTTP for use with --template-dir <url>"),
+			Run:     runServe,
+		},
+		{
+			Name:    "telemetry",
+			Usage:   "telemetry on|off|status",
+			Summary: tr("Opt in to or out of anonymous usage metrics"),
+			Run:     runTelemetry,
+		},
+		{
+			Name:    "test",
+			Usage:   "test [--template-dir <dir>] [--update]",
+			Summary: tr("Render a template pack for each fixture and compare with golden snapshots"),
+			Run:     runTest,
+		},
+		{
+			Name:    "upgrade",
+			Usage:   "upgrade [--all] [--status] [dir...] [-- <flags>]",
+			Summary: tr("Run cc-init --update in several projects and show which were behind, dirty or clean"),
+			Run:     runUpgrade,
+		},
+		{
+			Name:    "validate",
+			Usage:   "validate [flags]",
+			Summary: tr("Check .claude settings files against the Claude Code settings schema"),
+			Run:     runValidate,
+		},
+	}
+}
+
+// findCommand returns the subcommand called name, or nil
+func findCommand(name string) *Command {
+	for _, cmd := range commandTable() {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// printCommands lists the subcommands for usage output
+func printCommands() {
+	fmt.Fprint(os.Stderr, tr("\nCommands:\n"))
+	for _, cmd := range commandTable() {
+		fmt.Fprintf(os.Stderr, "  %-32s %s\n", cmd.Usage, cmd.Summary)
+	}
+	if names, aliases := aliasNames(); len(names) > 0 {
+		fmt.Fprint(os.Stderr, tr("\nAliases:\n"))
+		for _, name := range names {
+			fmt.Fprintf(os.Stderr, "  %-32s %s\n", name, aliases[name])
+		}
+	}
+	if plugins := listPlugins(); len(plugins) > 0 {
+		fmt.Fprint(os.Stderr, tr("\nPlugins:\n"))
+		for _, name := range plugins {
+			fmt.Fprintf(os.Stderr, "  %-32s %s\n", name, pluginPrefix+name)
+		}
+	}
+}
+
+// newCommandFlagSet creates a flag set with the flags shared by subcommands
+// that write into a target directory
+func newCommandFlagSet(cmd *Command, config *Config) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	defineWriteFlags(fs, config)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments, fills in the defaults of the configuration layers
+// and returns the positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, applyConfigDefaults(fs)
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Embedded schemas of the cc-init configuration files
+const (
+	projectConfigSchemaPath = "presets/schema/project-config.schema.json"
+	userConfigSchemaPath    = "presets/schema/user-config.schema.json"
+	orgConfigSchemaPath     = "presets/schema/org-config.schema.json"
+)
+
+// yamlErrorLine finds the line number in a YAML syntax error
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// yamlValue converts a YAML node into the values encoding/json decodes to,
+// numbers as json.Number, recording where each document path starts
+func yamlValue(node *yaml.Node, path string, positions map[string][2]int) interface{} {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		positions[path] = [2]int{node.Content[0].Line, node.Content[0].Column}
+		return yamlValue(node.Content[0], path, positions)
+	case yaml.AliasNode:
+		return yamlValue(node.Alias, path, positions)
+	case yaml.MappingNode:
+		object := map[string]interface{}{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			child := joinJSONPath(path, key.Value)
+			positions[child] = [2]int{key.Line, key.Column}
+			object[key.Value] = yamlValue(value, child, positions)
+		}
+		return object
+	case yaml.SequenceNode:
+		list := make([]interface{}, 0, len(node.Content))
+		for i, item := range node.Content {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			positions[child] = [2]int{item.Line, item.Column}
+			list = append(list, yamlValue(item, child, positions))
+		}
+		return list
+	}
+	switch node.ShortTag() {
+	case "!!null":
+		return nil
+	case "!!bool":
+		var b bool
+		node.Decode(&b)
+		return b
+	case "!!int", "!!float":
+		return json.Number(node.Value)
+	}
+	return node.Value
+}
+
+// checkConfigDocument checks a YAML configuration against the embedded
+// schema at schemaPath and its defaults against the flags of cc-init,
+// reporting positions
+func checkConfigDocument(schemaPath string, data []byte) ([]SchemaError, error) {
+	schema, err := loadSchema(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		line := 1
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		return []SchemaError{{Line: line, Column: 1, Message: "invalid YAML: " + err.Error()}}, nil
+	}
+	positions := map[string][2]int{"": {1, 1}}
+	doc := yamlValue(&node, "", positions)
+	if doc == nil {
+		return nil, nil
+	}
+
+	v := &schemaValidator{root: schema, scalarStrings: true, position: func(path string) (int, int) {
+		p := positions[path]
+		return p[0], p[1]
+	}}
+	v.validate(schema, doc, "")
+	if object, ok := doc.(map[string]interface{}); ok {
+		if defaults, ok := object["defaults"].(map[string]interface{}); ok {
+			checkFlagDefaults(v, defaults, schemaPath == projectConfigSchemaPath)
+		}
+	}
+	return v.sortedErrors(), nil
+}
+
+// checkFlagDefaults reports defaults that name no flag of cc-init or hold
+// a value their flag does not accept, and in a project configuration those
+// outside projectSafeFlags
+func checkFlagDefaults(v *schemaValidator, defaults map[string]interface{}, project bool) {
+	// The flags of an init run and those shared by commands
+	initFlags := flag.NewFlagSet("cc-init", flag.ContinueOnError)
+	defineInitFlags(initFlags, &Config{})
+	sharedFlags := newCommandFlagSet(&Command{Name: "config"}, &Config{})
+
+	for _, name := range sortedKeys(defaults) {
+		path := joinJSONPath("defaults", name)
+		fs := initFlags
+		if fs.Lookup(name) == nil {
+			fs = sharedFlags
+		}
+		if fs.Lookup(name) == nil {
+			v.fail(path, "unknown flag --%s", name)
+			continue
+		}
+		if project && !projectSafeFlags[name] {
+			v.fail(path, "a project may not set --%s; set it in the user or organization configuration", name)
+			continue
+		}
+		values, ok := defaults[name].([]interface{})
+		if !ok {
+			values = []interface{}{defaults[name]}
+		}
+		for _, value := range values {
+			if value == nil {
+				value = ""
+			}
+			if _, ok := value.(map[string]interface{}); ok {
+				v.fail(path, "expected a value or a list of values for --%s, got object", name)
+				break
+			}
+			if err := fs.Set(name, fmt.Sprint(value)); err != nil {
+				v.fail(path, "invalid value %s for --%s: %v", formatJSONValue(value), name, err)
+			}
+		}
+	}
+}
+
+// configFile is a configuration file `cc-init config check` reads
+type configFile struct {
+	path   string
+	schema string
+	data   []byte
+}
+
+// configFiles returns the configuration files that apply to runs in dir:
+// the organization's, the user's and the project's, where they exist
+func configFiles(dir string) ([]configFile, error) {
+	var files []configFile
+	if source := os.Getenv(orgConfigEnv); source != "" {
+		var data []byte
+		var err error
+		if isTemplateURL(source) {
+			data, err = fetchOrgConfig(source)
+		} else {
+			data, err = os.ReadFile(expandHome(source))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the organization configuration: %w", err)
+		}
+		files = append(files, configFile{path: source, schema: orgConfigSchemaPath, data: data})
+	}
+	if path, err := userConfigPath(); err == nil {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			files = append(files, configFile{path: path, schema: userConfigSchemaPath, data: data})
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	path := filepath.Join(dir, projectConfigFile)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		files = append(files, configFile{path: path, schema: projectConfigSchemaPath, data: data})
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return files, nil
+}
+
+// runConfig implements `cc-init config check|init`
+func runConfig(args []string) error {
+	cmd := findCommand("config")
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	var target string
+	fs.StringVar(&target, "target", "", tr("Project whose .cc-init.yaml to check or write (default: the git repository root, else the current directory)"))
+	fs.StringVar(&target, "t", "", tr("Project whose .cc-init.yaml to check or write (shorthand)"))
+	yes := fs.Bool("yes", false, tr("With init, ask nothing and write the defaults"))
+	force := fs.Bool("force", false, tr("With init, replace an existing .cc-init.yaml"))
+	noColor := fs.Bool("no-color", false, tr("Disable colored output"))
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 {
+		fs.Usage()
+		return fmt.Errorf("expected: cc-init config check|init")
+	}
+	// The configuration may be what is broken, so its defaults are not applied
+	action := args[0]
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if action != "check" && action != "init" {
+		fs.Usage()
+		return fmt.Errorf("unknown config action %q (expected check or init)", action)
+	}
+
+	logger := NewLogger(false, *noColor)
+	if target == "" {
+		dir, err := defaultTargetDir(&Config{})
+		if err != nil {
+			return err
+		}
+		target = dir
+	}
+	if action == "init" {
+		return runConfigInit(target, *yes, *force, logger)
+	}
+	files, err := configFiles(target)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		logger.Info("No configuration files apply to %s", target)
+		return nil
+	}
+
+	problems := 0
+	for _, file := range files {
+		errs, err := checkConfigDocument(file.schema, file.data)
+		if err != nil {
+			return err
+		}
+		for _, schemaErr := range errs {
+			logger.Error("%s:%s", file.path, schemaErr.Error())
+			problems++
+		}
+	}
+	if problems > 0 {
+		return fmt.Errorf("found %d configuration %s", problems, pluralize("problem", problems))
+	}
+	logger.Success("%d configuration %s passed the check", len(files), pluralize("file", len(files)))
+	return nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// embeddedSource is the answer for the templates built into cc-init
+const embeddedSource = "embedded"
+
+// configWizardAnswers are the choices `cc-init config init` writes; the
+// template source goes to the user configuration, since a project may not
+// choose it
+type configWizardAnswers struct {
+	TemplateDir string
+	Permissions string
+	Conflict    string
+	Vars        []string
+}
+
+// askConfigWizard asks for the template source, the permission preset, the
+// conflict policy and the variables of the chosen pack
+func askConfigWizard(r *bufio.Reader) (configWizardAnswers, error) {
+	var answers configWizardAnswers
+	w := os.Stderr
+
+	var pack fs.FS
+	for {
+		source, err := promptVariable(w, r, PackVariable{
+			Name:    "template-dir",
+			Help:    tr("Template source: a pack directory, the URL of cc-init serve, or embedded"),
+			Default: embeddedSource,
+		})
+		if err != nil {
+			return answers, err
+		}
+		switch {
+		case source == embeddedSource:
+			pack = templateFiles()
+		case isTemplateURL(source):
+			dir, err := fetchTemplatePack(source)
+			if err != nil {
+				fmt.Fprintf(w, tr("  Cannot fetch the pack: %v\n"), err)
+				continue
+			}
+			pack = os.DirFS(dir)
+			answers.TemplateDir = source
+		default:
+			if info, err := os.Stat(expandHome(source)); err != nil || !info.IsDir() {
+				fmt.Fprint(w, tr("  No such directory\n"))
+				continue
+			}
+			dir, err := filepath.Abs(expandHome(source))
+			if err != nil {
+				return answers, err
+			}
+			pack = os.DirFS(dir)
+			answers.TemplateDir = dir
+		}
+		break
+	}
+
+	permissions, err := promptVariable(w, r, PackVariable{
+		Name:    "permissions",
+		Type:    VarChoice,
+		Help:    tr("Permission preset"),
+		Choices: append([]string{"none"}, permissionPresetNames()...),
+		Default: "none",
+	})
+	if err != nil {
+		return answers, err
+	}
+	if permissions != "none" {
+		answers.Permissions = permissions
+	}
+
+	conflict, err := promptVariable(w, r, PackVariable{
+		Name:    "conflict",
+		Type:    VarChoice,
+		Help:    tr("How --update settles conflicting hunks"),
+		Choices: []string{"ask", ConflictOurs, ConflictTheirs, ConflictUnion, ConflictMarkers},
+		Default: "ask",
+	})
+	if err != nil {
+		return answers, err
+	}
+	if conflict != "ask" {
+		answers.Conflict = conflict
+	}
+
+	manifest, err := loadPackManifest(pack)
+	if err != nil {
+		return answers, err
+	}
+	if manifest != nil {
+		for _, v := range manifest.Variables {
+			if v.Command != "" {
+				continue
+			}
+			value, err := promptVariable(w, r, v)
+			if err != nil {
+				return answers, err
+			}
+			answers.Vars = append(answers.Vars, v.Name+"="+value)
+		}
+	}
+	return answers, nil
+}
+
+// runConfigInit implements `cc-init config init`: it asks a few questions
+// and writes the answers to .cc-init.yaml in target, and the template source
+// to the user configuration
+func runConfigInit(target string, yes, force bool, logger *Logger) error {
+	path := filepath.Join(target, projectConfigFile)
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists; pass --force to replace it", path)
+	}
+	var answers configWizardAnswers
+	if !yes {
+		if !stdinIsTerminal() {
+			return fmt.Errorf("config init asks questions; run it in a terminal, or pass --yes to write the defaults")
+		}
+		var err error
+		if answers, err = askConfigWizard(bufio.NewReader(os.Stdin)); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(path, []byte(renderProjectConfig(answers)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	logger.Success("Wrote %s", path)
+	if answers.TemplateDir != "" {
+		userPath, err := setUserDefault("template-dir", answers.TemplateDir)
+		if err != nil {
+			return err
+		}
+		logger.Success("Set template-dir in %s, since a project may not choose its template source", userPath)
+	}
+	return nil
+}
+
+// yamlScalar renders s as a YAML scalar, quoted where YAML needs it
+func yamlScalar(s string) string {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Sprintf("%q", s)
+	}
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+// renderProjectConfig writes the answers as a commented .cc-init.yaml; the
+// settings not chosen are left as commented examples
+func renderProjectConfig(answers configWizardAnswers) string {
+	var b strings.Builder
+	b.WriteString("# cc-init project configuration. Check it with `cc-init config check`.\n\n")
+	b.WriteString("# Flag values for every cc-init run in this project. They override the\n")
+	b.WriteString("# organization and user configuration; flags on the command line still win.\n")
+	if answers.Permissions == "" && answers.Conflict == "" && len(answers.Vars) == 0 {
+		b.WriteString("# defaults:\n")
+	} else {
+		b.WriteString("defaults:\n")
+	}
+	setting := func(comment, name, value, example string) {
+		fmt.Fprintf(&b, "  # %s\n", comment)
+		if value == "" {
+			fmt.Fprintf(&b, "  # %s: %s\n", name, example)
+		} else {
+			fmt.Fprintf(&b, "  %s: %s\n", name, yamlScalar(value))
+		}
+	}
+	setting("Permission preset: "+strings.Join(permissionPresetNames(), ", "), "permissions", answers.Permissions, "standard")
+	setting("How --update settles conflicting hunks: ours, theirs, union or markers", "conflict", answers.Conflict, "markers")
+	b.WriteString("  # Values of the template pack variables, as NAME=VALUE\n")
+	if len(answers.Vars) == 0 {
+		b.WriteString("  # var:\n  #   - owner=acme\n")
+	} else {
+		b.WriteString("  var:\n")
+		for _, v := range answers.Vars {
+			fmt.Fprintf(&b, "    - %s\n", yamlScalar(v))
+		}
+	}
+
+	b.WriteString(`
+# Files cc-init check requires, relative to the project; without this list
+# every template file is required
+# required:
+#   - .claude/settings.json
+
+# Severity of the cc-init check rules: error, warn or off
+# severity:
+#   drift: warn
+
+# Pin the version of the templates; cc-init refuses others
+# template_version: 1.2.0
+
+# Limits on the runs kept under .claude/.backup
+# backups:
+#   keep: 10
+#   max_age: 30d
+`)
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+)
+
+// devcontainerDir is the dev container configuration directory at the target root
+const devcontainerDir = ".devcontainer"
+
+// devcontainerFiles are the preset files installed by --devcontainer
+var devcontainerFiles = []string{"devcontainer.json", "Dockerfile"}
+
+// generateDevcontainer installs a dev container preconfigured for Claude Code
+func (e *Engine) generateDevcontainer() error {
+	if !e.config.Devcontainer {
+		return nil
+	}
+
+	dir := filepath.Join(e.config.TargetDir, devcontainerDir)
+	if err := e.processDirectory(dir); err != nil {
+		return err
+	}
+
+	for _, name := range devcontainerFiles {
+		content, err := presetFS.ReadFile(path.Join("presets", "devcontainer", name))
+		if err != nil {
+			return fmt.Errorf("failed to read devcontainer preset %s: %w", name, err)
+		}
+		if err := e.installFile(filepath.Join(dir, name), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
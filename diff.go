@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// ANSI attributes used by diff rendering
+const (
+	ColorCyan    = "\033[36m"
+	ColorBold    = "\033[1m"
+	ColorReverse = "\033[7m"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// maxDiffCells bounds the LCS table; larger inputs are shown as a full replacement
+const maxDiffCells = 4_000_000
+
+// diffLine is one line of a line-based diff: ' ' unchanged, '-' removed, '+' added
+type diffLine struct {
+	Kind byte
+	Text string
+}
+
+// splitLines splits text into lines without their trailing newlines
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes a minimal line diff of a and b using a longest common
+// subsequence table
+func diffLines(a, b []string) []diffLine {
+	var lines []diffLine
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			lines = append(lines, diffLine{'-', line})
+		}
+		for _, line := range b {
+			lines = append(lines, diffLine{'+', line})
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+	return lines
+}
+
+// renderUnifiedDiff renders the change from old to new as a unified diff.
+// With color, removals are red, additions green and hunk headers cyan, and
+// the changed part of a replaced line is highlighted; without color the
+// output is a plain unified diff. It returns "" when nothing changed.
+func renderUnifiedDiff(name string, old, new []byte, color bool) string {
+	lines := diffLines(splitLines(string(old)), splitLines(string(new)))
+
+	changed := false
+	for _, line := range lines {
+		if line.Kind != ' ' {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	paint := func(code, text string) string {
+		if !color {
+			return text
+		}
+		return code + text + ColorReset
+	}
+
+	oldName, newName := "a/"+name, "b/"+name
+	if filepath.IsAbs(name) {
+		oldName, newName = name, name
+	}
+	var b strings.Builder
+	b.WriteString(paint(ColorBold, "--- "+oldName) + "\n")
+	b.WriteString(paint(ColorBold, "+++ "+newName) + "\n")
+
+	for _, hunk := range diffHunks(lines) {
+		b.WriteString(paint(ColorCyan, hunk.header()) + "\n")
+		writeHunkLines(&b, hunk.Lines, color, paint)
+	}
+	return b.String()
+}
+
+// diffHunk is a run of changes with the unchanged lines around them; the
+// starts are 1-based, or the line before the hunk when it has no lines on
+// that side, as in diff -u
+type diffHunk struct {
+	OldStart, OldCount int
+	NewStart, NewCount int
+	Lines              []diffLine
+}
+
+// header returns the @@ line of the hunk
+func (h diffHunk) header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldCount, h.NewStart, h.NewCount)
+}
+
+// diffHunks groups the changes of a line diff into hunks with diffContext
+// lines of context
+func diffHunks(lines []diffLine) []diffHunk {
+	var changes []int
+	for i, line := range lines {
+		if line.Kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+
+	var hunks []diffHunk
+	for c := 0; c < len(changes); {
+		// Changes closer than twice the context share a hunk
+		last := c
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*diffContext {
+			last++
+		}
+		from := changes[c] - diffContext
+		if from < 0 {
+			from = 0
+		}
+		end := changes[last] + diffContext + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		hunk := diffHunk{OldStart: 1, NewStart: 1, Lines: lines[from:end]}
+		for _, line := range lines[:from] {
+			if line.Kind != '+' {
+				hunk.OldStart++
+			}
+			if line.Kind != '-' {
+				hunk.NewStart++
+			}
+		}
+		for _, line := range hunk.Lines {
+			if line.Kind != '+' {
+				hunk.OldCount++
+			}
+			if line.Kind != '-' {
+				hunk.NewCount++
+			}
+		}
+		if hunk.OldCount == 0 {
+			hunk.OldStart--
+		}
+		if hunk.NewCount == 0 {
+			hunk.NewStart--
+		}
+		hunks = append(hunks, hunk)
+		c = last + 1
+	}
+	return hunks
+}
+
+// writeHunkLines writes the lines of one hunk, pairing runs of removed and
+// added lines for intra-line highlighting
+func writeHunkLines(b *strings.Builder, lines []diffLine, color bool, paint func(code, text string) string) {
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == ' ' {
+			b.WriteString(" " + lines[i].Text + "\n")
+			i++
+			continue
+		}
+
+		removedEnd := i
+		for removedEnd < len(lines) && lines[removedEnd].Kind == '-' {
+			removedEnd++
+		}
+		addedEnd := removedEnd
+		for addedEnd < len(lines) && lines[addedEnd].Kind == '+' {
+			addedEnd++
+		}
+		removed, added := lines[i:removedEnd], lines[removedEnd:addedEnd]
+
+		for k, line := range removed {
+			text := line.Text
+			if color && len(removed) == len(added) {
+				text = highlightChange(line.Text, added[k].Text, ColorRed)
+			}
+			b.WriteString(paint(ColorRed, "-"+text) + "\n")
+		}
+		for k, line := range added {
+			text := line.Text
+			if color && len(removed) == len(added) {
+				text = highlightChange(line.Text, removed[k].Text, ColorGreen)
+			}
+			b.WriteString(paint(ColorGreen, "+"+text) + "\n")
+		}
+		i = addedEnd
+	}
+}
+
+// highlightChange marks the part of line that differs from other in reverse
+// video, restoring lineColor afterwards. Lines with nothing in common are
+// returned unchanged since highlighting all of them adds only noise.
+func highlightChange(line, other, lineColor string) string {
+	prefix := 0
+	for prefix < len(line) && prefix < len(other) && line[prefix] == other[prefix] {
+		prefix++
+	}
+	for prefix > 0 && prefix < len(line) && !utf8.RuneStart(line[prefix]) {
+		prefix--
+	}
+	suffix := 0
+	for suffix < len(line)-prefix && suffix < len(other)-prefix && line[len(line)-1-suffix] == other[len(other)-1-suffix] {
+		suffix++
+	}
+	for suffix > 0 && !utf8.RuneStart(line[len(line)-suffix]) {
+		suffix--
+	}
+
+	middle := line[prefix : len(line)-suffix]
+	if prefix+suffix == 0 || middle == "" {
+		return line
+	}
+	return line[:prefix] + ColorReverse + middle + ColorReset + lineColor + line[len(line)-suffix:]
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DockerFileSystem implements FileSystem inside a running container by
+// running POSIX shell utilities through docker exec, so the container only
+// needs sh
+type DockerFileSystem struct {
+	container string
+}
+
+// OpenDocker checks that the container is running and returns a
+// DockerFileSystem for it
+func OpenDocker(container string) (*DockerFileSystem, error) {
+	fs := &DockerFileSystem{container: container}
+	if _, err := fs.run(nil, "true"); err != nil {
+		return nil, fmt.Errorf("failed to reach container %s: %w", container, err)
+	}
+	return fs, nil
+}
+
+// Close does nothing; every operation is its own docker exec
+func (fs *DockerFileSystem) Close() error {
+	return nil
+}
+
+// run executes a shell script in the container with args as $1, $2, ...
+// and stdin as its standard input
+func (fs *DockerFileSystem) run(stdin []byte, script string, args ...string) ([]byte, error) {
+	cmdArgs := append([]string{"exec", "-i", fs.container, "sh", "-c", script, "sh"}, args...)
+	cmd := exec.Command("docker", cmdArgs...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return out, fmt.Errorf("%w: %s", err, msg)
+		}
+		return out, err
+	}
+	return out, nil
+}
+
+// notExist reports whether a script failed with exit status 2, which the
+// scripts below use for missing paths
+func notExist(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == 2
+}
+
+// Exists checks if a file or directory exists
+func (fs *DockerFileSystem) Exists(p string) bool {
+	_, err := fs.run(nil, `test -e "$1"`, p)
+	return err == nil
+}
+
+// CreateDir creates a directory if it doesn't exist
+func (fs *DockerFileSystem) CreateDir(p string, perm os.FileMode) error {
+	_, err := fs.run(nil, `
+[ -d "$1" ] && exit 0
+[ -e "$1" ] && { echo "path exists but is not a directory: $1" >&2; exit 1; }
+mkdir -p "$1" && chmod "$2" "$1"`, p, octalMode(perm))
+	return err
+}
+
+// CreateFile creates a file if it doesn't exist
+func (fs *DockerFileSystem) CreateFile(p string, content []byte, perm os.FileMode) error {
+	info, err := fs.Stat(p)
+	if err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("path exists but is a directory: %s", p)
+		}
+		return nil
+	}
+	return fs.WriteFile(p, content, perm)
+}
+
+// WriteFile writes a file, replacing any existing content
+func (fs *DockerFileSystem) WriteFile(p string, content []byte, perm os.FileMode) error {
+	_, err := fs.run(content, `mkdir -p "$(dirname "$1")" && cat > "$1" && chmod "$2" "$1"`, p, octalMode(perm))
+	return err
+}
+
+// ReadFile reads the content of a file
+func (fs *DockerFileSystem) ReadFile(p string) ([]byte, error) {
+	out, err := fs.run(nil, `[ -e "$1" ] || exit 2; cat "$1"`, p)
+	if notExist(err) {
+		return nil, &os.PathError{Op: "open", Path: p, Err: os.ErrNotExist}
+	}
+	return out, err
+}
+
+// statFormat prints raw mode (hex), size, mtime and name, one path per line
+const statFormat = `%f %s %Y %n`
+
+// Stat returns file info for the given path
+func (fs *DockerFileSystem) Stat(p string) (fs.FileInfo, error) {
+	out, err := fs.run(nil, `[ -e "$1" ] || exit 2; stat -c "$2" "$1"`, p, statFormat)
+	if notExist(err) {
+		return nil, &os.PathError{Op: "stat", Path: p, Err: os.ErrNotExist}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseStatLine(strings.TrimSpace(string(out)))
+}
+
+// Walk walks the file tree rooted at root in lexical order, like filepath.Walk
+func (fs *DockerFileSystem) Walk(root string, fn WalkFunc) error {
+	out, err := fs.run(nil, `[ -e "$1" ] || exit 2; find "$1" -exec stat -c "$2" {} +`, root, statFormat)
+	if err != nil {
+		if notExist(err) {
+			err = &os.PathError{Op: "lstat", Path: root, Err: os.ErrNotExist}
+		}
+		return fn(root, nil, err)
+	}
+
+	infos := map[string]os.FileInfo{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		info, err := parseStatLine(line)
+		if err != nil {
+			return err
+		}
+		infos[info.(*listedFileInfo).path] = info
+	}
+	return walkListing(root, infos, fn)
+}
+
+// Chmod sets the exact mode of a file or directory
+func (fs *DockerFileSystem) Chmod(p string, mode os.FileMode) error {
+	_, err := fs.run(nil, `chmod "$2" "$1"`, p, octalMode(mode))
+	return err
+}
+
+// Chown sets the owner of a file or directory; -1 keeps that id
+func (fs *DockerFileSystem) Chown(p string, uid, gid int) error {
+	owner := ""
+	if uid != -1 {
+		owner = strconv.Itoa(uid)
+	}
+	if gid != -1 {
+		owner += ":" + strconv.Itoa(gid)
+	}
+	_, err := fs.run(nil, `chown "$2" "$1"`, p, owner)
+	return err
+}
+
+// Chtimes sets the access and modification times of a file
+func (fs *DockerFileSystem) Chtimes(p string, mtime time.Time) error {
+	_, err := fs.run(nil, `TZ=UTC0 touch -t "$2" "$1"`, p, mtime.UTC().Format("200601021504.05"))
+	return err
+}
+
+// Remove deletes a file or an empty directory
+func (fs *DockerFileSystem) Remove(p string) error {
+	_, err := fs.run(nil, `[ -e "$1" ] || exit 2; rm -df "$1"`, p)
+	if notExist(err) {
+		return &os.PathError{Op: "remove", Path: p, Err: os.ErrNotExist}
+	}
+	return err
+}
+
+// octalMode formats permission bits for chmod
+func octalMode(mode os.FileMode) string {
+	return strconv.FormatUint(uint64(mode.Perm()), 8)
+}
+
+// parseStatLine parses one line of statFormat output
+func parseStatLine(line string) (fs.FileInfo, error) {
+	fields := strings.SplitN(line, " ", 4)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("unexpected stat output %q", line)
+	}
+	raw, err := strconv.ParseUint(fields[0], 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected stat output %q", line)
+	}
+	size, _ := strconv.ParseInt(fields[1], 10, 64)
+	mtime, _ := strconv.ParseInt(fields[2], 10, 64)
+
+	mode := fs.FileMode(raw & 0777)
+	switch raw & 0170000 {
+	case 0040000:
+		mode |= fs.ModeDir
+	case 0120000:
+		mode |= fs.ModeSymlink
+	}
+	return &listedFileInfo{path: fields[3], size: size, mode: mode, modTime: time.Unix(mtime, 0)}, nil
+}
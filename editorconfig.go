@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// editorConfigFile holds the formatting rules of the target project
+const editorConfigFile = ".editorconfig"
+
+// utf8BOM starts files whose charset is utf-8-bom
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// editorSection is a [glob] section of .editorconfig with its properties,
+// lowercased as the specification requires
+type editorSection struct {
+	re    *regexp.Regexp
+	props map[string]string
+}
+
+// parseEditorConfig reads the sections of an .editorconfig document;
+// sections with globs that cannot be translated are skipped
+func parseEditorConfig(data []byte) []editorSection {
+	var sections []editorSection
+	var current *editorSection
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = nil
+			if re, err := regexp.Compile(editorGlobRegexp(line[1 : len(line)-1])); err == nil {
+				sections = append(sections, editorSection{re: re, props: map[string]string{}})
+				current = &sections[len(sections)-1]
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || current == nil {
+			continue
+		}
+		current.props[strings.ToLower(strings.TrimSpace(key))] = strings.ToLower(strings.TrimSpace(value))
+	}
+	return sections
+}
+
+// editorGlobRegexp translates an EditorConfig glob into a regular expression
+// over slash-separated paths relative to the .editorconfig. Globs without a
+// slash match the base name in any directory.
+func editorGlobRegexp(glob string) string {
+	var re strings.Builder
+	re.WriteString("^")
+	if !strings.Contains(glob, "/") {
+		re.WriteString("(.*/)?")
+	}
+	glob = strings.TrimPrefix(glob, "/")
+	depth := 0
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				re.WriteString(".*")
+				i++
+			} else {
+				re.WriteString("[^/]*")
+			}
+		case '?':
+			re.WriteString("[^/]")
+		case '[':
+			if end := strings.IndexByte(glob[i:], ']'); end > 0 {
+				class := glob[i+1 : i+end]
+				if strings.HasPrefix(class, "!") {
+					class = "^" + class[1:]
+				}
+				re.WriteString("[" + class + "]")
+				i += end
+			} else {
+				re.WriteString(`\[`)
+			}
+		case '{':
+			depth++
+			re.WriteString("(?:")
+		case '}':
+			if depth > 0 {
+				depth--
+				re.WriteString(")")
+			} else {
+				re.WriteString(`\}`)
+			}
+		case ',':
+			if depth > 0 {
+				re.WriteString("|")
+			} else {
+				re.WriteString(",")
+			}
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				re.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+	return re.String()
+}
+
+// editorProps returns the properties for targetPath from the .editorconfig
+// of the target root, later sections overriding earlier ones
+func (e *Engine) editorProps(targetPath string) map[string]string {
+	if !e.editorRead {
+		e.editorRead = true
+		if data, err := e.fs.ReadFile(filepath.Join(e.config.TargetDir, editorConfigFile)); err == nil {
+			e.editorRules = parseEditorConfig(data)
+		}
+	}
+	props := map[string]string{}
+	rel := filepath.ToSlash(e.formatPath(targetPath))
+	for _, section := range e.editorRules {
+		if section.re.MatchString(rel) {
+			for key, value := range section.props {
+				props[key] = value
+			}
+		}
+	}
+	return props
+}
+
+// applyEditorConfig formats text content for targetPath as the project's
+// .editorconfig asks: indentation, trailing whitespace, final newline,
+// charset and, unless --line-endings is given, line endings. Markdown keeps
+// its indentation, which carries meaning, and YAML is never indented with
+// tabs.
+func (e *Engine) applyEditorConfig(targetPath string, content []byte) []byte {
+	if content == nil || bytes.IndexByte(content, 0) >= 0 {
+		return content
+	}
+	props := e.editorProps(targetPath)
+	if len(props) == 0 {
+		return content
+	}
+	text := strings.ReplaceAll(string(bytes.TrimPrefix(content, utf8BOM)), "\r\n", "\n")
+	eol := "\n"
+	if strings.Contains(string(content), "\r\n") {
+		eol = "\r\n"
+	}
+
+	ext := strings.ToLower(filepath.Ext(targetPath))
+	style := props["indent_style"]
+	if ext == ".md" || ext == ".markdown" || (style == "tab" && (ext == ".yml" || ext == ".yaml")) {
+		style = ""
+	}
+	if style == "tab" || style == "space" {
+		size, err := strconv.Atoi(props["indent_size"])
+		if props["indent_size"] == "tab" || err != nil {
+			size, err = strconv.Atoi(props["tab_width"])
+		}
+		if err != nil || size <= 0 {
+			size = 4
+		}
+		text = reindent(text, style == "tab", size)
+	}
+
+	if props["trim_trailing_whitespace"] == "true" {
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(line, " \t")
+		}
+		text = strings.Join(lines, "\n")
+	}
+	switch props["insert_final_newline"] {
+	case "true":
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+	case "false":
+		text = strings.TrimRight(text, "\n")
+	}
+
+	if e.config.LineEndings == "" {
+		switch props["end_of_line"] {
+		case "lf":
+			eol = "\n"
+		case "crlf":
+			if ext != ".sh" {
+				eol = "\r\n"
+			}
+		}
+	}
+	out := []byte(strings.ReplaceAll(text, "\n", eol))
+	bom := bytes.HasPrefix(content, utf8BOM)
+	switch charset := props["charset"]; charset {
+	case "utf-8":
+		bom = false
+	case "utf-8-bom":
+		bom = true
+	case "":
+	default:
+		// latin1 and utf-16 would change the bytes of every non-ASCII
+		// character; templates stay UTF-8
+		e.logger.Debug("Ignoring charset %s for %s", charset, e.formatPath(targetPath))
+	}
+	if bom {
+		out = append(append([]byte{}, utf8BOM...), out...)
+	}
+	return out
+}
+
+// reindent rewrites the leading whitespace of every line with tabs or with
+// size spaces per level. The indentation unit of the input is its smallest
+// space indent, or a tab.
+func reindent(text string, tabs bool, size int) string {
+	lines := strings.Split(text, "\n")
+	unit := 0
+	for _, line := range lines {
+		n := len(line) - len(strings.TrimLeft(line, " "))
+		if n > 0 && n < len(line) && (unit == 0 || n < unit) {
+			unit = n
+		}
+	}
+	if unit == 0 {
+		unit = size
+	}
+	for i, line := range lines {
+		body := strings.TrimLeft(line, " \t")
+		if body == "" {
+			continue
+		}
+		lead := line[:len(line)-len(body)]
+		levels, spaces := strings.Count(lead, "\t"), strings.Count(lead, " ")
+		levels += spaces / unit
+		rest := spaces % unit
+		if tabs {
+			lines[i] = strings.Repeat("\t", levels) + strings.Repeat(" ", rest) + body
+		} else {
+			lines[i] = strings.Repeat(" ", levels*size+rest) + body
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,60 +1,284 @@
 package main
 
 import (
-	"embed"
+	"bytes"
 	"fmt"
+	"io"
 	"io/fs"
+	mathrand "math/rand/v2"
+	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 )
 
 // Engine is the core orchestrator for the cc-init tool
 type Engine struct {
-	templateFS embed.FS
-	config     *Config
-	logger     *Logger
-	fs         FileSystem
-	tmpl       *TemplateManager
-	stats      Statistics
+	templateFS  fs.FS
+	config      *Config
+	logger      *Logger
+	fs          FileSystem
+	tmpl        *TemplateManager
+	reporter    Reporter
+	stats       Statistics
+	sections    []ManagedSection
+	started     time.Time
+	timing      *TimingFileSystem
+	timings     Timings
+	eolRules    []eolRule
+	eolLoaded   bool
+	editorRules []editorSection
+	editorRead  bool
+	chownFailed bool
+	closers     []io.Closer
+	templateSet string
+	manifest    *PackManifest
+	wasm        *WASMPlugins
+	seeded      *mathrand.ChaCha8
+	headers     []HeaderRule
+	headerOwner string
+	headersRead bool
+	vars        map[string]interface{}
+	project     *ProjectInfo
+	backupRun   string
+	journal     []JournalEntry
+	pruned      []string
+	prevLock    *Lock
+	lockLoaded  bool
 }
 
 // Statistics tracks the operation results
 type Statistics struct {
 	FilesCreated      int
 	FilesSkipped      int
+	FilesUpdated      int
+	FilesRemoved      int
 	DirsCreated       int
 	DirsSkipped       int
 	Errors            []error
+	Records           []FileRecord
 }
 
-// NewEngine creates a new Engine instance
-func NewEngine(templateFS embed.FS, config *Config) *Engine {
-	logger := NewLogger(config.Verbose, config.NoColor)
+// Actions recorded for each processed path
+const (
+	ActionCreated = "created"
+	ActionSkipped = "skipped"
+	ActionUpdated = "updated"
+	ActionRemoved = "removed"
+	ActionFailed  = "failed"
+)
+
+// FileRecord describes what happened to a single target path
+type FileRecord struct {
+	Path       string  `json:"path"`
+	IsDir      bool    `json:"is_dir"`
+	Action     string  `json:"action"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// NewEngine creates a new Engine instance, connecting to the target host
+// for remote targets
+func NewEngine(templateFS fs.FS, config *Config) (*Engine, error) {
+	// Machine-readable reports own stdout, so progress goes to stderr
+	var logWriter io.Writer = os.Stdout
+	if config.ReportFormat == ReportJSON || config.ReportFormat == ReportMarkdown || config.SummaryFormat != "" {
+		logWriter = os.Stderr
+	}
+	logger := NewLoggerWithWriter(config.Verbose, config.NoColor, logWriter)
+	if config.LogLevel != "" {
+		logger.SetLevel(config.LogLevel)
+	}
+	if config.LogFormat != "" {
+		logger.SetFormat(config.LogFormat)
+	}
+	if config.LogFormat == LogFormatJSON {
+		// JSON logs feed log pipelines, which expect stable English messages
+		currentLocale = LocaleEN
+	}
+	theme := defaultTheme
+	if config.ASCII {
+		theme = asciiTheme
+	}
+	if config.Theme != "" {
+		loaded, err := LoadTheme(config.Theme, theme)
+		if err != nil {
+			logger.Warning("Cannot load theme: %v", err)
+		}
+		theme = loaded
+	}
+	logger.SetTheme(theme)
+	if config.Remote == nil && config.LogFormat != LogFormatJSON && !config.NoColor && supportsHyperlinks(os.Getenv, logWriter) {
+		logger.SetHyperlinks(config.TargetDir)
+	}
+	if config.LogFile != "" {
+		// The file stays open until the process exits
+		file, err := os.OpenFile(config.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			logger.Warning("Cannot open log file %s: %v", config.LogFile, err)
+		} else {
+			logger.SetFile(file)
+		}
+	}
 	
-	var fileSystem FileSystem = NewOSFileSystem()
+	osFS := NewOSFileSystem()
+	osFS.SetRetryPolicy(RetryPolicy{Retries: config.Retries, Delay: config.RetryDelay}, logger)
+	var base FileSystem = osFS
+	var closers []io.Closer
+	if config.Remote != nil {
+		logger.Debug("Connecting to %s", config.Remote)
+		remote, err := openRemote(config.Remote)
+		if err != nil {
+			return nil, err
+		}
+		if info, err := remote.Stat(config.TargetDir); err != nil || !info.IsDir() {
+			remote.Close()
+			return nil, fmt.Errorf("target directory does not exist: %s", config.Remote)
+		}
+		base = remote
+		closers = append(closers, remote)
+	}
+	timing := NewTimingFileSystem(base)
+	var fileSystem FileSystem = timing
+	if config.OutputArchive != "" {
+		// validateConfig has already checked --mtime
+		mtime, _ := resolveMtime(config.Mtime)
+		archive := NewArchiveFileSystem(fileSystem, config.TargetDir, config.OutputArchive, mtime, logger)
+		fileSystem = archive
+		// The archive is written before a remote connection closes
+		closers = append([]io.Closer{archive}, closers...)
+	}
+	if config.EmitPatch != "" {
+		patch := NewPatchFileSystem(fileSystem, config.TargetDir, config.EmitPatch, logger)
+		fileSystem = patch
+		closers = append([]io.Closer{patch}, closers...)
+	}
 	if config.DryRun {
 		fileSystem = NewDryRunFileSystem(fileSystem, logger)
 	}
 	
-	return &Engine{
+	reporter, err := NewReporter(config.ReportFormat, logger, os.Stdout)
+	if err != nil {
+		// validateConfig rejects unknown formats, so fall back quietly
+		reporter = &ConsoleReporter{logger: logger}
+	}
+	if config.SummaryFormat != "" {
+		// validateConfig has already parsed the template once
+		if tmpl, err := parseSummaryTemplate(config.SummaryFormat); err == nil {
+			reporter = &TemplateReporter{tmpl: tmpl, writer: os.Stdout}
+		}
+	}
+	if config.ReportFile != "" {
+		reporter = &ReportFileReporter{Reporter: reporter, path: config.ReportFile, dryRun: config.DryRun}
+	}
+	reporter = withGitHubReporter(reporter, os.Getenv)
+	if config.NotifyURL != "" {
+		reporter = NewNotifyReporter(reporter, config.NotifyURL, logger)
+	}
+
+	engine := &Engine{
 		templateFS: templateFS,
 		config:     config,
 		logger:     logger,
 		fs:         fileSystem,
-		tmpl:       NewTemplateManager(templateFS, ".claude"),
+		reporter:   reporter,
 		stats:      Statistics{},
+		started:    time.Now(),
+		timing:     timing,
+		closers:    closers,
+	}
+	if err := engine.selectTemplates(); err != nil {
+		engine.Close()
+		return nil, err
+	}
+	return engine, nil
+}
+
+// selectTemplates picks the templates to install: --template-dir, --set, or
+// the set the target was initialized with, so later runs stay on that set
+func (e *Engine) selectTemplates() error {
+	var pack fs.FS
+	if e.config.TemplateDir != "" {
+		pack = os.DirFS(e.config.TemplateDir)
+	} else {
+		set := e.config.TemplateSet
+		if set == "" {
+			set = defaultTemplateSet
+			if lock, err := e.sharedLock(); err == nil && lock.Set != "" {
+				if err := validateTemplateSet(lock.Set); err != nil {
+					return fmt.Errorf("%s selects template set %q, which this build does not include; pass --set", lockFile, lock.Set)
+				}
+				set = lock.Set
+			}
+		}
+		var err error
+		if pack, err = openTemplateSet(set); err != nil {
+			return err
+		}
+		e.templateSet = set
+	}
+	e.tmpl = NewTemplateManager(pack, ".")
+
+	manifest, err := loadPackManifest(pack)
+	if err != nil {
+		return err
+	}
+	e.manifest = manifest
+	if manifest != nil && len(manifest.WASM) > 0 {
+		if e.wasm, err = LoadWASMPlugins(pack, manifest.WASM); err != nil {
+			return err
+		}
+		e.closers = append(e.closers, e.wasm)
+	}
+	return nil
+}
+
+// Close writes a pending --output-archive and releases the connection of a
+// remote target
+func (e *Engine) Close() error {
+	var first error
+	for _, closer := range e.closers {
+		if err := closer.Close(); err != nil && first == nil {
+			first = err
+		}
 	}
+	return first
 }
 
 // Run executes the main initialization process
 func (e *Engine) Run() error {
 	e.logger.Debug("Starting cc-init with target directory: %s", e.config.TargetDir)
+	if e.config.AtGitRoot {
+		e.logger.Info("Using the git repository root %s; pass -t . or --no-git-root for the current directory", e.config.TargetDir)
+	}
 	
 	// Check if templates exist
 	if !e.tmpl.HasTemplates() {
 		return fmt.Errorf("no template files found in embedded .claude directory")
 	}
 	
+	// Refuse templates other than the version .cc-init.yaml pins
+	if err := e.enforceTemplatePin(); err != nil {
+		return err
+	}
+	
+	// Refuse templates older than the organization policy allows
+	if err := e.enforcePolicy(); err != nil {
+		return err
+	}
+	
+	// Add the hooks of --preset and of the languages the target uses
+	e.applyFrameworkPreset()
+	e.autodetectHooks()
+	
+	// Say what an update of the pack changes
+	e.announceChanges()
+	
+	// Ask for the variables of the pack before rendering
+	if err := e.resolveVariables(); err != nil {
+		return err
+	}
+	
 	// List all templates if verbose
 	if e.config.Verbose {
 		templates, err := e.tmpl.ListTemplates()
@@ -74,12 +298,32 @@ func (e *Engine) Run() error {
 			return nil // Continue processing other files
 		}
 		
-		targetPath := filepath.Join(e.config.TargetDir, ".claude", filepath.FromSlash(path))
+		if err := checkWindowsPath(path); err != nil {
+			if runtime.GOOS == "windows" {
+				e.logger.Error("Invalid template path %v", err)
+				e.stats.Errors = append(e.stats.Errors, err)
+				return nil
+			}
+			e.logger.Warning("Template path is not portable: %v", err)
+		}
+		
+		targetPath := filepath.Join(e.config.TargetDir, filepath.FromSlash(templateTarget(path)))
 		
 		if entry.IsDir() {
 			return e.processDirectory(targetPath)
 		}
 		
+		// The manifest and WASM modules belong to the pack and are not
+		// installed
+		if e.manifest.packOnly(path) {
+			return nil
+		}
+		
+		// Settings are written by generateSettings when flags customize them
+		if path == settingsFile && e.hasSettingsPatch() {
+			return nil
+		}
+		
 		return e.processFile(path, targetPath)
 	})
 	
@@ -87,13 +331,40 @@ func (e *Engine) Run() error {
 		return fmt.Errorf("failed to process templates: %w", err)
 	}
 	
+	// Write files derived from flags rather than copied verbatim
+	return e.Apply(e.generators()...)
+}
+
+// Apply runs the given steps, then reports the accumulated results; a failing
+// step is recorded and does not stop later steps
+func (e *Engine) Apply(steps ...func() error) error {
+	for _, step := range steps {
+		// Helpers such as installFile record their own errors
+		recorded := len(e.stats.Errors)
+		if err := step(); err != nil && len(e.stats.Errors) == recorded {
+			e.logger.Error("%v", err)
+			e.stats.Errors = append(e.stats.Errors, err)
+		}
+	}
+	
+	if err := e.writeAudit(); err != nil {
+		e.logger.Error("%v", err)
+		e.stats.Errors = append(e.stats.Errors, err)
+	}
+	
 	// Show summary
-	e.showSummary()
+	if err := e.reporter.Report(e.report()); err != nil {
+		return err
+	}
 	
 	// Return error if there were any critical errors
 	if len(e.stats.Errors) > 0 {
 		return fmt.Errorf("completed with %d errors", len(e.stats.Errors))
 	}
+
+	if e.config.CI && e.config.DryRun && changesPending(e.stats) {
+		return ErrChangesPending
+	}
 	
 	return nil
 }
@@ -116,18 +387,26 @@ func (e *Engine) processDirectory(targetPath string) error {
 		
 		e.logger.DirSkipped(e.formatPath(targetPath))
 		e.stats.DirsSkipped++
+		e.record(targetPath, true, ActionSkipped)
 		return nil
 	}
 	
-	err := e.fs.CreateDir(targetPath, e.tmpl.GetDefaultDirMode())
+	mode, _ := e.resolveMode(targetPath, e.tmpl.GetDefaultDirMode())
+	created := append(e.missingParents(targetPath), targetPath)
+	err := e.fs.CreateDir(targetPath, mode)
+	if err == nil {
+		err = e.finishWrite(targetPath, mode, true, created)
+	}
 	if err != nil {
 		e.logger.Error("Failed to create directory %s: %v", targetPath, err)
 		e.stats.Errors = append(e.stats.Errors, err)
+		e.record(targetPath, true, ActionFailed)
 		return err
 	}
 	
 	e.logger.DirCreated(e.formatPath(targetPath))
 	e.stats.DirsCreated++
+	e.record(targetPath, true, ActionCreated)
 	return nil
 }
 
@@ -135,6 +414,77 @@ func (e *Engine) processDirectory(targetPath string) error {
 func (e *Engine) processFile(sourcePath, targetPath string) error {
 	e.logger.Debug("Processing file: %s -> %s", sourcePath, targetPath)
 	
+	existed := e.fs.Exists(targetPath)
+	if existed && !e.config.Overwrite && !e.config.Update && (e.wasm == nil || !e.wasm.HasPolicy()) {
+		return e.installFile(targetPath, nil, 0)
+	}
+	
+	// Read the source file
+	start := time.Now()
+	content, err := e.tmpl.ReadFile(sourcePath)
+	e.timings.Resolve += time.Since(start)
+	if err != nil {
+		e.logger.Error("Failed to read template file %s: %v", sourcePath, err)
+		e.stats.Errors = append(e.stats.Errors, err)
+		return err
+	}
+	
+	// Render .tmpl files
+	if isTemplateFile(sourcePath) {
+		start := time.Now()
+		content, err = e.renderTemplate(sourcePath, content)
+		e.timings.Render += time.Since(start)
+		if err != nil {
+			err = fmt.Errorf("failed to render %s: %w", sourcePath, err)
+			e.logger.Error("%v", err)
+			e.stats.Errors = append(e.stats.Errors, err)
+			e.record(targetPath, false, ActionFailed)
+			return err
+		}
+	}
+	
+	// Get file mode
+	mode := e.tmpl.GetDefaultFileMode(sourcePath)
+	
+	// A conflict policy of the pack decides about existing files
+	if e.wasm != nil && e.wasm.HasPolicy() && e.fs.Exists(targetPath) && !e.config.Overwrite {
+		return e.resolveConflict(sourcePath, targetPath, content, mode)
+	}
+	
+	// --watch replaces earlier output with the edited template
+	if e.config.Overwrite {
+		err := e.updateFile(targetPath, mode, func(existing []byte) ([]byte, error) {
+			return content, nil
+		})
+		if err != nil {
+			e.logger.Error("%v", err)
+			e.stats.Errors = append(e.stats.Errors, err)
+		}
+		return err
+	}
+	
+	// --update merges newer templates into existing files
+	if e.config.Update && existed {
+		err := e.updateTemplate(targetPath, content, mode)
+		if err != nil {
+			e.logger.Error("%v", err)
+			e.stats.Errors = append(e.stats.Errors, err)
+		}
+		return err
+	}
+	
+	if err := e.installFile(targetPath, content, mode); err != nil || existed {
+		return err
+	}
+	final, err := e.finalContent(targetPath, content)
+	if err != nil {
+		return err
+	}
+	return e.saveBase(targetPath, final)
+}
+
+// installFile creates targetPath with content, skipping it if it already exists
+func (e *Engine) installFile(targetPath string, content []byte, mode fs.FileMode) error {
 	if e.fs.Exists(targetPath) {
 		info, err := e.fs.Stat(targetPath)
 		if err != nil {
@@ -149,107 +499,212 @@ func (e *Engine) processFile(sourcePath, targetPath string) error {
 		
 		e.logger.FileSkipped(e.formatPath(targetPath))
 		e.stats.FilesSkipped++
+		e.record(targetPath, false, ActionSkipped)
 		return nil
 	}
 	
-	// Read the source file
-	content, err := e.tmpl.ReadFile(sourcePath)
+	// Create the file
+	content, err := e.addHeader(targetPath, content)
 	if err != nil {
-		e.logger.Error("Failed to read template file %s: %v", sourcePath, err)
+		e.logger.Error("Failed to create file %s: %v", targetPath, err)
 		e.stats.Errors = append(e.stats.Errors, err)
+		e.record(targetPath, false, ActionFailed)
 		return err
 	}
-	
-	// Get file mode
-	mode := e.tmpl.GetDefaultFileMode(sourcePath)
-	
-	// Create the file
-	err = e.fs.CreateFile(targetPath, content, mode)
+	content = e.normalizeLineEndings(targetPath, e.applyEditorConfig(targetPath, content))
+	mode, _ = e.resolveMode(targetPath, mode)
+	created := append(e.missingParents(targetPath), targetPath)
+	err = e.checkSecrets(targetPath, content)
+	if err == nil {
+		err = e.fs.CreateFile(targetPath, content, mode)
+	}
+	if err == nil {
+		err = e.finishWrite(targetPath, mode, false, created)
+	}
 	if err != nil {
 		e.logger.Error("Failed to create file %s: %v", targetPath, err)
 		e.stats.Errors = append(e.stats.Errors, err)
+		e.record(targetPath, false, ActionFailed)
 		return err
 	}
 	
 	e.logger.FileCreated(e.formatPath(targetPath))
 	e.stats.FilesCreated++
+	e.record(targetPath, false, ActionCreated)
 	return nil
 }
 
-// formatPath formats a path for display
-func (e *Engine) formatPath(path string) string {
-	// Try to make path relative to target directory for cleaner output
-	if relPath, err := filepath.Rel(e.config.TargetDir, path); err == nil && !strings.HasPrefix(relPath, "..") {
-		return relPath
+// generators returns the steps that produce files from flags, run after the
+// template walk in order
+func (e *Engine) generators() []func() error {
+	return []func() error{
+		e.generateHookScripts,
+		e.generateStatusline,
+		e.generateOutputStyles,
+		e.generateSettings,
+		e.generateMCPConfig,
+		e.generateClaudeMD,
+		e.generateFrameworkGuidance,
+		e.generateWorkspaces,
+		e.offerImports,
+		e.generateLocalOverrides,
+		e.generateDevcontainer,
+		e.writeMemoryFiles,
+		e.checkSettings,
+		e.pruneOrphans,
+		e.writeJournal,
+		e.rotateBackups,
+		e.writeLock,
+		e.writeProvenance,
 	}
-	return path
 }
 
-// showSummary displays the operation summary
-func (e *Engine) showSummary() {
-	totalCreated := e.stats.FilesCreated + e.stats.DirsCreated
-	totalSkipped := e.stats.FilesSkipped + e.stats.DirsSkipped
-	
-	fmt.Println() // Empty line before summary
+// updateFile creates or rewrites a generated file; build receives the existing
+// content (nil if the file is missing) and returns the desired content
+func (e *Engine) updateFile(targetPath string, mode fs.FileMode, build func(existing []byte) ([]byte, error)) error {
+	var existing []byte
+	exists := e.fs.Exists(targetPath)
+	if exists {
+		info, err := e.fs.Stat(targetPath)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", targetPath, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("path exists but is a directory: %s", targetPath)
+		}
+		existing, err = e.fs.ReadFile(targetPath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", targetPath, err)
+		}
+	}
 	
-	if e.config.DryRun {
-		e.logger.Info("DRY RUN - No changes were made")
-		fmt.Println()
+	start := time.Now()
+	content, err := build(existing)
+	e.timings.Render += time.Since(start)
+	if err != nil {
+		e.record(targetPath, false, ActionFailed)
+		return fmt.Errorf("%s: %w", e.formatPath(targetPath), err)
 	}
+	if content, err = e.addHeader(targetPath, content); err != nil {
+		e.record(targetPath, false, ActionFailed)
+		return err
+	}
+	content = e.normalizeLineEndings(targetPath, e.applyEditorConfig(targetPath, content))
 	
-	// Show what was created
-	if totalCreated > 0 {
-		items := []string{}
-		if e.stats.FilesCreated > 0 {
-			items = append(items, fmt.Sprintf("%d %s", e.stats.FilesCreated, pluralize("file", e.stats.FilesCreated)))
-		}
-		if e.stats.DirsCreated > 0 {
-			items = append(items, fmt.Sprintf("%d %s", e.stats.DirsCreated, pluralize("directory", e.stats.DirsCreated)))
-		}
-		e.logger.Success("Created %s", strings.Join(items, " and "))
+	if exists && bytes.Equal(existing, content) {
+		e.logger.FileSkipped(e.formatPath(targetPath))
+		e.stats.FilesSkipped++
+		e.record(targetPath, false, ActionSkipped)
+		return nil
 	}
 	
-	// Show what was skipped
-	if totalSkipped > 0 {
-		items := []string{}
-		if e.stats.FilesSkipped > 0 {
-			items = append(items, fmt.Sprintf("%d %s", e.stats.FilesSkipped, pluralize("file", e.stats.FilesSkipped)))
-		}
-		if e.stats.DirsSkipped > 0 {
-			items = append(items, fmt.Sprintf("%d %s", e.stats.DirsSkipped, pluralize("directory", e.stats.DirsSkipped)))
+	if err := e.checkSecrets(targetPath, content); err != nil {
+		e.record(targetPath, false, ActionFailed)
+		return err
+	}
+	if exists {
+		if err := e.trashFile(targetPath, existing); err != nil {
+			e.record(targetPath, false, ActionFailed)
+			return err
 		}
-		e.logger.Info("Skipped %s (already exist)", strings.Join(items, " and "))
+	}
+	mode, _ = e.resolveMode(targetPath, mode)
+	created := append(e.missingParents(targetPath), targetPath)
+	if err := e.fs.WriteFile(targetPath, content, mode); err != nil {
+		e.record(targetPath, false, ActionFailed)
+		return fmt.Errorf("failed to write file %s: %w", targetPath, err)
+	}
+	if err := e.finishWrite(targetPath, mode, false, created); err != nil {
+		e.record(targetPath, false, ActionFailed)
+		return err
 	}
 	
-	// Show errors if any
-	if len(e.stats.Errors) > 0 {
-		e.logger.Error("Encountered %d %s during initialization", len(e.stats.Errors), pluralize("error", len(e.stats.Errors)))
-		if e.config.Verbose {
-			for _, err := range e.stats.Errors {
-				e.logger.Error("  - %v", err)
-			}
-		}
+	if exists {
+		e.logger.FileUpdated(e.formatPath(targetPath))
+		e.stats.FilesUpdated++
+		e.record(targetPath, false, ActionUpdated)
+		return nil
 	}
 	
-	// Final status
-	if totalCreated == 0 && totalSkipped > 0 {
-		e.logger.Info("All Claude configuration files already exist")
-	} else if len(e.stats.Errors) == 0 {
-		e.logger.Success("Claude configuration initialized successfully")
+	e.logger.FileCreated(e.formatPath(targetPath))
+	e.stats.FilesCreated++
+	e.record(targetPath, false, ActionCreated)
+	return nil
+}
+
+// finishWrite applies --chmod, --chown and --mtime to a path that was just
+// written; created lists it together with any parent directories made for it
+func (e *Engine) finishWrite(targetPath string, mode fs.FileMode, isDir bool, created []string) error {
+	if err := e.fixMode(targetPath, mode); err != nil {
+		return err
+	}
+	if err := e.fixOwner(created...); err != nil {
+		return err
+	}
+	if isDir {
+		// Directory mtimes change as their contents are written
+		return nil
+	}
+	return e.fixTimes(targetPath)
+}
+
+// formatPath formats a path for display
+func (e *Engine) formatPath(path string) string {
+	// Try to make path relative to target directory for cleaner output
+	if relPath, err := filepath.Rel(e.config.TargetDir, path); err == nil && !strings.HasPrefix(relPath, "..") {
+		return relPath
+	}
+	return path
+}
+
+// record appends a per-path record for reporters
+func (e *Engine) record(targetPath string, isDir bool, action string) {
+	e.stats.Records = append(e.stats.Records, FileRecord{
+		Path:       filepath.ToSlash(e.formatPath(targetPath)),
+		IsDir:      isDir,
+		Action:     action,
+		DurationMS: milliseconds(e.timing.Spent(targetPath)),
+	})
+}
+
+// report builds the Report handed to the configured Reporter
+func (e *Engine) report() *Report {
+	digest, _ := e.tmpl.Digest()
+	return &Report{
+		TargetDir:   e.config.TargetDir,
+		Templates:   digest,
+		DryRun:      e.config.DryRun,
+		Verbose:     e.config.Verbose,
+		Stats:       e.stats,
+		Duration:    time.Since(e.started),
+		Timings:     e.currentTimings(),
+		ShowTimings: e.config.ShowTimings,
 	}
 }
 
+// currentTimings returns the phase timings of the run so far
+func (e *Engine) currentTimings() Timings {
+	timings := e.timings
+	timings.Total = time.Since(e.started)
+	timings.Write = e.timing.total
+	return timings
+}
+
 // pluralize returns the plural form of a word if count != 1
 func pluralize(word string, count int) string {
 	if count == 1 {
-		return word
+		return tr(word)
 	}
 	
 	// Handle special cases
 	switch word {
 	case "directory":
-		return "directories"
+		return tr("directories")
+	case "entry":
+		return tr("entries")
+	case "repository":
+		return tr("repositories")
 	default:
-		return word + "s"
+		return tr(word + "s")
 	}
 }
\ No newline at end of file
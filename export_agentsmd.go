@@ -0,0 +1,23 @@
+package main
+
+import "fmt"
+
+// agentsMDExporter renders sections into AGENTS.md
+type agentsMDExporter struct{}
+
+// Export merges every section into AGENTS.md; AGENTS.md has no equivalent
+// of slash commands
+func (agentsMDExporter) Export(config *ClaudeConfig) *ExportResult {
+	result := &ExportResult{}
+	if len(config.Sections) > 0 {
+		result.Files = append(result.Files, ExportedFile{
+			Path:     agentsMDFile,
+			Header:   agentsMDHeader,
+			Sections: config.Sections,
+		})
+	}
+	for _, cmd := range config.Commands {
+		result.Skipped = append(result.Skipped, fmt.Sprintf("command %s: AGENTS.md has no slash commands", cmd.Name))
+	}
+	return result
+}
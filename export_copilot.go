@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// copilotInstructionsHeader starts a newly created .github/copilot-instructions.md
+const copilotInstructionsHeader = "# Copilot Instructions\n"
+
+// copilotExporter renders sections as Copilot custom instructions and
+// commands as prompt files
+type copilotExporter struct{}
+
+// Export writes unscoped sections to .github/copilot-instructions.md,
+// glob-scoped sections to .github/instructions and commands to .github/prompts
+func (copilotExporter) Export(config *ClaudeConfig) *ExportResult {
+	result := &ExportResult{}
+
+	var repository []ManagedSection
+	for _, section := range config.Sections {
+		globs, body := sectionScope(section.Body)
+		if globs == "" {
+			repository = append(repository, section)
+			continue
+		}
+		name := strings.TrimPrefix(section.Name, "copilot-")
+		result.Files = append(result.Files, ExportedFile{
+			Path:    ".github/instructions/" + name + ".instructions.md",
+			Content: fmt.Sprintf("---\napplyTo: %q\n---\n\n%s\n", globs, strings.TrimSpace(body)),
+		})
+	}
+	if len(repository) > 0 {
+		result.Files = append(result.Files, ExportedFile{
+			Path:     ".github/copilot-instructions.md",
+			Header:   copilotInstructionsHeader,
+			Sections: repository,
+		})
+	}
+
+	for _, cmd := range config.Commands {
+		description := cmd.Description
+		if description == "" {
+			description = "Claude Code command " + cmd.Name
+		}
+		body := strings.ReplaceAll(cmd.Body, "$ARGUMENTS", "${input:arguments}")
+		result.Files = append(result.Files, ExportedFile{
+			Path:    ".github/prompts/" + cmd.Name + ".prompt.md",
+			Content: "---\ndescription: " + description + "\n---\n\n" + body + "\n",
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cursorExporter renders sections and commands as .cursor/rules/*.mdc
+type cursorExporter struct{}
+
+// Export writes each section as an always-applied (or glob-scoped) rule and
+// each command as a manual rule
+func (cursorExporter) Export(config *ClaudeConfig) *ExportResult {
+	result := &ExportResult{}
+	written := map[string]bool{}
+
+	for _, section := range config.Sections {
+		name := strings.TrimPrefix(section.Name, "cursor-")
+		globs, body := sectionScope(section.Body)
+		written[name] = true
+		result.Files = append(result.Files, ExportedFile{
+			Path: ".cursor/rules/" + name + ".mdc",
+			Content: fmt.Sprintf("---\ndescription: %s\nglobs:%s\nalwaysApply: %t\n---\n\n%s\n",
+				sectionTitle(section), strings.TrimRight(" "+globs, " "), globs == "", strings.TrimSpace(body)),
+		})
+	}
+
+	for _, cmd := range config.Commands {
+		if written[cmd.Name] {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("command %s: a rule with the same name was exported from CLAUDE.md", cmd.Name))
+			continue
+		}
+		if strings.Contains(cmd.Body, "$ARGUMENTS") {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("command %s: Cursor rules take no arguments; $ARGUMENTS was left as is", cmd.Name))
+		}
+		result.Files = append(result.Files, ExportedFile{
+			Path:    ".cursor/rules/" + cmd.Name + ".mdc",
+			Content: "---\ndescription:\nglobs:\nalwaysApply: false\n---\n\n" + cmd.Body + "\n",
+		})
+	}
+
+	return result
+}
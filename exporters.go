@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Exporter renders Claude Code configuration in another assistant's file layout
+type Exporter interface {
+	Export(config *ClaudeConfig) *ExportResult
+}
+
+// ClaudeCommand is a slash command read from .claude/commands
+type ClaudeCommand struct {
+	Name        string
+	Description string
+	Body        string
+}
+
+// ClaudeConfig is the managed Claude Code content that exporters render
+type ClaudeConfig struct {
+	Sections []ManagedSection
+	Commands []ClaudeCommand
+}
+
+// ExportedFile is a file written by an Exporter. When Sections is set they are
+// merged into the file as managed sections (creating it with Header);
+// otherwise the file is replaced with Content.
+type ExportedFile struct {
+	Path     string
+	Content  string
+	Header   string
+	Sections []ManagedSection
+}
+
+// ExportResult is the output of an Exporter
+type ExportResult struct {
+	Files []ExportedFile
+	// Skipped describes Claude Code content that could not be mapped
+	Skipped []string
+}
+
+// exporters lists the supported export formats by name
+var exporters = map[string]Exporter{
+	"agentsmd": agentsMDExporter{},
+	"copilot":  copilotExporter{},
+	"cursor":   cursorExporter{},
+}
+
+// exporterNames returns the sorted names of the supported export formats
+func exporterNames() []string {
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// appliesToPattern matches the file-scope line written by the importers
+var appliesToPattern = regexp.MustCompile("(?m)^Applies when working on files matching: `([^`]+)`\\n*")
+
+// sectionScope splits the file-scope globs (if any) from a section body
+func sectionScope(body string) (string, string) {
+	m := appliesToPattern.FindStringSubmatchIndex(body)
+	if m == nil {
+		return "", body
+	}
+	return body[m[2]:m[3]], body[:m[0]] + body[m[1]:]
+}
+
+// sectionTitle returns the text of the first heading in a section body, or its name
+func sectionTitle(section ManagedSection) string {
+	for _, line := range strings.Split(section.Body, "\n") {
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			return strings.TrimSpace(m[2])
+		}
+	}
+	return section.Name
+}
+
+// loadClaudeConfig reads the managed sections of CLAUDE.md and AGENTS.md and
+// the slash commands in .claude/commands
+func loadClaudeConfig(dir string) (*ClaudeConfig, error) {
+	config := &ClaudeConfig{}
+
+	for _, name := range []string{agentsMDFile, claudeMDFile} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		for _, section := range managedSections(string(data)) {
+			if section.Name != agentsPointerSection {
+				config.Sections = mergeSections(config.Sections, []ManagedSection{section})
+			}
+		}
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, ".claude", "commands", "*.md"))
+	sort.Strings(matches)
+	for _, match := range matches {
+		data, err := os.ReadFile(match)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", match, err)
+		}
+		fields, body := parseFrontmatter(string(data))
+		config.Commands = append(config.Commands, ClaudeCommand{
+			Name:        strings.TrimSuffix(filepath.Base(match), ".md"),
+			Description: fields["description"],
+			Body:        strings.TrimSpace(body),
+		})
+	}
+
+	return config, nil
+}
+
+// runExport implements `cc-init export --format <format>`
+func runExport(args []string) error {
+	config := &Config{}
+	fs := newCommandFlagSet(findCommand("export"), config)
+	format := fs.String("format", "", tr("Target format: ")+strings.Join(exporterNames(), ", "))
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	exporter, ok := exporters[*format]
+	if !ok {
+		return fmt.Errorf("unknown export format %q (available: %s)", *format, strings.Join(exporterNames(), ", "))
+	}
+
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	if config.Remote != nil {
+		return fmt.Errorf("export does not support remote targets")
+	}
+	engine, err := NewEngine(templateFiles(), config)
+	if err != nil {
+		return err
+	}
+	err = engine.Apply(func() error { return engine.exportTo(exporter) })
+	if closeErr := engine.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// exportTo renders the target's Claude Code configuration with an Exporter
+func (e *Engine) exportTo(exporter Exporter) error {
+	source, err := loadClaudeConfig(e.config.TargetDir)
+	if err != nil {
+		return err
+	}
+	if len(source.Sections) == 0 && len(source.Commands) == 0 {
+		return fmt.Errorf("no managed CLAUDE.md sections or commands found in %s", e.config.TargetDir)
+	}
+
+	result := exporter.Export(source)
+	dirs := map[string]bool{}
+	for _, file := range result.Files {
+		for _, dir := range exportParentDirs(file.Path) {
+			if dirs[dir] {
+				continue
+			}
+			dirs[dir] = true
+			if err := e.processDirectory(filepath.Join(e.config.TargetDir, filepath.FromSlash(dir))); err != nil {
+				return err
+			}
+		}
+		if err := e.writeExportedFile(file); err != nil {
+			return err
+		}
+	}
+	for _, note := range result.Skipped {
+		e.logger.Warning("Not exported: %s", note)
+	}
+	return nil
+}
+
+// writeExportedFile writes one ExportedFile
+func (e *Engine) writeExportedFile(file ExportedFile) error {
+	targetPath := filepath.Join(e.config.TargetDir, filepath.FromSlash(file.Path))
+
+	return e.updateFile(targetPath, 0644, func(existing []byte) ([]byte, error) {
+		if file.Sections == nil {
+			return []byte(file.Content), nil
+		}
+		doc := string(existing)
+		if existing == nil {
+			doc = file.Header
+		}
+		for _, section := range file.Sections {
+			doc = upsertManagedSection(doc, section.Name, section.Body)
+		}
+		return []byte(doc), nil
+	})
+}
+
+// exportParentDirs returns the parent directories of a slash-separated
+// relative path, outermost first
+func exportParentDirs(rel string) []string {
+	var dirs []string
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// frameworkSection is the managed CLAUDE.md section written by --preset
+const frameworkSection = "framework"
+
+// FrameworkPreset is a curated bundle for a framework selectable with
+// --preset; it is layered on top of the template set
+type FrameworkPreset struct {
+	Description string
+	// Guidance is the body of the framework section of CLAUDE.md
+	Guidance string
+	Allow    []string
+	Deny     []string
+	Hooks    []string
+}
+
+// frameworkPresets are the bundles accepted by --preset
+var frameworkPresets = map[string]FrameworkPreset{
+	"nextjs": {
+		Description: "Next.js: App Router conventions, npm scripts, eslint hook",
+		Guidance: `## Next.js
+
+- Use the App Router under ` + "`app/`" + `; keep components server components unless they need state, effects or browser APIs, and mark client components with ` + "`\"use client\"`" + `.
+- Fetch data in server components or route handlers (` + "`app/**/route.ts`" + `), not in ` + "`useEffect`" + `.
+- Never expose secrets through ` + "`NEXT_PUBLIC_`" + ` variables.
+- Run ` + "`npm run lint`" + ` and ` + "`npm run build`" + ` before considering a change complete.`,
+		Allow: []string{"@node", "Bash(npx next lint:*)", "Bash(npm run build)"},
+		Deny:  []string{"@secrets"},
+		Hooks: []string{"eslint", "protect-env"},
+	},
+	"django": {
+		Description: "Django: apps, migrations and manage.py commands, ruff hook",
+		Guidance: `## Django
+
+- Keep models, views, URLs and tests inside their app; register new apps in ` + "`INSTALLED_APPS`" + `.
+- Every model change needs a migration: run ` + "`python manage.py makemigrations`" + ` and commit it with the change; never edit applied migrations.
+- Use the ORM and query parameters; do not build SQL from strings.
+- Run ` + "`python manage.py test`" + ` before considering a change complete.`,
+		Allow: []string{"@python", "Bash(python manage.py test:*)", "Bash(python manage.py makemigrations:*)", "Bash(python manage.py check:*)"},
+		Deny:  []string{"@secrets", "Bash(python manage.py flush:*)"},
+		Hooks: []string{"ruff", "protect-env"},
+	},
+	"rails": {
+		Description: "Rails: conventions, migrations and test commands",
+		Guidance: `## Rails
+
+- Follow Rails conventions: fat models, thin controllers, RESTful routes in ` + "`config/routes.rb`" + `.
+- Change the schema only through migrations generated with ` + "`bin/rails generate migration`" + `; never edit ` + "`db/schema.rb`" + ` by hand.
+- Use strong parameters in controllers and ActiveRecord queries instead of raw SQL.
+- Run ` + "`bin/rails test`" + ` (or ` + "`bundle exec rspec`" + `) before considering a change complete.`,
+		Allow: []string{"Bash(bin/rails test:*)", "Bash(bundle exec rspec:*)", "Bash(bin/rails generate migration:*)", "Bash(bundle exec rubocop:*)"},
+		Deny:  []string{"@secrets", "Read(config/master.key)", "Bash(bin/rails db:drop:*)"},
+		Hooks: []string{"protect-env"},
+	},
+	"spring": {
+		Description: "Spring Boot: layering, configuration and Maven/Gradle commands",
+		Guidance: `## Spring Boot
+
+- Keep controllers thin; put business logic in ` + "`@Service`" + ` classes and data access in repositories.
+- Use constructor injection, not field injection.
+- Read configuration through ` + "`@ConfigurationProperties`" + ` and keep secrets out of ` + "`application.yml`" + `.
+- Run ` + "`./mvnw test`" + ` or ` + "`./gradlew test`" + ` before considering a change complete.`,
+		Allow: []string{"Bash(./mvnw test:*)", "Bash(./mvnw verify:*)", "Bash(./gradlew test:*)", "Bash(./gradlew build:*)"},
+		Deny:  []string{"@secrets"},
+		Hooks: []string{"protect-env"},
+	},
+	"fastapi": {
+		Description: "FastAPI: typed endpoints, Pydantic models and pytest, ruff hook",
+		Guidance: `## FastAPI
+
+- Declare request and response bodies as Pydantic models and annotate every endpoint with types.
+- Share database sessions and settings through dependencies (` + "`Depends`" + `), not globals.
+- Use ` + "`async def`" + ` endpoints only when everything they await is async; blocking calls belong in ` + "`def`" + ` endpoints.
+- Test endpoints with ` + "`TestClient`" + ` and run ` + "`pytest`" + ` before considering a change complete.`,
+		Allow: []string{"@python"},
+		Deny:  []string{"@secrets"},
+		Hooks: []string{"ruff", "protect-env"},
+	},
+}
+
+// validateFrameworkPreset checks the --preset value
+func validateFrameworkPreset(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := frameworkPresets[name]; !ok {
+		return fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(frameworkPresetNames(), ", "))
+	}
+	return nil
+}
+
+// frameworkPresetNames returns the sorted names of the framework presets
+func frameworkPresetNames() []string {
+	names := make([]string, 0, len(frameworkPresets))
+	for name := range frameworkPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyFrameworkPreset adds the hooks of the --preset bundle to those
+// requested with --hooks
+func (e *Engine) applyFrameworkPreset() {
+	for _, name := range frameworkPresets[e.config.Preset].Hooks {
+		if !containsString(e.config.Hooks, name) {
+			e.config.Hooks = append(e.config.Hooks, name)
+		}
+	}
+}
+
+// generateFrameworkGuidance writes the guidance of the --preset bundle into
+// CLAUDE.md
+func (e *Engine) generateFrameworkGuidance() error {
+	if e.config.Preset == "" {
+		return nil
+	}
+	e.queueSections(ManagedSection{Name: frameworkSection, Body: frameworkPresets[e.config.Preset].Guidance})
+	return nil
+}
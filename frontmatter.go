@@ -0,0 +1,36 @@
+package main
+
+import "strings"
+
+// parseFrontmatter splits a markdown document into its YAML frontmatter
+// key/value pairs and the remaining body. Only flat `key: value` pairs are
+// understood; documents without frontmatter return a nil map and the full text.
+func parseFrontmatter(doc string) (map[string]string, string) {
+	doc = strings.ReplaceAll(doc, "\r\n", "\n")
+	if !strings.HasPrefix(doc, "---\n") {
+		return nil, doc
+	}
+	end := strings.Index(doc[4:], "\n---")
+	if end < 0 {
+		return nil, doc
+	}
+
+	fields := map[string]string{}
+	for _, line := range strings.Split(doc[4:4+end], "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		fields[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+
+	body := doc[4+end+len("\n---"):]
+	body = strings.TrimPrefix(body, "\n")
+	return fields, body
+}
+
+// frontmatterValue returns a top-level key from a markdown document's YAML frontmatter
+func frontmatterValue(doc, key string) string {
+	fields, _ := parseFrontmatter(doc)
+	return fields[key]
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"time"
 )
 
 // FileSystem defines the interface for file system operations
@@ -12,24 +15,89 @@ type FileSystem interface {
 	Exists(path string) bool
 	CreateDir(path string, perm os.FileMode) error
 	CreateFile(path string, content []byte, perm os.FileMode) error
+	WriteFile(path string, content []byte, perm os.FileMode) error
+	ReadFile(path string) ([]byte, error)
 	Walk(root string, fn WalkFunc) error
 	Stat(path string) (fs.FileInfo, error)
+	Chmod(path string, mode os.FileMode) error
+	Chown(path string, uid, gid int) error
+	Chtimes(path string, mtime time.Time) error
+	Remove(path string) error
 }
 
 // WalkFunc is the type of function called for each file or directory visited by Walk
 type WalkFunc func(path string, info fs.FileInfo, err error) error
 
+// walkListing walks a flat listing of slash-separated paths under root in
+// lexical order, like filepath.Walk, for file systems that can list a whole
+// tree at once
+func walkListing(root string, infos map[string]fs.FileInfo, fn WalkFunc) error {
+	children := map[string][]string{}
+	for p := range infos {
+		if p != root {
+			children[path.Dir(p)] = append(children[path.Dir(p)], p)
+		}
+	}
+
+	var walk func(p string) error
+	walk = func(p string) error {
+		if err := fn(p, infos[p], nil); err != nil {
+			if err == filepath.SkipDir && infos[p].IsDir() {
+				return nil
+			}
+			return err
+		}
+		kids := children[p]
+		sort.Strings(kids)
+		for _, kid := range kids {
+			if err := walk(kid); err != nil {
+				if err == filepath.SkipDir {
+					break
+				}
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(root)
+}
+
+// listedFileInfo is a fs.FileInfo built from a remote listing
+type listedFileInfo struct {
+	path    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i *listedFileInfo) Name() string       { return path.Base(i.path) }
+func (i *listedFileInfo) Size() int64        { return i.size }
+func (i *listedFileInfo) Mode() fs.FileMode  { return i.mode }
+func (i *listedFileInfo) ModTime() time.Time { return i.modTime }
+func (i *listedFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *listedFileInfo) Sys() interface{}   { return nil }
+
 // OSFileSystem implements FileSystem interface for the real OS file system
-type OSFileSystem struct{}
+type OSFileSystem struct {
+	policy RetryPolicy
+	logger *Logger
+}
 
 // NewOSFileSystem creates a new OSFileSystem instance
 func NewOSFileSystem() *OSFileSystem {
 	return &OSFileSystem{}
 }
 
+// SetRetryPolicy makes operations that fail with transient errors retry,
+// logging each attempt to logger
+func (fs *OSFileSystem) SetRetryPolicy(policy RetryPolicy, logger *Logger) {
+	fs.policy = policy
+	fs.logger = logger
+}
+
 // Exists checks if a file or directory exists
 func (fs *OSFileSystem) Exists(path string) bool {
-	_, err := os.Stat(path)
+	_, err := os.Stat(longPath(path))
 	return err == nil
 }
 
@@ -37,7 +105,7 @@ func (fs *OSFileSystem) Exists(path string) bool {
 func (fs *OSFileSystem) CreateDir(path string, perm os.FileMode) error {
 	if fs.Exists(path) {
 		// Check if it's actually a directory
-		info, err := os.Stat(path)
+		info, err := os.Stat(longPath(path))
 		if err != nil {
 			return fmt.Errorf("failed to stat existing path: %w", err)
 		}
@@ -46,14 +114,16 @@ func (fs *OSFileSystem) CreateDir(path string, perm os.FileMode) error {
 		}
 		return nil
 	}
-	return os.MkdirAll(path, perm)
+	return fs.retry("mkdir", path, func() error {
+		return os.MkdirAll(longPath(path), perm)
+	})
 }
 
 // CreateFile creates a file if it doesn't exist
 func (fs *OSFileSystem) CreateFile(path string, content []byte, perm os.FileMode) error {
 	if fs.Exists(path) {
 		// Check if it's actually a file
-		info, err := os.Stat(path)
+		info, err := os.Stat(longPath(path))
 		if err != nil {
 			return fmt.Errorf("failed to stat existing path: %w", err)
 		}
@@ -70,17 +140,73 @@ func (fs *OSFileSystem) CreateFile(path string, content []byte, perm os.FileMode
 	}
 
 	// Write the file
-	return os.WriteFile(path, content, perm)
+	return fs.retry("write", path, func() error {
+		return os.WriteFile(longPath(path), content, perm)
+	})
+}
+
+// WriteFile writes a file, replacing any existing content
+func (fs *OSFileSystem) WriteFile(path string, content []byte, perm os.FileMode) error {
+	if err := fs.CreateDir(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+	return fs.retry("write", path, func() error {
+		return os.WriteFile(longPath(path), content, perm)
+	})
 }
 
-// Walk walks the file tree rooted at root
+// ReadFile reads the content of a file
+func (fs *OSFileSystem) ReadFile(path string) ([]byte, error) {
+	var content []byte
+	err := fs.retry("read", path, func() (err error) {
+		content, err = os.ReadFile(longPath(path))
+		return err
+	})
+	return content, err
+}
+
+// Walk walks the file tree rooted at root. The root is not given the \\?\
+// prefix, since callers relate the paths walked to the ones they passed.
 func (fs *OSFileSystem) Walk(root string, fn WalkFunc) error {
 	return filepath.Walk(root, filepath.WalkFunc(fn))
 }
 
 // Stat returns file info for the given path
 func (fs *OSFileSystem) Stat(path string) (fs.FileInfo, error) {
-	return os.Stat(path)
+	var info os.FileInfo
+	err := fs.retry("stat", path, func() (err error) {
+		info, err = os.Stat(longPath(path))
+		return err
+	})
+	return info, err
+}
+
+// Chmod sets the exact mode of a file or directory
+func (fs *OSFileSystem) Chmod(path string, mode os.FileMode) error {
+	return fs.retry("chmod", path, func() error {
+		return os.Chmod(longPath(path), mode)
+	})
+}
+
+// Chown sets the owner of a file or directory
+func (fs *OSFileSystem) Chown(path string, uid, gid int) error {
+	return fs.retry("chown", path, func() error {
+		return os.Chown(longPath(path), uid, gid)
+	})
+}
+
+// Chtimes sets the access and modification times of a file
+func (fs *OSFileSystem) Chtimes(path string, mtime time.Time) error {
+	return fs.retry("chtimes", path, func() error {
+		return os.Chtimes(longPath(path), mtime, mtime)
+	})
+}
+
+// Remove deletes a file or an empty directory
+func (fs *OSFileSystem) Remove(path string) error {
+	return fs.retry("remove", path, func() error {
+		return os.Remove(longPath(path))
+	})
 }
 
 // DryRunFileSystem wraps another FileSystem and simulates operations without making changes
@@ -136,6 +262,24 @@ func (fs *DryRunFileSystem) CreateFile(path string, content []byte, perm os.File
 	return nil
 }
 
+// WriteFile simulates replacing a file's content
+func (fs *DryRunFileSystem) WriteFile(path string, content []byte, perm os.FileMode) error {
+	if fs.Exists(path) {
+		fs.logger.Info("Would update file: %s (mode: %v, size: %d bytes)", path, perm, len(content))
+		if existing, err := fs.wrapped.ReadFile(path); err == nil {
+			fs.logger.Diff(path, existing, content)
+		}
+		return nil
+	}
+	fs.logger.Info("Would create file: %s (mode: %v, size: %d bytes)", path, perm, len(content))
+	return nil
+}
+
+// ReadFile delegates to the wrapped filesystem
+func (fs *DryRunFileSystem) ReadFile(path string) ([]byte, error) {
+	return fs.wrapped.ReadFile(path)
+}
+
 // Walk delegates to the wrapped filesystem
 func (fs *DryRunFileSystem) Walk(root string, fn WalkFunc) error {
 	return fs.wrapped.Walk(root, fn)
@@ -146,3 +290,26 @@ func (fs *DryRunFileSystem) Stat(path string) (fs.FileInfo, error) {
 	return fs.wrapped.Stat(path)
 }
 
+// Chmod simulates a mode change
+func (fs *DryRunFileSystem) Chmod(path string, mode os.FileMode) error {
+	fs.logger.Debug("Would set mode of %s to %v", path, mode)
+	return nil
+}
+
+// Chown simulates an owner change
+func (fs *DryRunFileSystem) Chown(path string, uid, gid int) error {
+	fs.logger.Debug("Would set owner of %s to %d:%d", path, uid, gid)
+	return nil
+}
+
+// Chtimes simulates a timestamp change
+func (fs *DryRunFileSystem) Chtimes(path string, mtime time.Time) error {
+	fs.logger.Debug("Would set modification time of %s to %s", path, mtime.Format(time.RFC3339))
+	return nil
+}
+
+// Remove simulates deleting a file or an empty directory
+func (fs *DryRunFileSystem) Remove(path string) error {
+	fs.logger.Info("Would remove: %s", path)
+	return nil
+}
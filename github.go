@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// GitHubReporter runs another reporter, then publishes the run to GitHub
+// Actions: a markdown job summary and step outputs that later steps can use
+// as ${{ steps.<id>.outputs.<name> }}
+type GitHubReporter struct {
+	Reporter
+	outputPath  string
+	summaryPath string
+}
+
+// withGitHubReporter wraps reporter when running inside GitHub Actions,
+// which announces itself through GITHUB_ACTIONS and the file paths in
+// GITHUB_OUTPUT and GITHUB_STEP_SUMMARY
+func withGitHubReporter(reporter Reporter, getenv func(string) string) Reporter {
+	if getenv("GITHUB_ACTIONS") != "true" {
+		return reporter
+	}
+	outputPath, summaryPath := getenv("GITHUB_OUTPUT"), getenv("GITHUB_STEP_SUMMARY")
+	if outputPath == "" && summaryPath == "" {
+		return reporter
+	}
+	return &GitHubReporter{Reporter: reporter, outputPath: outputPath, summaryPath: summaryPath}
+}
+
+// Report runs the wrapped reporter, then appends to the step summary and
+// outputs files
+func (r *GitHubReporter) Report(report *Report) error {
+	if err := r.Reporter.Report(report); err != nil {
+		return err
+	}
+
+	if r.summaryPath != "" {
+		var b strings.Builder
+		if err := (&MarkdownReporter{writer: &b}).Report(report); err != nil {
+			return err
+		}
+		if err := appendFile(r.summaryPath, b.String()+"\n"); err != nil {
+			return fmt.Errorf("failed to write GitHub step summary: %w", err)
+		}
+	}
+
+	if r.outputPath != "" {
+		stats := report.Stats
+		outputs := fmt.Sprintf("files_created=%d\nfiles_updated=%d\nfiles_skipped=%d\ndirs_created=%d\nerrors=%d\ndrift_detected=%t\n",
+			stats.FilesCreated, stats.FilesUpdated, stats.FilesSkipped, stats.DirsCreated, len(stats.Errors), changesPending(stats))
+		if err := appendFile(r.outputPath, outputs); err != nil {
+			return fmt.Errorf("failed to write GitHub outputs: %w", err)
+		}
+	}
+	return nil
+}
+
+// appendFile appends text to a file; GitHub shares these files between the
+// steps of a job, so they must never be truncated
+func appendFile(path, text string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
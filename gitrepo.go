@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// findGitRoot returns the root of the git work tree containing dir: the
+// nearest ancestor holding .git, which is a file in linked worktrees and
+// submodules. It returns "" outside a repository.
+func findGitRoot(dir string) string {
+	for {
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// defaultTargetDir is the target when -t is not given: the root of the
+// enclosing git work tree, so running from a subdirectory does not scatter
+// configuration, else the current directory
+func defaultTargetDir(config *Config) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if config.NoGitRoot {
+		return cwd, nil
+	}
+	if root := findGitRoot(cwd); root != "" && root != cwd {
+		config.AtGitRoot = true
+		return root, nil
+	}
+	return cwd, nil
+}
+
+// gitDirOf returns the git directory of the work tree at root, following the
+// "gitdir:" indirection of a .git file, or "" when root has none
+func gitDirOf(root string) string {
+	dotGit := filepath.Join(root, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return ""
+	}
+	if info.IsDir() {
+		return dotGit
+	}
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return ""
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	dir = filepath.FromSlash(strings.TrimSpace(dir))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	return dir
+}
+
+// mainWorkTree returns the main work tree of a linked worktree (git worktree
+// add) at root, or "" when root is not one. Submodules also use a .git file,
+// but their git directory has no commondir and they are repositories of
+// their own.
+func mainWorkTree(root string) string {
+	gitDir := gitDirOf(root)
+	if gitDir == "" {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return ""
+	}
+	common := filepath.FromSlash(strings.TrimSpace(string(data)))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(gitDir, common)
+	}
+	common = filepath.Clean(common)
+	if filepath.Base(common) != ".git" {
+		// A bare repository has no main work tree
+		return ""
+	}
+	return filepath.Dir(common)
+}
+
+// insideGitDir reports whether p lies within a .git directory, where cc-init
+// must never write
+func insideGitDir(p string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(filepath.Clean(p)), "/") {
+		if part == ".git" {
+			return true
+		}
+	}
+	return false
+}
+
+// submodulePaths returns the checked-out submodules listed in the
+// .gitmodules of root, relative to root
+func submodulePaths(root string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(root, ".gitmodules"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || strings.TrimSpace(key) != "path" {
+			continue
+		}
+		p := filepath.FromSlash(strings.TrimSpace(value))
+		if !filepath.IsLocal(p) || gitDirOf(filepath.Join(root, p)) == "" {
+			// Not initialized: the directory is empty
+			continue
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+// sharedLock reads the lockfile of the target. A linked worktree without one
+// reads the lockfile of its main work tree instead, so it follows the set
+// the repository was initialized with; the worktree still writes its own.
+func (e *Engine) sharedLock() (*Lock, error) {
+	lock, err := e.readLock()
+	if err == nil || e.config.Remote != nil || !errors.Is(err, fs.ErrNotExist) {
+		return lock, err
+	}
+	main := mainWorkTree(e.config.TargetDir)
+	if main == "" {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(main, filepath.FromSlash(lockFile)))
+	if err != nil {
+		return nil, err
+	}
+	e.logger.Debug("Using the lockfile of the main work tree %s", main)
+	lock = &Lock{}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("invalid %s in %s: %w", lockFile, main, err)
+	}
+	return lock, nil
+}
+
+// runSubmodules initializes every checked-out submodule of the target with
+// the same configuration, for --include-submodules
+func runSubmodules(templates fs.FS, config *Config) error {
+	paths, err := submodulePaths(config.TargetDir)
+	if err != nil {
+		return fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+	for _, p := range paths {
+		sub := *config
+		sub.TargetDir = filepath.Join(config.TargetDir, p)
+		sub.AtGitRoot = false
+		engine, err := NewEngine(templates, &sub)
+		if err != nil {
+			return fmt.Errorf("submodule %s: %w", p, err)
+		}
+		engine.logger.Info("Initializing submodule %s", p)
+		err = engine.Run()
+		if closeErr := engine.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("submodule %s: %w", p, err)
+		}
+	}
+	return nil
+}
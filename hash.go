@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runHash implements `cc-init hash [source]`: it prints the digest of the
+// embedded templates, or of a template directory given as a local path or a
+// remote target, in the form recorded in lockfiles
+func runHash(args []string) error {
+	cmd := findCommand("hash")
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	set := fs.String("set", "", tr("Embedded template set to hash: ")+strings.Join(templateSetNames(), ", "))
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %v", positional[1:])
+	}
+
+	var digest string
+	if len(positional) == 0 {
+		pack, openErr := openTemplateSet(*set)
+		if openErr != nil {
+			return openErr
+		}
+		digest, err = NewTemplateManager(pack, ".").Digest()
+	} else if *set != "" {
+		return fmt.Errorf("--set selects embedded templates and cannot be combined with a source")
+	} else {
+		digest, err = hashSource(positional[0])
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(digest)
+	return nil
+}
+
+// hashSource returns the digest of the files below a local directory or a
+// remote target, computed like TemplateManager.Digest
+func hashSource(source string) (string, error) {
+	var fsys FileSystem = NewOSFileSystem()
+	root := filepath.Clean(source)
+	remote, isRemote, err := parseRemoteTarget(source)
+	if err != nil {
+		return "", err
+	}
+	if isRemote {
+		rfs, err := openRemote(remote)
+		if err != nil {
+			return "", err
+		}
+		defer rfs.Close()
+		fsys = rfs
+		root = remote.Path
+	} else if info, err := os.Stat(root); err != nil {
+		return "", err
+	} else if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", root)
+	}
+
+	prefix := strings.TrimSuffix(filepath.ToSlash(root), "/") + "/"
+	var paths []string
+	err = fsys.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		paths = append(paths, strings.TrimPrefix(filepath.ToSlash(p), prefix))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return digestFiles(paths, func(rel string) ([]byte, error) {
+		if isRemote {
+			return fsys.ReadFile(prefix + rel)
+		}
+		return fsys.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+	})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// HeaderRule in .cc-init.yaml prepends a license or ownership header to the
+// files cc-init writes whose paths match
+type HeaderRule struct {
+	// Paths are patterns relative to the target, as for --chmod
+	Paths []string `yaml:"paths" json:"paths,omitempty"`
+	// Text is a Go template with .Year and .Owner, written as a comment
+	Text string `yaml:"text" json:"text,omitempty"`
+}
+
+// headerData is what header templates see
+type headerData struct {
+	Year  int
+	Owner string
+}
+
+// lineComments maps extensions to their line comment marker; Markdown gets
+// an HTML comment instead, and other files, such as JSON, get no header
+var lineComments = map[string]string{
+	".sh":   "#",
+	".bash": "#",
+	".py":   "#",
+	".rb":   "#",
+	".yml":  "#",
+	".yaml": "#",
+	".toml": "#",
+	".js":   "//",
+	".ts":   "//",
+	".go":   "//",
+}
+
+// headerRule returns the last rule of .cc-init.yaml matching targetPath
+func (e *Engine) headerRule(targetPath string) (*HeaderRule, error) {
+	if !e.headersRead {
+		e.headersRead = true
+		project, err := e.loadProjectConfig()
+		if err != nil {
+			return nil, err
+		}
+		e.headers, e.headerOwner = project.Headers, project.Owner
+	}
+	rel := filepath.ToSlash(e.formatPath(targetPath))
+	var match *HeaderRule
+	for i, rule := range e.headers {
+		for _, pattern := range rule.Paths {
+			if matchPathPattern(pattern, rel) {
+				match = &e.headers[i]
+			}
+		}
+	}
+	return match, nil
+}
+
+// addHeader prepends the header of the matching rule to content, after a
+// shebang line or Markdown frontmatter. Content that already carries the
+// header is returned as is, so rewriting a file does not stack headers.
+func (e *Engine) addHeader(targetPath string, content []byte) ([]byte, error) {
+	rule, err := e.headerRule(targetPath)
+	if err != nil || rule == nil {
+		return content, err
+	}
+	ext := path.Ext(targetPath)
+	marker, ok := lineComments[ext]
+	if !ok && ext != ".md" {
+		return content, nil
+	}
+
+	tmpl, err := template.New("header").Option("missingkey=error").Parse(rule.Text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid header in %s: %w", projectConfigFile, err)
+	}
+	var text bytes.Buffer
+	if err := tmpl.Execute(&text, headerData{Year: e.renderTime().Year(), Owner: e.headerOwner}); err != nil {
+		return nil, fmt.Errorf("invalid header in %s: %w", projectConfigFile, err)
+	}
+	var header strings.Builder
+	lines := strings.Split(strings.TrimRight(text.String(), "\n"), "\n")
+	if ext == ".md" {
+		header.WriteString("<!--\n" + strings.Join(lines, "\n") + "\n-->\n\n")
+	} else {
+		for _, line := range lines {
+			header.WriteString(strings.TrimRight(marker+" "+line, " ") + "\n")
+		}
+	}
+	if bytes.Contains(content, []byte(header.String())) {
+		return content, nil
+	}
+
+	split := 0
+	if bytes.HasPrefix(content, []byte("#!")) {
+		if i := bytes.IndexByte(content, '\n'); i >= 0 {
+			split = i + 1
+		} else {
+			content, split = append(content, '\n'), len(content)+1
+		}
+	} else if ext == ".md" && bytes.HasPrefix(content, []byte("---\n")) {
+		if i := bytes.Index(content[4:], []byte("\n---\n")); i >= 0 {
+			split = 4 + i + 5
+		}
+	}
+	out := append([]byte{}, content[:split]...)
+	out = append(out, header.String()...)
+	return append(out, content[split:]...), nil
+}
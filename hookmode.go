@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// runHookMode implements `cc-init hook-mode`, a pre-commit or husky hook
+// that passes silently while the target matches its lockfile and fails with
+// a short drift summary otherwise
+func runHookMode(args []string) error {
+	config := &Config{}
+	fs := newCommandFlagSet(findCommand("hook-mode"), config)
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %v", positional)
+	}
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	engine, err := NewEngine(templateFiles(), config)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	drift, err := engine.checkLock()
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s not found; run cc-init and commit the result", lockFile)
+	}
+	if err != nil {
+		return err
+	}
+	if len(drift) == 0 {
+		return nil
+	}
+
+	logger := engine.logger
+	logger.Error(".claude configuration drifted from the cc-init %s templates:", version)
+	for _, d := range drift {
+		switch {
+		case d.Kind == DriftOutdated:
+			logger.Info("%s: written by a different cc-init version", d.Path)
+		case d.Added+d.Removed > 0:
+			logger.Info("%s: %s (+%d -%d against the template)", d.Path, tr(d.Kind), d.Added, d.Removed)
+		default:
+			logger.Info("%s: %s", d.Path, tr(d.Kind))
+		}
+	}
+	logger.Info("Run cc-init and commit the result, including %s", lockFile)
+	return fmt.Errorf("found %d drifted %s", len(drift), pluralize("path", len(drift)))
+}
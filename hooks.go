@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// hooksDir is where hook scripts are installed, relative to .claude
+const hooksDir = "hooks"
+
+// Platforms of hook scripts for --hook-platform. Every preset ships a bash
+// script and a PowerShell script of the same name with a .ps1 extension.
+const (
+	HookPlatformAuto    = "auto"
+	HookPlatformUnix    = "unix"
+	HookPlatformWindows = "windows"
+	HookPlatformBoth    = "both"
+)
+
+// HookWiring connects a hook script to a Claude Code hook event
+type HookWiring struct {
+	Event   string
+	Matcher string
+	Args    string
+}
+
+// HookPreset is a ready-made hook shipped with cc-init
+type HookPreset struct {
+	Description string
+	Script      string
+	Wiring      []HookWiring
+	// Languages select the preset automatically when the target uses one of
+	// them, as detected by AnalyzeProject
+	Languages []string
+}
+
+// hookCatalog lists the hook presets selectable with --hooks
+var hookCatalog = map[string]HookPreset{
+	"gofmt": {
+		Description: "Run gofmt on Go files after they are edited",
+		Script:      "gofmt.sh",
+		Wiring: []HookWiring{
+			{Event: "PostToolUse", Matcher: "Edit|MultiEdit|Write"},
+		},
+		Languages: []string{"Go"},
+	},
+	"go-test": {
+		Description: "Run the tests of the Go package of an edited file",
+		Script:      "go-test.sh",
+		Wiring: []HookWiring{
+			{Event: "PostToolUse", Matcher: "Edit|MultiEdit|Write"},
+		},
+		Languages: []string{"Go"},
+	},
+	"eslint": {
+		Description: "Run eslint --fix on JavaScript and TypeScript files after they are edited",
+		Script:      "eslint.sh",
+		Wiring: []HookWiring{
+			{Event: "PostToolUse", Matcher: "Edit|MultiEdit|Write"},
+		},
+		Languages: []string{"JavaScript", "TypeScript"},
+	},
+	"ruff": {
+		Description: "Run ruff format and ruff check --fix on Python files after they are edited",
+		Script:      "ruff.sh",
+		Wiring: []HookWiring{
+			{Event: "PostToolUse", Matcher: "Edit|MultiEdit|Write"},
+		},
+		Languages: []string{"Python"},
+	},
+	"protect-env": {
+		Description: "Block reads and writes of .env files",
+		Script:      "protect-env.sh",
+		Wiring: []HookWiring{
+			{Event: "PreToolUse", Matcher: "Read|Edit|MultiEdit|Write"},
+		},
+	},
+	"notify-long-bash": {
+		Description: "Send a desktop notification when a Bash command runs long",
+		Script:      "notify-long-bash.sh",
+		Wiring: []HookWiring{
+			{Event: "PreToolUse", Matcher: "Bash", Args: "start"},
+			{Event: "PostToolUse", Matcher: "Bash", Args: "stop"},
+		},
+	},
+}
+
+// hookCommand returns the settings.json command that runs an installed hook
+// script; PowerShell scripts run through powershell
+func hookCommand(script, args string) string {
+	command := `"$CLAUDE_PROJECT_DIR"/.claude/` + path.Join(hooksDir, script)
+	if path.Ext(script) == ".ps1" {
+		command = "powershell -NoProfile -ExecutionPolicy Bypass -File " + command
+	}
+	if args != "" {
+		command += " " + args
+	}
+	return command
+}
+
+// AddHook registers a command hook for event and matcher, skipping duplicates
+func (s Settings) AddHook(event, matcher, command string) {
+	hooks, _ := s["hooks"].(map[string]interface{})
+	if hooks == nil {
+		hooks = map[string]interface{}{}
+		s["hooks"] = hooks
+	}
+
+	entry := map[string]interface{}{
+		"type":    "command",
+		"command": command,
+	}
+
+	groups, _ := hooks[event].([]interface{})
+	for _, g := range groups {
+		group, ok := g.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if m, _ := group["matcher"].(string); m != matcher {
+			continue
+		}
+		list, _ := group["hooks"].([]interface{})
+		for _, h := range list {
+			if existing, ok := h.(map[string]interface{}); ok && existing["command"] == command {
+				return
+			}
+		}
+		group["hooks"] = append(list, entry)
+		return
+	}
+
+	hooks[event] = append(groups, map[string]interface{}{
+		"matcher": matcher,
+		"hooks":   []interface{}{entry},
+	})
+}
+
+// validateHookPlatform checks the --hook-platform value; empty means auto
+func validateHookPlatform(platform string) error {
+	switch platform {
+	case "", HookPlatformAuto, HookPlatformUnix, HookPlatformWindows, HookPlatformBoth:
+		return nil
+	default:
+		return fmt.Errorf("unknown --hook-platform %q (expected %s, %s, %s or %s)", platform, HookPlatformAuto, HookPlatformUnix, HookPlatformWindows, HookPlatformBoth)
+	}
+}
+
+// powershellScript returns the name of the PowerShell flavor of a hook script
+func powershellScript(script string) string {
+	return strings.TrimSuffix(script, path.Ext(script)) + ".ps1"
+}
+
+// targetPlatform returns the platform whose hook scripts settings.json runs:
+// the one given by --hook-platform, else windows when cc-init runs on a
+// local Windows machine, else unix
+func (e *Engine) targetPlatform() string {
+	switch e.config.HookPlatform {
+	case HookPlatformUnix, HookPlatformWindows:
+		return e.config.HookPlatform
+	}
+	if runtime.GOOS == "windows" && e.config.Remote == nil {
+		return HookPlatformWindows
+	}
+	return HookPlatformUnix
+}
+
+// hookScript returns the flavor of a preset script settings.json runs
+func (e *Engine) hookScript(script string) string {
+	if e.targetPlatform() == HookPlatformWindows {
+		return powershellScript(script)
+	}
+	return script
+}
+
+// hookScripts returns the flavors of a preset script to install: both with
+// --hook-platform both, else the one settings.json runs
+func (e *Engine) hookScripts(script string) []string {
+	if e.config.HookPlatform == HookPlatformBoth {
+		return []string{script, powershellScript(script)}
+	}
+	return []string{e.hookScript(script)}
+}
+
+// validateHookPresets checks that every requested hook preset exists
+func validateHookPresets(names []string) error {
+	for _, name := range names {
+		if _, ok := hookCatalog[name]; !ok {
+			return fmt.Errorf("unknown hook preset %q (available: %s)", name, strings.Join(hookPresetNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// hookPresetNames returns the sorted names of the hook presets
+func hookPresetNames() []string {
+	names := make([]string, 0, len(hookCatalog))
+	for name := range hookCatalog {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// autodetectHooks adds the hook presets matching the languages of the target
+// when no template set was chosen, unless --no-autodetect is given
+func (e *Engine) autodetectHooks() {
+	if e.config.NoAutodetect || e.config.TemplateSet != "" || e.config.TemplateDir != "" || e.config.Remote != nil {
+		return
+	}
+	if e.project == nil {
+		e.project = AnalyzeProject(e.config.TargetDir)
+	}
+	for _, name := range hookPresetNames() {
+		if containsString(e.config.Hooks, name) {
+			continue
+		}
+		for _, language := range hookCatalog[name].Languages {
+			if containsString(e.project.Languages, language) {
+				e.logger.Info("Detected %s; adding the %s hook", language, name)
+				e.config.Hooks = append(e.config.Hooks, name)
+				break
+			}
+		}
+	}
+}
+
+// applyHookPresets wires the selected hook presets into settings
+func (e *Engine) applyHookPresets(settings Settings, names []string) {
+	for _, name := range names {
+		preset := hookCatalog[name]
+		for _, w := range preset.Wiring {
+			settings.AddHook(w.Event, w.Matcher, hookCommand(e.hookScript(preset.Script), w.Args))
+		}
+	}
+}
+
+// generateHookScripts installs the scripts of the selected hook presets
+func (e *Engine) generateHookScripts() error {
+	if len(e.config.Hooks) == 0 {
+		return nil
+	}
+
+	dir := filepath.Join(e.config.TargetDir, ".claude", hooksDir)
+	if err := e.processDirectory(dir); err != nil {
+		return err
+	}
+
+	for _, name := range e.config.Hooks {
+		for _, script := range e.hookScripts(hookCatalog[name].Script) {
+			content, err := presetFS.ReadFile(path.Join("presets", hooksDir, script))
+			if err != nil {
+				return fmt.Errorf("failed to read hook preset %s: %w", name, err)
+			}
+			if err := e.installFile(filepath.Join(dir, script), content, 0755); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// supportsHyperlinks reports whether OSC 8 hyperlinks can be written to w.
+// FORCE_HYPERLINK=1 or 0 overrides detection; otherwise w must be a terminal
+// known to render them.
+func supportsHyperlinks(getenv func(string) string, w io.Writer) bool {
+	if force := getenv("FORCE_HYPERLINK"); force != "" {
+		return force != "0"
+	}
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	if getenv("CI") != "" || getenv("TERM") == "dumb" {
+		return false
+	}
+
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper":
+		return true
+	}
+	if getenv("WT_SESSION") != "" || getenv("KITTY_WINDOW_ID") != "" || getenv("DOMTERM") != "" {
+		return true
+	}
+	// GNOME Terminal and other VTE-based terminals since 0.50
+	if version, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && version >= 5000 {
+		return true
+	}
+	return false
+}
+
+// fileURI returns the file:// URI of an absolute path
+func fileURI(path string) string {
+	slashed := filepath.ToSlash(path)
+	if !strings.HasPrefix(slashed, "/") {
+		// Windows drive paths become file:///C:/...
+		slashed = "/" + slashed
+	}
+	return (&url.URL{Scheme: "file", Path: slashed}).String()
+}
+
+// hyperlink wraps text in an OSC 8 escape sequence pointing at uri
+func hyperlink(uri, text string) string {
+	return "\033]8;;" + uri + "\033\\" + text + "\033]8;;\033\\"
+}
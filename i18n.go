@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// Supported output locales
+const (
+	LocaleEN   = "en"
+	LocaleZhCN = "zh-CN"
+)
+
+// catalogs maps a locale to its translations, keyed by the English message.
+// English is the source language and has no catalog.
+var catalogs = map[string]map[string]string{
+	LocaleZhCN: zhCNMessages,
+}
+
+// currentLocale is the locale used for user-facing messages
+var currentLocale = detectLocale(os.Getenv)
+
+// detectLocale picks the output locale from CC_INIT_LANG, then the standard
+// LC_ALL, LC_MESSAGES and LANG variables
+func detectLocale(getenv func(string) string) string {
+	for _, key := range []string{"CC_INIT_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := getenv(key); value != "" {
+			return normalizeLocale(value)
+		}
+	}
+	return LocaleEN
+}
+
+// normalizeLocale maps a POSIX locale such as "zh_CN.UTF-8" to a supported
+// locale, falling back to English
+func normalizeLocale(value string) string {
+	value, _, _ = strings.Cut(value, ".")
+	value, _, _ = strings.Cut(value, "@")
+	value = strings.ToLower(strings.ReplaceAll(value, "_", "-"))
+	if value == "zh" || strings.HasPrefix(value, "zh-") {
+		return LocaleZhCN
+	}
+	return LocaleEN
+}
+
+// tr returns the translation of an English message (usually a format string)
+// in the current locale, or the message itself if it has none
+func tr(message string) string {
+	if translated, ok := catalogs[currentLocale][message]; ok {
+		return translated
+	}
+	return message
+}
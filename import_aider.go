@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Aider configuration files, relative to the project root
+const (
+	aiderConfigFile      = ".aider.conf.yml"
+	aiderConventionsFile = "CONVENTIONS.md"
+)
+
+// aiderModelAliases maps aider's Anthropic model aliases to Claude Code model aliases
+var aiderModelAliases = map[string]string{
+	"sonnet": "sonnet",
+	"opus":   "opus",
+	"haiku":  "haiku",
+}
+
+// aiderImporter converts .aider.conf.yml and aider conventions files
+type aiderImporter struct{}
+
+// Detect finds the aider config file and CONVENTIONS.md
+func (aiderImporter) Detect(dir string) []string {
+	var found []string
+	for _, name := range []string{aiderConfigFile, aiderConventionsFile} {
+		if fileExists(filepath.Join(dir, name)) {
+			found = append(found, name)
+		}
+	}
+	return found
+}
+
+// Import maps read-only context files to CLAUDE.md sections, test and lint
+// commands to CLAUDE.md and Bash permissions, and Anthropic models to the
+// model setting. Other options are reported as not mapped.
+func (aiderImporter) Import(dir string) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	config := map[string][]string{}
+	if data, err := os.ReadFile(filepath.Join(dir, aiderConfigFile)); err == nil {
+		config = parseAiderConfig(string(data))
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", aiderConfigFile, err)
+	}
+
+	// aider users conventionally load CONVENTIONS.md with --read
+	reads := config["read"]
+	if fileExists(filepath.Join(dir, aiderConventionsFile)) && !containsString(reads, aiderConventionsFile) {
+		reads = append([]string{aiderConventionsFile}, reads...)
+	}
+
+	var references []string
+	for _, rel := range reads {
+		if !strings.EqualFold(filepath.Ext(rel), ".md") {
+			references = append(references, "@"+filepath.ToSlash(rel))
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("read file %s could not be loaded: %v", rel, err))
+			continue
+		}
+		body := strings.TrimSpace(string(data))
+		if body == "" {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s is empty", rel))
+			continue
+		}
+		name := slugify(filepath.Base(rel))
+		result.Sections = append(result.Sections, ManagedSection{
+			Name: "aider-" + name,
+			Body: fmt.Sprintf("## Aider Conventions: %s\n\n%s\n", name, demoteHeadings(body, 3)),
+		})
+	}
+	if len(references) > 0 {
+		result.Sections = append(result.Sections, ManagedSection{
+			Name: "aider-context",
+			Body: "## Aider Context Files\n\nAlways read these files before making changes:\n\n" + strings.Join(references, "\n") + "\n",
+		})
+	}
+
+	var commands []string
+	for _, key := range []string{"test-cmd", "lint-cmd"} {
+		for _, cmd := range config[key] {
+			commands = append(commands, fmt.Sprintf("- %s: `%s`", strings.TrimSuffix(key, "-cmd"), cmd))
+			result.Allow = append(result.Allow, "Bash("+cmd+")")
+		}
+	}
+	if len(commands) > 0 {
+		result.Sections = append(result.Sections, ManagedSection{
+			Name: "aider-commands",
+			Body: "## Aider Commands\n\nRun these after making changes:\n\n" + strings.Join(commands, "\n") + "\n",
+		})
+	}
+
+	if models := config["model"]; len(models) > 0 {
+		if model := claudeModelFromAider(models[0]); model != "" {
+			result.Settings = map[string]interface{}{"model": model}
+		} else {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: model %q is not an Anthropic model", aiderConfigFile, models[0]))
+		}
+	}
+
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch key {
+		case "read", "test-cmd", "lint-cmd", "model":
+		case "file":
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %q files are added per session; mention them with @path instead", aiderConfigFile, key))
+		default:
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: option %q has no Claude Code equivalent", aiderConfigFile, key))
+		}
+	}
+
+	return result, nil
+}
+
+// claudeModelFromAider converts an aider model name to a Claude Code model
+// setting, returning "" for non-Anthropic models
+func claudeModelFromAider(model string) string {
+	if alias, ok := aiderModelAliases[model]; ok {
+		return alias
+	}
+	model = strings.TrimPrefix(model, "anthropic/")
+	if strings.HasPrefix(model, "claude-") {
+		return model
+	}
+	return ""
+}
+
+// parseAiderConfig reads the subset of YAML used by .aider.conf.yml: top-level
+// scalars, flow lists ([a, b]) and block lists ("- a"). Every key maps to its
+// values so single and repeated options are handled alike.
+func parseAiderConfig(doc string) map[string][]string {
+	config := map[string][]string{}
+	var current string
+	for _, line := range strings.Split(strings.ReplaceAll(doc, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "- ") && current != "" {
+			config[current] = append(config[current], unquoteYAML(trimmed[2:]))
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok || line != trimmed {
+			continue
+		}
+		current = strings.TrimSpace(key)
+		value = strings.TrimSpace(stripYAMLComment(value))
+		switch {
+		case value == "":
+			config[current] = nil
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			config[current] = nil
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = unquoteYAML(item); item != "" {
+					config[current] = append(config[current], item)
+				}
+			}
+		default:
+			config[current] = []string{unquoteYAML(value)}
+		}
+	}
+	return config
+}
+
+// stripYAMLComment removes a trailing " # comment" outside of quotes
+func stripYAMLComment(value string) string {
+	quote := rune(0)
+	for i, r := range value {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t'):
+			return value[:i]
+		}
+	}
+	return value
+}
+
+// unquoteYAML trims whitespace and matching quotes from a scalar
+func unquoteYAML(value string) string {
+	value = strings.TrimSpace(stripYAMLComment(value))
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
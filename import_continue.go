@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// continueConfigFile is the Continue.dev project configuration, relative to the project root
+const continueConfigFile = ".continue/config.json"
+
+// continueInputPattern matches the {{{ input }}} placeholder in Continue prompts
+var continueInputPattern = regexp.MustCompile(`\{\{\{\s*input\s*\}\}\}`)
+
+// continueProviderServers maps Continue context providers to equivalent MCP servers from mcpCatalog
+var continueProviderServers = map[string]string{
+	"docs": "context7",
+	"url":  "fetch",
+}
+
+// continueBuiltinProviders are context providers whose context Claude Code gathers itself
+var continueBuiltinProviders = map[string]bool{
+	"code": true, "codebase": true, "currentFile": true, "diff": true, "file": true,
+	"folder": true, "open": true, "os": true, "problems": true, "search": true,
+	"terminal": true, "tree": true,
+}
+
+// continueConfig is the subset of .continue/config.json that cc-init converts
+type continueConfig struct {
+	CustomCommands []struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Prompt      string `json:"prompt"`
+	} `json:"customCommands"`
+	SlashCommands []struct {
+		Name string `json:"name"`
+	} `json:"slashCommands"`
+	ContextProviders []struct {
+		Name string `json:"name"`
+	} `json:"contextProviders"`
+	Experimental struct {
+		MCPServers []struct {
+			Name      string `json:"name"`
+			Transport struct {
+				Type    string            `json:"type"`
+				Command string            `json:"command"`
+				Args    []string          `json:"args"`
+				Env     map[string]string `json:"env"`
+				URL     string            `json:"url"`
+			} `json:"transport"`
+		} `json:"modelContextProtocolServers"`
+	} `json:"experimental"`
+	Models []json.RawMessage `json:"models"`
+}
+
+// continueImporter converts .continue/config.json
+type continueImporter struct{}
+
+// Detect finds the Continue project configuration
+func (continueImporter) Detect(dir string) []string {
+	if fileExists(filepath.Join(dir, filepath.FromSlash(continueConfigFile))) {
+		return []string{continueConfigFile}
+	}
+	return nil
+}
+
+// Import maps custom commands to slash commands and MCP servers and known
+// context providers to .mcp.json entries
+func (continueImporter) Import(dir string) (*ImportResult, error) {
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(continueConfigFile)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", continueConfigFile, err)
+	}
+	var config continueConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", continueConfigFile, err)
+	}
+
+	result := &ImportResult{MCPServers: map[string]map[string]interface{}{}}
+
+	for _, cmd := range config.CustomCommands {
+		name := slugify(cmd.Name)
+		if name == "" || strings.TrimSpace(cmd.Prompt) == "" {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("custom command %q has no name or prompt", cmd.Name))
+			continue
+		}
+		prompt := strings.TrimSpace(cmd.Prompt)
+		if continueInputPattern.MatchString(prompt) {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("custom command %s: {{{ input }}} was replaced with $ARGUMENTS", name))
+			prompt = continueInputPattern.ReplaceAllString(prompt, "$$ARGUMENTS")
+		}
+		description := cmd.Description
+		if description == "" {
+			description = "Imported Continue command " + name
+		}
+		result.Commands = append(result.Commands, ImportedCommand{
+			Name:    name,
+			Content: "---\ndescription: " + description + "\n---\n\n" + prompt + "\n",
+		})
+	}
+
+	for _, cmd := range config.SlashCommands {
+		result.Skipped = append(result.Skipped, fmt.Sprintf("built-in slash command %q has no Claude Code equivalent", cmd.Name))
+	}
+
+	for _, provider := range config.ContextProviders {
+		switch server, mapped := continueProviderServers[provider.Name]; {
+		case mapped:
+			result.MCPServers[server] = mcpCatalog[server]
+		case continueBuiltinProviders[provider.Name]:
+			result.Skipped = append(result.Skipped, fmt.Sprintf("context provider %q is built into Claude Code; use @path or ask directly", provider.Name))
+		default:
+			result.Skipped = append(result.Skipped, fmt.Sprintf("context provider %q has no Claude Code equivalent", provider.Name))
+		}
+	}
+
+	for i, server := range config.Experimental.MCPServers {
+		transport := server.Transport
+		name := slugify(server.Name)
+		if name == "" && len(transport.Args) > 0 {
+			name = slugify(filepath.Base(transport.Args[len(transport.Args)-1]))
+		}
+		if name == "" {
+			name = fmt.Sprintf("continue-%d", i+1)
+		}
+
+		switch transport.Type {
+		case "stdio":
+			entry := map[string]interface{}{"type": "stdio", "command": transport.Command}
+			if len(transport.Args) > 0 {
+				args := make([]interface{}, len(transport.Args))
+				for j, arg := range transport.Args {
+					args[j] = arg
+				}
+				entry["args"] = args
+			}
+			if len(transport.Env) > 0 {
+				env := map[string]interface{}{}
+				for key, value := range transport.Env {
+					env[key] = value
+				}
+				entry["env"] = env
+			}
+			result.MCPServers[name] = entry
+		case "sse", "http", "streamable-http":
+			serverType := transport.Type
+			if serverType == "streamable-http" {
+				serverType = "http"
+			}
+			result.MCPServers[name] = map[string]interface{}{"type": serverType, "url": transport.URL}
+		default:
+			result.Skipped = append(result.Skipped, fmt.Sprintf("MCP server %s: transport %q is not supported", name, transport.Type))
+		}
+	}
+
+	if len(config.Models) > 0 {
+		result.Skipped = append(result.Skipped, "model configuration is managed by Claude Code and was not imported")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// copilotInputPattern matches ${input:name} and ${input:name:placeholder} prompt variables
+var copilotInputPattern = regexp.MustCompile(`\$\{input:[^}]+\}`)
+
+// copilotImporter converts GitHub Copilot custom instructions and prompt files
+type copilotImporter struct{}
+
+// Detect finds Copilot instruction and prompt files under .github
+func (copilotImporter) Detect(dir string) []string {
+	var found []string
+	if fileExists(filepath.Join(dir, ".github", "copilot-instructions.md")) {
+		found = append(found, ".github/copilot-instructions.md")
+	}
+	for _, pattern := range []string{"instructions/*.instructions.md", "prompts/*.prompt.md"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, ".github", filepath.FromSlash(pattern)))
+		sort.Strings(matches)
+		for _, match := range matches {
+			rel, _ := filepath.Rel(dir, match)
+			found = append(found, filepath.ToSlash(rel))
+		}
+	}
+	return found
+}
+
+// Import maps repository and path-specific instructions to CLAUDE.md sections
+// and prompt files to slash commands
+func (c copilotImporter) Import(dir string) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	for _, rel := range c.Detect(dir) {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		fields, body := parseFrontmatter(string(data))
+		body = strings.TrimSpace(body)
+		if body == "" {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s is empty", rel))
+			continue
+		}
+
+		base := filepath.Base(rel)
+		switch {
+		case base == "copilot-instructions.md":
+			result.Sections = append(result.Sections, ManagedSection{
+				Name: "copilot-instructions",
+				Body: "## GitHub Copilot Instructions\n\n" + demoteHeadings(body, 3) + "\n",
+			})
+
+		case strings.HasSuffix(base, ".instructions.md"):
+			name := slugify(strings.TrimSuffix(base, ".instructions.md"))
+			var b strings.Builder
+			fmt.Fprintf(&b, "## Copilot Instructions: %s\n\n", name)
+			if applyTo := fields["applyTo"]; applyTo != "" {
+				fmt.Fprintf(&b, "Applies when working on files matching: `%s`\n\n", applyTo)
+			}
+			b.WriteString(demoteHeadings(body, 3) + "\n")
+			result.Sections = append(result.Sections, ManagedSection{Name: "copilot-" + name, Body: b.String()})
+
+		case strings.HasSuffix(base, ".prompt.md"):
+			name := slugify(strings.TrimSuffix(base, ".prompt.md"))
+			for _, key := range []string{"mode", "tools", "model"} {
+				if fields[key] != "" {
+					result.Skipped = append(result.Skipped, fmt.Sprintf("%s: prompt field %q has no Claude Code equivalent", rel, key))
+				}
+			}
+			if copilotInputPattern.MatchString(body) {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("%s: ${input:...} variables were replaced with $ARGUMENTS", rel))
+				body = copilotInputPattern.ReplaceAllString(body, "$$ARGUMENTS")
+			}
+			if strings.Contains(body, "#file:") {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("%s: #file: references are not resolved; use @path in Claude Code", rel))
+			}
+
+			description := fields["description"]
+			if description == "" {
+				description = "Imported Copilot prompt " + name
+			}
+			result.Commands = append(result.Commands, ImportedCommand{
+				Name:    name,
+				Content: "---\ndescription: " + description + "\n---\n\n" + body + "\n",
+			})
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// cursorImporter converts .cursorrules and .cursor/rules/*.mdc
+type cursorImporter struct{}
+
+// Detect finds Cursor rule files
+func (cursorImporter) Detect(dir string) []string {
+	var found []string
+	if fileExists(filepath.Join(dir, ".cursorrules")) {
+		found = append(found, ".cursorrules")
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, ".cursor", "rules", "*.mdc"))
+	sort.Strings(matches)
+	for _, match := range matches {
+		rel, _ := filepath.Rel(dir, match)
+		found = append(found, filepath.ToSlash(rel))
+	}
+	return found
+}
+
+// Import maps always-applied, glob-scoped and agent-requested rules to
+// CLAUDE.md sections, and manual rules (invoked with @rule) to slash commands
+func (c cursorImporter) Import(dir string) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	if data, err := os.ReadFile(filepath.Join(dir, ".cursorrules")); err == nil {
+		body := strings.TrimSpace(string(data))
+		if body != "" {
+			result.Sections = append(result.Sections, ManagedSection{
+				Name: "cursor-rules",
+				Body: "## Cursor Rules\n\n" + body + "\n",
+			})
+		}
+	}
+
+	for _, rel := range c.Detect(dir) {
+		if rel == ".cursorrules" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+
+		name := slugify(filepath.Base(rel))
+		fields, body := parseFrontmatter(string(data))
+		body = strings.TrimSpace(body)
+		if body == "" {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s has no rule content", rel))
+			continue
+		}
+
+		description := fields["description"]
+		globs := fields["globs"]
+		alwaysApply := fields["alwaysApply"] == "true"
+
+		if !alwaysApply && globs == "" && description == "" {
+			// Manual rules are only used when referenced, like a slash command
+			result.Commands = append(result.Commands, ImportedCommand{
+				Name:    name,
+				Content: "---\ndescription: Cursor rule " + name + "\n---\n\n" + body + "\n",
+			})
+			continue
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "## Cursor Rule: %s\n\n", name)
+		if description != "" {
+			fmt.Fprintf(&b, "_%s_\n\n", description)
+		}
+		if globs != "" && !alwaysApply {
+			fmt.Fprintf(&b, "Applies when working on files matching: `%s`\n\n", globs)
+		}
+		b.WriteString(body + "\n")
+
+		result.Sections = append(result.Sections, ManagedSection{Name: "cursor-" + name, Body: b.String()})
+	}
+
+	return result, nil
+}
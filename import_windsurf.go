@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// windsurfImporter converts .windsurfrules and .windsurf/rules/*.md
+type windsurfImporter struct{}
+
+// Detect finds Windsurf rule files
+func (windsurfImporter) Detect(dir string) []string {
+	var found []string
+	if fileExists(filepath.Join(dir, ".windsurfrules")) {
+		found = append(found, ".windsurfrules")
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, ".windsurf", "rules", "*.md"))
+	sort.Strings(matches)
+	for _, match := range matches {
+		rel, _ := filepath.Rel(dir, match)
+		found = append(found, filepath.ToSlash(rel))
+	}
+	return found
+}
+
+// Import maps always-on, glob and model-decision rules to CLAUDE.md sections,
+// and manual rules (invoked with @rule) to slash commands
+func (w windsurfImporter) Import(dir string) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	for _, rel := range w.Detect(dir) {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+
+		if rel == ".windsurfrules" {
+			if body := strings.TrimSpace(string(data)); body != "" {
+				result.Sections = append(result.Sections, ManagedSection{
+					Name: "windsurf-rules",
+					Body: "## Windsurf Rules\n\n" + demoteHeadings(body, 3) + "\n",
+				})
+			}
+			continue
+		}
+
+		name := slugify(filepath.Base(rel))
+		fields, body := parseFrontmatter(string(data))
+		body = strings.TrimSpace(body)
+		if body == "" {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s has no rule content", rel))
+			continue
+		}
+
+		description := fields["description"]
+		switch trigger := fields["trigger"]; trigger {
+		case "manual":
+			if description == "" {
+				description = "Windsurf rule " + name
+			}
+			result.Commands = append(result.Commands, ImportedCommand{
+				Name:    name,
+				Content: "---\ndescription: " + description + "\n---\n\n" + body + "\n",
+			})
+			continue
+		case "", "always_on", "model_decision", "glob":
+		default:
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: unknown trigger %q, imported as always on", rel, trigger))
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "## Windsurf Rule: %s\n\n", name)
+		if description != "" {
+			fmt.Fprintf(&b, "_%s_\n\n", description)
+		}
+		if globs := fields["globs"]; globs != "" && fields["trigger"] == "glob" {
+			fmt.Fprintf(&b, "Applies when working on files matching: `%s`\n\n", globs)
+		}
+		b.WriteString(demoteHeadings(body, 3) + "\n")
+
+		result.Sections = append(result.Sections, ManagedSection{Name: "windsurf-" + name, Body: b.String()})
+	}
+
+	return result, nil
+}
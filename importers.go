@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Importer converts another AI assistant's configuration into Claude Code files
+type Importer interface {
+	// Detect returns the source files present in dir, relative to dir
+	Detect(dir string) []string
+	// Import reads the source files in dir and converts them
+	Import(dir string) (*ImportResult, error)
+}
+
+// ImportedCommand becomes a slash command in .claude/commands
+type ImportedCommand struct {
+	Name    string
+	Content string
+}
+
+// ImportResult is the Claude Code configuration produced by an Importer
+type ImportResult struct {
+	Sections []ManagedSection
+	Commands []ImportedCommand
+	// Allow lists permission rules to add to settings.json
+	Allow []string
+	// MCPServers are added to .mcp.json by name
+	MCPServers map[string]map[string]interface{}
+	// Settings holds top-level settings.json keys, set only where absent
+	Settings map[string]interface{}
+	// Skipped describes source content that could not be mapped
+	Skipped []string
+}
+
+// importers lists the supported import sources by name
+var importers = map[string]Importer{
+	"aider":    aiderImporter{},
+	"continue": continueImporter{},
+	"copilot":  copilotImporter{},
+	"cursor":   cursorImporter{},
+	"windsurf": windsurfImporter{},
+}
+
+// Modes of --import, for the configurations of other assistants found
+// during init
+const (
+	ImportAsk  = "ask"
+	ImportAuto = "auto"
+	ImportOff  = "off"
+)
+
+// validateImportMode checks the --import value; empty means ask
+func validateImportMode(mode string) error {
+	switch mode {
+	case "", ImportAsk, ImportAuto, ImportOff:
+		return nil
+	default:
+		return fmt.Errorf("unknown --import mode %q (expected %s, %s or %s)", mode, ImportAsk, ImportAuto, ImportOff)
+	}
+}
+
+// importerNames returns the sorted names of the supported import sources
+func importerNames() []string {
+	names := make([]string, 0, len(importers))
+	for name := range importers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// slugPattern matches runs of characters that are not allowed in section and command names
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify turns a file or heading name into a lowercase dash-separated name
+func slugify(name string) string {
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if windowsReservedName(slug) {
+		// Commands are written as <slug>.md, which Windows refuses for "con" etc.
+		slug += "-rule"
+	}
+	return slug
+}
+
+// headingPattern matches an ATX markdown heading
+var headingPattern = regexp.MustCompile(`^(#{1,6})(\s.*)$`)
+
+// demoteHeadings shifts markdown headings so the shallowest becomes level
+// minLevel, keeping their relative structure and leaving code blocks alone
+func demoteHeadings(body string, minLevel int) string {
+	lines := strings.Split(body, "\n")
+
+	shallowest := 7
+	inFence := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+			continue
+		}
+		if m := headingPattern.FindStringSubmatch(line); m != nil && !inFence && len(m[1]) < shallowest {
+			shallowest = len(m[1])
+		}
+	}
+	if shallowest == 7 || shallowest >= minLevel {
+		return body
+	}
+
+	shift := minLevel - shallowest
+	inFence = false
+	for i, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+			continue
+		}
+		if m := headingPattern.FindStringSubmatch(line); m != nil && !inFence {
+			level := len(m[1]) + shift
+			if level > 6 {
+				level = 6
+			}
+			lines[i] = strings.Repeat("#", level) + m[2]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// runImport implements `cc-init import <source>`
+func runImport(args []string) error {
+	config := &Config{}
+	fs := newCommandFlagSet(findCommand("import"), config)
+	fs.StringVar(&config.AgentsMD, "agents-md", "", tr("Also maintain AGENTS.md: sync or pointer"))
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected: cc-init import <%s>", strings.Join(importerNames(), "|"))
+	}
+
+	importer, ok := importers[positional[0]]
+	if !ok {
+		return fmt.Errorf("unknown import source %q (available: %s)", positional[0], strings.Join(importerNames(), ", "))
+	}
+
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	if err := validateAgentsMDMode(config.AgentsMD); err != nil {
+		return err
+	}
+
+	if config.Remote != nil {
+		return fmt.Errorf("import does not support remote targets")
+	}
+	engine, err := NewEngine(templateFiles(), config)
+	if err != nil {
+		return err
+	}
+	err = engine.Apply(
+		func() error { return engine.importFrom(positional[0], importer) },
+		engine.writeMemoryFiles,
+	)
+	if closeErr := engine.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// importFrom runs an Importer against the target and writes its results
+func (e *Engine) importFrom(name string, importer Importer) error {
+	sources := importer.Detect(e.config.TargetDir)
+	if len(sources) == 0 {
+		return fmt.Errorf("no %s configuration found in %s", name, e.config.TargetDir)
+	}
+	for _, source := range sources {
+		e.logger.Debug("Importing %s", source)
+	}
+
+	result, err := importer.Import(e.config.TargetDir)
+	if err != nil {
+		return fmt.Errorf("failed to import %s configuration: %w", name, err)
+	}
+	return e.applyImport(result)
+}
+
+// offerImports looks for the configurations of other assistants in the
+// target. With --import=auto they are converted; otherwise they are offered
+// when the target is first initialized: asked in a terminal, and pointed out
+// with the command to run elsewhere.
+func (e *Engine) offerImports() error {
+	mode := e.config.ImportMode
+	if mode == ImportOff || e.config.Remote != nil {
+		return nil
+	}
+	if mode != ImportAuto {
+		if _, err := e.sharedLock(); err == nil {
+			return nil
+		}
+	}
+	interactive := stdinIsTerminal() && !e.config.CI && !e.config.Yes
+	var input *bufio.Reader
+	for _, name := range importerNames() {
+		importer := importers[name]
+		sources := importer.Detect(e.config.TargetDir)
+		if len(sources) == 0 {
+			continue
+		}
+		found := strings.Join(sources, ", ")
+		if mode != ImportAuto {
+			if !interactive {
+				e.logger.Info("Found %s; pass --import=auto to convert it, or run cc-init import %s", found, name)
+				continue
+			}
+			if input == nil {
+				input = bufio.NewReader(os.Stdin)
+			}
+			answer, err := promptVariable(os.Stderr, input, PackVariable{
+				Name:    "import " + name,
+				Type:    VarBool,
+				Help:    fmt.Sprintf(tr("Found %s; convert it into the Claude configuration?"), found),
+				Default: "yes",
+			})
+			if err != nil {
+				return err
+			}
+			if ok, _ := parseVarBool(answer); !ok {
+				continue
+			}
+		}
+		e.logger.Info("Importing %s", found)
+		if err := e.importFrom(name, importer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyImport writes the sections and commands of an ImportResult
+func (e *Engine) applyImport(result *ImportResult) error {
+	e.queueSections(result.Sections...)
+
+	if len(result.Commands) > 0 {
+		dir := filepath.Join(e.config.TargetDir, ".claude", "commands")
+		if err := e.processDirectory(dir); err != nil {
+			return err
+		}
+		for _, cmd := range result.Commands {
+			if err := e.installFile(filepath.Join(dir, cmd.Name+".md"), []byte(cmd.Content), 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(result.Allow) > 0 || len(result.Settings) > 0 {
+		if err := e.processDirectory(filepath.Join(e.config.TargetDir, ".claude")); err != nil {
+			return err
+		}
+		err := e.updateSettings(func(settings Settings) {
+			settings.AddPermissionRules(PermissionAllow, result.Allow)
+			for key, value := range result.Settings {
+				if _, exists := settings[key]; !exists {
+					settings[key] = value
+				}
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(result.MCPServers) > 0 {
+		if err := e.addMCPServers(result.MCPServers); err != nil {
+			return err
+		}
+	}
+
+	for _, note := range result.Skipped {
+		e.logger.Warning("Not fully mapped: %s", note)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import "bytes"
+
+// stripJSONC blanks out // and /* */ comments and trailing commas so that a
+// hand-edited JSONC document decodes as JSON. Removed bytes are replaced with
+// spaces (newlines are kept) so offsets, lines and columns stay accurate.
+func stripJSONC(data []byte) []byte {
+	out := append([]byte(nil), data...)
+	blank := func(from, to int) {
+		for i := from; i < to; i++ {
+			if out[i] != '\n' && out[i] != '\r' {
+				out[i] = ' '
+			}
+		}
+	}
+
+	lastComma := -1
+	for i := 0; i < len(out); i++ {
+		switch c := out[i]; {
+		case c == '"':
+			lastComma = -1
+			for i++; i < len(out) && out[i] != '"'; i++ {
+				if out[i] == '\\' {
+					i++
+				}
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			end := bytes.IndexByte(out[i:], '\n')
+			if end < 0 {
+				end = len(out) - i
+			}
+			blank(i, i+end)
+			i += end - 1
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(out) - i - 2
+			} else {
+				end += 2
+			}
+			blank(i, i+2+end)
+			i += 1 + end
+		case c == ',':
+			lastComma = i
+		case c == '}' || c == ']':
+			if lastComma >= 0 {
+				out[lastComma] = ' '
+			}
+			lastComma = -1
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		default:
+			lastComma = -1
+		}
+	}
+	return out
+}
+
+// hasJSONComments reports whether a JSONC document contains comments
+func hasJSONComments(data []byte) bool {
+	stripped := stripJSONC(data)
+	for i := range data {
+		if data[i] != stripped[i] && (data[i] == '/' || data[i] == '*') {
+			return true
+		}
+	}
+	return false
+}
+
+// leadingJSONComments returns the comment lines that precede the top-level
+// value of a JSONC document, or "" if there are none
+func leadingJSONComments(data []byte) string {
+	stripped := stripJSONC(data)
+	start := bytes.IndexAny(stripped, "{[")
+	if start <= 0 {
+		return ""
+	}
+	header := bytes.TrimSpace(data[:start])
+	if len(header) == 0 {
+		return ""
+	}
+	return string(header) + "\n"
+}
+
+// preserveJSONComments carries the leading comments of existing over to the
+// re-encoded document and reports whether any other comments were dropped
+func preserveJSONComments(existing, encoded []byte) ([]byte, bool) {
+	start := bytes.IndexAny(stripJSONC(existing), "{[")
+	if start < 0 || !hasJSONComments(existing) {
+		return encoded, false
+	}
+	header := leadingJSONComments(existing)
+	return append([]byte(header), encoded...), hasJSONComments(existing[start:])
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// orgConfigEnv names the environment variable with the path or URL of the
+// organization configuration
+const orgConfigEnv = "CC_INIT_ORG_CONFIG"
+
+// orgConfigCacheFile keeps, in the user cache directory, the last copy of an
+// organization configuration fetched over HTTP, for runs while offline
+const orgConfigCacheFile = "org-config.yaml"
+
+// orgConfigTimeout bounds fetching the organization configuration
+const orgConfigTimeout = 10 * time.Second
+
+// OrgConfig is the organization configuration: flag defaults that platform
+// teams set for everyone, such as the template source, the permission
+// preset or the conflict policy
+type OrgConfig struct {
+	Defaults map[string]interface{} `yaml:"defaults"`
+}
+
+// loadOrgConfig reads the organization configuration from the path or URL
+// in CC_INIT_ORG_CONFIG. Without it there is none; a URL that cannot be
+// fetched falls back to the copy fetched last.
+func loadOrgConfig() (*OrgConfig, error) {
+	config := &OrgConfig{}
+	source := os.Getenv(orgConfigEnv)
+	if source == "" {
+		return config, nil
+	}
+	var data []byte
+	var err error
+	if isTemplateURL(source) {
+		data, err = fetchOrgConfig(source)
+	} else {
+		data, err = os.ReadFile(expandHome(source))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the organization configuration: %w", err)
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid organization configuration %s: %w", source, err)
+	}
+	return config, nil
+}
+
+// fetchOrgConfig downloads the organization configuration and keeps a copy
+// in the cache, which it returns when the download fails
+func fetchOrgConfig(url string) ([]byte, error) {
+	var cached string
+	if dir, err := userCacheDir(); err == nil {
+		cached = filepath.Join(dir, orgConfigCacheFile)
+	}
+	client := &http.Client{Timeout: orgConfigTimeout}
+	body, err := httpGet(client, url)
+	if err == nil {
+		defer body.Close()
+		var data []byte
+		if data, err = io.ReadAll(body); err == nil {
+			if cached != "" && os.MkdirAll(filepath.Dir(cached), 0755) == nil {
+				os.WriteFile(cached, data, 0644)
+			}
+			return data, nil
+		}
+	}
+	if cached != "" {
+		if data, readErr := os.ReadFile(cached); readErr == nil {
+			return data, nil
+		}
+	}
+	return nil, err
+}
+
+// projectSafeFlags are the flags the .cc-init.yaml of a checkout may set.
+// Anyone who can push to the repository controls that file, so it may only
+// shape what is written inside the target, as committed files could. Flags
+// that choose the target or the templates, write elsewhere, run commands,
+// read the environment, send data away or skip questions are left to flags
+// and the user and organization configuration.
+var projectSafeFlags = map[string]bool{
+	"agents-md":          true,
+	"allow":              true,
+	"ascii":              true,
+	"chmod":              true,
+	"claude-md":          true,
+	"conflict":           true,
+	"deny":               true,
+	"devcontainer":       true,
+	"dry-run":            true,
+	"hook-platform":      true,
+	"hooks":              true,
+	"import":             true,
+	"include-submodules": true,
+	"line-endings":       true,
+	"local":              true,
+	"log-format":         true,
+	"log-level":          true,
+	"managed-settings":   true,
+	"mcp":                true,
+	"migrate":            true,
+	"mtime":              true,
+	"no-autodetect":      true,
+	"no-color":           true,
+	"output-styles":      true,
+	"permissions":        true,
+	"preset":             true,
+	"prune":              true,
+	"report":             true,
+	"respect-umask":      true,
+	"retries":            true,
+	"retry-delay":        true,
+	"secret-scan":        true,
+	"seed":               true,
+	"set":                true,
+	"shell":              true,
+	"statusline":         true,
+	"trash":              true,
+	"update":             true,
+	"var":                true,
+	"verbose":            true,
+	"vv":                 true,
+	"workspaces":         true,
+}
+
+// checkProjectDefaults refuses project defaults for flags outside
+// projectSafeFlags
+func checkProjectDefaults(defaults map[string]interface{}, path string) error {
+	for _, name := range sortedKeys(defaults) {
+		if !projectSafeFlags[name] {
+			return fmt.Errorf("%s sets --%s, which a project may not set; pass it as a flag or set it in the user or organization configuration", path, name)
+		}
+	}
+	return nil
+}
+
+// configDefault is a flag default and the configuration it comes from
+type configDefault struct {
+	value  interface{}
+	source string
+}
+
+// configDefaults returns the flag defaults of the configuration layers, the
+// organization's beneath the user's beneath those of the project in dir; a
+// higher layer replaces the value of a lower one
+func configDefaults(dir string) (map[string]configDefault, error) {
+	defaults := map[string]configDefault{}
+	merge := func(layer map[string]interface{}, source string) {
+		for name, value := range layer {
+			defaults[name] = configDefault{value: value, source: source}
+		}
+	}
+
+	org, err := loadOrgConfig()
+	if err != nil {
+		return nil, err
+	}
+	merge(org.Defaults, os.Getenv(orgConfigEnv))
+
+	user, err := loadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	path, _ := userConfigPath()
+	merge(user.Defaults, path)
+
+	if dir != "" {
+		path := filepath.Join(dir, projectConfigFile)
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		var project ProjectConfig
+		if err := yaml.Unmarshal(data, &project); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", projectConfigFile, err)
+		}
+		if err := checkProjectDefaults(project.Defaults, path); err != nil {
+			return nil, err
+		}
+		merge(project.Defaults, path)
+	}
+	return defaults, nil
+}
+
+// projectDir returns the local directory whose .cc-init.yaml applies to a
+// run with the flags of fs, or "" for remote targets and commands without
+// a target
+func projectDir(fs *flag.FlagSet) string {
+	f := fs.Lookup("target")
+	if f == nil {
+		return ""
+	}
+	target := f.Value.String()
+	if target == "" {
+		config := &Config{}
+		if f := fs.Lookup("no-git-root"); f != nil {
+			config.NoGitRoot = f.Value.String() == "true"
+		}
+		dir, err := defaultTargetDir(config)
+		if err != nil {
+			return ""
+		}
+		return dir
+	}
+	if _, remote, _ := parseRemoteTarget(target); remote {
+		return ""
+	}
+	dir, err := filepath.Abs(target)
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// applyConfigDefaults sets the flags of fs not given on the command line to
+// the defaults of the configuration layers. Flags sharing a variable, such
+// as -t and --target, count as given together. Defaults for flags fs does
+// not have belong to other commands and are skipped.
+func applyConfigDefaults(fs *flag.FlagSet) error {
+	defaults, err := configDefaults(projectDir(fs))
+	if err != nil {
+		return err
+	}
+	given := map[interface{}]bool{}
+	fs.Visit(func(f *flag.Flag) { given[flagVariable(f)] = true })
+
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil || given[flagVariable(f)] {
+			continue
+		}
+		values, ok := defaults[name].value.([]interface{})
+		if !ok {
+			values = []interface{}{defaults[name].value}
+		}
+		for _, value := range values {
+			if value == nil {
+				value = ""
+			}
+			if err := fs.Set(name, fmt.Sprint(value)); err != nil {
+				return fmt.Errorf("invalid default for --%s in %s: %w", name, defaults[name].source, err)
+			}
+		}
+	}
+	return nil
+}
+
+// flagVariable identifies the variable a flag sets, so that a shorthand and
+// its long form are recognized as one flag
+func flagVariable(f *flag.Flag) interface{} {
+	if v := reflect.ValueOf(f.Value); v.Kind() == reflect.Pointer {
+		return v.Pointer()
+	}
+	return f.Name
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// layerFixture points the configuration layers at temporary files: the user
+// configuration and the .cc-init.yaml of a project, and returns the project
+func layerFixture(t *testing.T, user, project string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+	t.Setenv(orgConfigEnv, "")
+	if user != "" {
+		dir := filepath.Join(home, appDirName)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, userConfigFile), []byte(user), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dir := t.TempDir()
+	if project != "" {
+		if err := os.WriteFile(filepath.Join(dir, projectConfigFile), []byte(project), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// parseWithLayers parses args with the flags of an init run and applies the
+// configuration layers
+func parseWithLayers(t *testing.T, args ...string) (*Config, error) {
+	t.Helper()
+	config := &Config{}
+	fs := flag.NewFlagSet("cc-init", flag.ContinueOnError)
+	defineInitFlags(fs, config)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return config, applyConfigDefaults(fs)
+}
+
+func TestProjectLayerCannotSetRestrictedFlags(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "victim.txt")
+	tests := []struct {
+		name, value string
+		applied     func(*Config) bool
+	}{
+		{"allow-env", "AWS_SECRET_ACCESS_KEY", func(c *Config) bool { return len(c.AllowEnv) > 0 }},
+		{"allow-exec", "true", func(c *Config) bool { return c.AllowExec }},
+		{"merge-tool", "touch /tmp/pwned", func(c *Config) bool { return c.MergeTool == "touch /tmp/pwned" }},
+		{"notify-url", "https://collector.example.com/", func(c *Config) bool { return c.NotifyURL != "" }},
+		{"template-dir", "/tmp/evil/pack", func(c *Config) bool { return c.TemplateDir != "" }},
+		{"report-file", outside, func(c *Config) bool { return c.ReportFile != "" }},
+		{"audit-log", outside, func(c *Config) bool { return c.AuditLog != "" }},
+		{"log-file", outside, func(c *Config) bool { return c.LogFile != "" }},
+		{"output-archive", outside, func(c *Config) bool { return c.OutputArchive != "" }},
+		{"emit-patch", outside, func(c *Config) bool { return c.EmitPatch != "" }},
+		{"answers", outside, func(c *Config) bool { return c.AnswersFile != "" }},
+		{"yes", "true", func(c *Config) bool { return c.Yes }},
+		{"ci", "true", func(c *Config) bool { return c.CI }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(auditLogEnv, "")
+			t.Setenv(mergeToolEnv, "")
+			t.Setenv(notifyURLEnv, "")
+			dir := layerFixture(t, "", "defaults:\n  "+tt.name+": "+tt.value+"\n")
+			config, err := parseWithLayers(t, "-t", dir)
+			if err == nil || !strings.Contains(err.Error(), "sets --"+tt.name+", which a project may not set") {
+				t.Fatalf("a project set --%s: %v", tt.name, err)
+			}
+			if tt.applied(config) {
+				t.Fatalf("a project set --%s: %+v", tt.name, config)
+			}
+		})
+	}
+}
+
+func TestProjectLayerCannotChooseTarget(t *testing.T) {
+	elsewhere := t.TempDir()
+	dir := layerFixture(t, "", "defaults:\n  target: "+elsewhere+"\n")
+	t.Chdir(dir)
+	config, err := parseWithLayers(t, "--no-git-root")
+	if err == nil || !strings.Contains(err.Error(), "sets --target, which a project may not set") {
+		t.Fatalf("a project chose the target: %v", err)
+	}
+	if config.TargetDir == elsewhere {
+		t.Fatalf("a project moved the target to %s", elsewhere)
+	}
+}
+
+func TestProjectLayerOnlySetsSafeFlags(t *testing.T) {
+	fs := flag.NewFlagSet("cc-init", flag.ContinueOnError)
+	defineInitFlags(fs, &Config{})
+	fs.VisitAll(func(f *flag.Flag) {
+		err := checkProjectDefaults(map[string]interface{}{f.Name: "x"}, projectConfigFile)
+		if projectSafeFlags[f.Name] != (err == nil) {
+			t.Errorf("--%s: safe %v, but checkProjectDefaults = %v", f.Name, projectSafeFlags[f.Name], err)
+		}
+	})
+	for name := range projectSafeFlags {
+		if fs.Lookup(name) == nil {
+			t.Errorf("projectSafeFlags names --%s, which is no flag of an init run", name)
+		}
+	}
+}
+
+func TestReportFileSkippedInDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	for _, dryRun := range []bool{true, false} {
+		reporter := &ReportFileReporter{Reporter: &JSONReporter{writer: io.Discard}, path: path, dryRun: dryRun}
+		if err := reporter.Report(&Report{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(path); (err == nil) == dryRun {
+			t.Fatalf("dry run %v: report file written = %v", dryRun, err == nil)
+		}
+	}
+}
+
+func TestProjectLayerSetsOtherFlags(t *testing.T) {
+	dir := layerFixture(t, "", "defaults:\n  conflict: ours\n")
+	config, err := parseWithLayers(t, "-t", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Conflict != ConflictOurs {
+		t.Fatalf("conflict = %q, want %q", config.Conflict, ConflictOurs)
+	}
+}
+
+func TestUserLayerSetsRestrictedFlags(t *testing.T) {
+	dir := layerFixture(t, "defaults:\n  allow-exec: true\n  template-dir: /srv/packs/team\n", "")
+	config, err := parseWithLayers(t, "-t", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !config.AllowExec || config.TemplateDir != "/srv/packs/team" {
+		t.Fatalf("the user layer did not apply: allow-exec %v, template-dir %q", config.AllowExec, config.TemplateDir)
+	}
+}
+
+func TestFlagsBeatConfigurationLayers(t *testing.T) {
+	dir := layerFixture(t, "defaults:\n  conflict: theirs\n", "defaults:\n  conflict: ours\n")
+	config, err := parseWithLayers(t, "-t", dir, "--conflict", "markers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Conflict != ConflictMarkers {
+		t.Fatalf("conflict = %q, want %q", config.Conflict, ConflictMarkers)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Line ending modes accepted by --line-endings
+const (
+	LineEndingsLF   = "lf"
+	LineEndingsCRLF = "crlf"
+	LineEndingsAuto = "auto"
+)
+
+// validateLineEndings checks the --line-endings value
+func validateLineEndings(mode string) error {
+	switch mode {
+	case "", LineEndingsLF, LineEndingsCRLF, LineEndingsAuto:
+		return nil
+	default:
+		return fmt.Errorf("unknown --line-endings mode %q (expected %s, %s or %s)", mode, LineEndingsLF, LineEndingsCRLF, LineEndingsAuto)
+	}
+}
+
+// eolRule is a .gitattributes line that sets or unsets eol for a pattern
+type eolRule struct {
+	pattern string
+	eol     string // "lf", "crlf", or "" for binary/-text
+}
+
+// parseGitattributesEOL extracts the rules that affect line endings
+func parseGitattributesEOL(data []byte) []eolRule {
+	var rules []eolRule
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			switch attr {
+			case "eol=lf":
+				rules = append(rules, eolRule{fields[0], LineEndingsLF})
+			case "eol=crlf":
+				rules = append(rules, eolRule{fields[0], LineEndingsCRLF})
+			case "binary", "-text":
+				rules = append(rules, eolRule{fields[0], ""})
+			}
+		}
+	}
+	return rules
+}
+
+// lineEndingFor returns the line ending to write targetPath with, or "" to
+// leave the content alone
+func (e *Engine) lineEndingFor(targetPath string) string {
+	// Shell scripts run under bash even on Windows, where CRLF breaks them
+	script := filepath.Ext(targetPath) == ".sh"
+	switch e.config.LineEndings {
+	case LineEndingsLF:
+		return LineEndingsLF
+	case LineEndingsCRLF:
+		if script {
+			return LineEndingsLF
+		}
+		return LineEndingsCRLF
+	}
+
+	if !e.eolLoaded {
+		e.eolLoaded = true
+		if data, err := e.fs.ReadFile(filepath.Join(e.config.TargetDir, ".gitattributes")); err == nil {
+			e.eolRules = parseGitattributesEOL(data)
+		}
+	}
+	rel := filepath.ToSlash(e.formatPath(targetPath))
+	for i := len(e.eolRules) - 1; i >= 0; i-- {
+		if matchPathPattern(e.eolRules[i].pattern, rel) {
+			return e.eolRules[i].eol
+		}
+	}
+
+	if runtime.GOOS == "windows" && !script {
+		return LineEndingsCRLF
+	}
+	return LineEndingsLF
+}
+
+// normalizeLineEndings converts the line endings of text content for
+// targetPath according to --line-endings; binary content is left alone
+func (e *Engine) normalizeLineEndings(targetPath string, content []byte) []byte {
+	if e.config.LineEndings == "" || content == nil || bytes.IndexByte(content, 0) >= 0 {
+		return content
+	}
+	eol := e.lineEndingFor(targetPath)
+	if eol == "" {
+		return content
+	}
+	lf := bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	if eol == LineEndingsCRLF {
+		return bytes.ReplaceAll(lf, []byte("\n"), []byte("\r\n"))
+	}
+	return lf
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LintIssue is a problem found in a template pack
+type LintIssue struct {
+	Path    string
+	Message string
+}
+
+// agentNamePattern matches the lowercase, hyphenated names Claude Code
+// expects for subagents
+var agentNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// packVersionPattern matches pack versions such as 1.2.0 or v2.0
+var packVersionPattern = regexp.MustCompile(`^v?\d+(\.\d+)*$`)
+
+// broadToolRules are allow rules that grant a tool without any restriction
+var broadToolRules = map[string]bool{
+	"Bash":     true,
+	"Bash(*)":  true,
+	"Bash(:*)": true,
+	"Edit":     true,
+	"Write":    true,
+	"WebFetch": true,
+}
+
+// dangerousCommands are command prefixes a pack should never allow
+// unattended
+var dangerousCommands = []string{"sudo", "rm -rf", "chmod 777", "curl", "wget", "git push --force", "git push -f"}
+
+// lintPack checks the files of a template pack laid out like .claude
+func lintPack(pack fs.FS) ([]LintIssue, error) {
+	var issues []LintIssue
+	add := func(p, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{Path: p, Message: fmt.Sprintf(tr(format), args...)})
+	}
+
+	templates := map[string][]byte{}
+	err := fs.WalkDir(pack, ".", func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil && info.Mode().Perm()&0002 != 0 {
+			add(p, "file is world-writable (%v)", info.Mode().Perm())
+		}
+		data, err := fs.ReadFile(pack, p)
+		if err != nil {
+			return err
+		}
+
+		if isTemplateFile(p) {
+			// Templates are checked once the functions of the pack are known
+			templates[p] = data
+			return nil
+		}
+		switch {
+		case path.Ext(p) == ".json" && strings.HasPrefix(path.Base(p), "settings"):
+			lintSettings(p, data, add)
+		case path.Ext(p) == ".json":
+			if !json.Valid(stripJSONC(data)) {
+				add(p, "invalid JSON")
+			}
+		case path.Ext(p) == ".md":
+			lintMarkdown(p, string(data), add)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var funcs []string
+	if manifest, err := loadPackManifest(pack); err != nil {
+		add(packManifestFile, "%v", err)
+	} else if manifest != nil {
+		if manifest.Name == "" {
+			add(packManifestFile, "manifest is missing %q", "name")
+		}
+		if manifest.Version != "" && !packVersionPattern.MatchString(manifest.Version) {
+			add(packManifestFile, "version %q is not a dotted version such as 1.2.0", manifest.Version)
+		}
+		seen := map[string]bool{}
+		for _, entry := range manifest.Changelog {
+			switch {
+			case !packVersionPattern.MatchString(entry.Version):
+				add(packManifestFile, "changelog version %q is not a dotted version such as 1.2.0", entry.Version)
+			case seen[entry.Version]:
+				add(packManifestFile, "changelog lists version %s twice", entry.Version)
+			case manifest.Version != "" && compareVersions(entry.Version, manifest.Version) > 0:
+				add(packManifestFile, "changelog lists version %s, newer than the pack version %s", entry.Version, manifest.Version)
+			}
+			seen[entry.Version] = true
+		}
+		if err := validateVariables(manifest.Variables); err != nil {
+			add(packManifestFile, "%v", err)
+		}
+		if len(manifest.WASM) > 0 {
+			if plugins, err := LoadWASMPlugins(pack, manifest.WASM); err != nil {
+				add(packManifestFile, "%v", err)
+			} else {
+				funcs = plugins.Functions()
+				plugins.Close()
+			}
+		}
+	}
+	for p, data := range templates {
+		if err := parseTemplate(p, data, funcs); err != nil {
+			add(p, "%v", err)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return issues, nil
+}
+
+// lintSettings checks a settings file against the schema and flags
+// allow rules that grant too much
+func lintSettings(p string, data []byte, add func(p, format string, args ...interface{})) {
+	errs, err := ValidateSettings(data)
+	if err != nil {
+		add(p, "%v", err)
+		return
+	}
+	for _, schemaErr := range errs {
+		add(p, "%s", schemaErr.Error())
+	}
+	settings, err := ParseSettings(data)
+	if err != nil {
+		return
+	}
+
+	permissions, _ := settings["permissions"].(map[string]interface{})
+	rules, _ := permissions[PermissionAllow].([]interface{})
+	for _, value := range rules {
+		rule, _ := value.(string)
+		if broadToolRules[rule] {
+			add(p, "allow rule %q grants the tool without restriction", rule)
+		}
+		for _, secret := range ruleGroups["secrets"] {
+			if rule == secret {
+				add(p, "allow rule %q exposes secret files", rule)
+			}
+		}
+		if command, ok := strings.CutPrefix(rule, "Bash("); ok {
+			for _, prefix := range dangerousCommands {
+				if strings.HasPrefix(command, prefix) {
+					add(p, "allow rule %q runs %q without confirmation", rule, prefix)
+				}
+			}
+		}
+	}
+}
+
+// lintMarkdown checks the frontmatter of agents, commands and output styles
+func lintMarkdown(p, doc string, add func(p, format string, args ...interface{})) {
+	doc = strings.ReplaceAll(doc, "\r\n", "\n")
+	if !strings.HasPrefix(doc, "---\n") {
+		if strings.HasPrefix(p, "agents/") {
+			add(p, "agent has no frontmatter")
+		}
+		return
+	}
+	end := strings.Index(doc[4:], "\n---")
+	if end < 0 {
+		add(p, "frontmatter is not closed with ---")
+		return
+	}
+
+	var fields map[string]interface{}
+	if err := yaml.Unmarshal([]byte(doc[4:4+end]), &fields); err != nil {
+		add(p, "invalid frontmatter: %v", err)
+		return
+	}
+	if !strings.HasPrefix(p, "agents/") {
+		return
+	}
+	for _, key := range []string{"name", "description"} {
+		if value, _ := fields[key].(string); strings.TrimSpace(value) == "" {
+			add(p, "agent frontmatter is missing %q", key)
+		}
+	}
+	if name, _ := fields["name"].(string); name != "" && !agentNamePattern.MatchString(name) {
+		add(p, "agent name %q should be lowercase letters, digits and hyphens", name)
+	}
+}
+
+// openPack opens a template pack directory, or the embedded template set
+// when dir is empty
+func openPack(dir, set string) (fs.FS, error) {
+	if dir == "" {
+		return openTemplateSet(set)
+	}
+	if set != "" {
+		return nil, fmt.Errorf("--set selects embedded templates and cannot be combined with --template-dir")
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return os.DirFS(dir), nil
+}
+
+// runLint implements `cc-init lint`
+func runLint(args []string) error {
+	cmd := findCommand("lint")
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	templateDir := fs.String("template-dir", "", tr("Template pack to check, laid out like .claude (default: the embedded templates)"))
+	set := fs.String("set", "", tr("Embedded template set to check: ")+strings.Join(templateSetNames(), ", "))
+	noColor := fs.Bool("no-color", false, tr("Disable colored output"))
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %v", positional)
+	}
+
+	pack, err := openPack(*templateDir, *set)
+	if err != nil {
+		return err
+	}
+
+	issues, err := lintPack(pack)
+	if err != nil {
+		return err
+	}
+	logger := NewLogger(false, *noColor)
+	for _, issue := range issues {
+		logger.Error("%s: %s", issue.Path, issue.Message)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("found %d %s", len(issues), pluralize("problem", len(issues)))
+	}
+	logger.Success("Template pack passed lint")
+	return nil
+}
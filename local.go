@@ -0,0 +1,106 @@
+package main
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Personal override files, relative to the target root
+const (
+	claudeLocalMDFile     = "CLAUDE.local.md"
+	localSettingsFile     = "settings.local.json"
+	localSettingsRelative = ".claude/" + localSettingsFile
+)
+
+// localOverrideFiles are personal files that must stay out of version control
+// and are never treated as managed configuration
+var localOverrideFiles = []string{claudeLocalMDFile, localSettingsRelative}
+
+// gitignoreComment introduces the entries cc-init adds to .gitignore
+const gitignoreComment = "# Claude Code personal overrides (added by cc-init)"
+
+// claudeLocalMDExample is written to a new CLAUDE.local.md
+const claudeLocalMDExample = `# CLAUDE.local.md
+
+Personal instructions for Claude Code in this project. This file is gitignored
+and is read in addition to CLAUDE.md; use it for preferences that should not be
+shared with the team, e.g.:
+
+- Preferred sandbox URLs or test data
+- Your local toolchain paths
+- Reminders about work in progress
+`
+
+// localSettingsExample is written to a new .claude/settings.local.json
+const localSettingsExample = `{
+  "permissions": {
+    "allow": [],
+    "deny": []
+  }
+}
+`
+
+// isLocalOverride reports whether relPath (slash-separated, relative to the
+// target root) is a personal override file
+func isLocalOverride(relPath string) bool {
+	relPath = path.Clean(filepath.ToSlash(relPath))
+	for _, file := range localOverrideFiles {
+		if relPath == file {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureGitignoreEntries appends any missing entries to a .gitignore document
+func ensureGitignoreEntries(doc string, entries []string) string {
+	present := map[string]bool{}
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		present[strings.TrimPrefix(line, "/")] = true
+	}
+
+	var missing []string
+	for _, entry := range entries {
+		if !present[entry] {
+			missing = append(missing, entry)
+		}
+	}
+	if len(missing) == 0 {
+		return doc
+	}
+
+	if doc != "" && !strings.HasSuffix(doc, "\n") {
+		doc += "\n"
+	}
+	if doc != "" {
+		doc += "\n"
+	}
+	return doc + gitignoreComment + "\n" + strings.Join(missing, "\n") + "\n"
+}
+
+// generateLocalOverrides creates example personal override files and makes
+// sure they are gitignored
+func (e *Engine) generateLocalOverrides() error {
+	if !e.config.LocalOverrides {
+		return nil
+	}
+
+	if err := e.installFile(filepath.Join(e.config.TargetDir, claudeLocalMDFile), []byte(claudeLocalMDExample), 0644); err != nil {
+		return err
+	}
+
+	// The template walk already handled settings.local.json if the pack ships one
+	if _, err := e.tmpl.ReadFile(localSettingsFile); err != nil {
+		target := filepath.Join(e.config.TargetDir, filepath.FromSlash(localSettingsRelative))
+		if err := e.installFile(target, []byte(localSettingsExample), 0644); err != nil {
+			return err
+		}
+	}
+
+	gitignore := filepath.Join(e.config.TargetDir, ".gitignore")
+	return e.updateFile(gitignore, 0644, func(existing []byte) ([]byte, error) {
+		return []byte(ensureGitignoreEntries(string(existing), localOverrideFiles)), nil
+	})
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// lockFile records what cc-init installed, relative to the target directory
+const lockFile = ".claude/cc-init.lock"
+
+// Lock records the templates a target was initialized from and the content
+// of every file cc-init manages there, so hooks can detect drift cheaply.
+// PackVersion is the version in pack.yaml of the installed pack.
+type Lock struct {
+	Version     string            `json:"version"`
+	Set         string            `json:"set,omitempty"`
+	PackVersion string            `json:"pack_version,omitempty"`
+	Templates   string            `json:"templates"`
+	Files       map[string]string `json:"files"`
+}
+
+// contentDigest returns the digest of a file's content as stored in the lock
+func contentDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Digest returns a digest of the paths and contents of all templates, which
+// identifies the template set a binary ships
+func (tm *TemplateManager) Digest() (string, error) {
+	var paths []string
+	err := tm.Walk(func(p string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		paths = append(paths, p)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return digestFiles(paths, tm.ReadFile)
+}
+
+// digestFiles hashes files given by slash-separated relative paths. Paths are
+// sorted first and only paths and contents are hashed, so the digest does not
+// depend on walk order, timestamps or modes.
+func digestFiles(paths []string, read func(string) ([]byte, error)) (string, error) {
+	sorted := append([]string(nil), paths...)
+	sort.Strings(sorted)
+	h := sha256.New()
+	for _, p := range sorted {
+		data, err := read(p)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", p, len(data))
+		h.Write(data)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// lockExcluded reports whether a managed path stays out of the lock.
+// Personal override files are meant to be edited by each developer, and the
+// provenance file is derived from the lock.
+func lockExcluded(rel string) bool {
+	return rel == lockFile || rel == provenanceFile || isLocalOverride(rel)
+}
+
+// lockPathValid reports whether a path of the lock is a clean, relative,
+// slash-separated path inside the target and outside its .git. The lock is
+// committed with the project, so paths such as ../elsewhere must never reach
+// prune or check.
+func lockPathValid(rel string) bool {
+	if rel == "" || rel != path.Clean(rel) || rel == "." || path.IsAbs(rel) || strings.ContainsAny(rel, `\:`) {
+		return false
+	}
+	top, _, _ := strings.Cut(rel, "/")
+	return top != ".." && top != ".git"
+}
+
+// readLock reads the lockfile of the target; a missing lock is returned as
+// an error satisfying os.IsNotExist, and one naming a path outside the
+// target is refused
+func (e *Engine) readLock() (*Lock, error) {
+	data, err := e.fs.ReadFile(filepath.Join(e.config.TargetDir, filepath.FromSlash(lockFile)))
+	if err != nil {
+		return nil, err
+	}
+	var lock Lock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", lockFile, err)
+	}
+	for rel := range lock.Files {
+		if !lockPathValid(rel) {
+			return nil, fmt.Errorf("invalid %s: %q is not a relative path inside the target", lockFile, rel)
+		}
+	}
+	if lock.Files == nil {
+		lock.Files = map[string]string{}
+	}
+	return &lock, nil
+}
+
+// writeLock records the files of this run in the lockfile, keeping entries
+// of earlier runs that this one did not touch
+func (e *Engine) writeLock() error {
+	if e.config.DryRun {
+		return nil
+	}
+	digest, err := e.tmpl.Digest()
+	if err != nil {
+		return err
+	}
+
+	lock := &Lock{Files: map[string]string{}}
+	if existing, err := e.readLock(); err == nil {
+		lock = existing
+	}
+	lock.Version = version
+	lock.Set = e.templateSet
+	lock.PackVersion = ""
+	if e.manifest != nil {
+		lock.PackVersion = e.manifest.Version
+	}
+	lock.Templates = digest
+	for _, p := range e.pruned {
+		delete(lock.Files, p)
+	}
+	for _, rec := range e.stats.Records {
+		if rec.IsDir || rec.Action == ActionFailed || rec.Action == ActionRemoved || lockExcluded(rec.Path) || filepath.IsAbs(rec.Path) {
+			continue
+		}
+		data, err := e.fs.ReadFile(filepath.Join(e.config.TargetDir, filepath.FromSlash(rec.Path)))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", rec.Path, err)
+		}
+		lock.Files[rec.Path] = contentDigest(data)
+	}
+
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	lockPath := filepath.Join(e.config.TargetDir, filepath.FromSlash(lockFile))
+	return e.updateFile(lockPath, 0644, func(existing []byte) ([]byte, error) {
+		return append(data, '\n'), nil
+	})
+}
+
+// Drift kinds reported by checkLock
+const (
+	DriftModified = "modified"
+	DriftMissing  = "missing"
+	DriftOutdated = "outdated"
+)
+
+// LockDrift is one difference between the lock and the target. Added and
+// Removed count changed lines against the template the file came from.
+type LockDrift struct {
+	Path    string
+	Kind    string
+	Added   int
+	Removed int
+}
+
+// checkLock compares the target with its lockfile and the templates of this
+// binary; it returns nothing when they all match
+func (e *Engine) checkLock() ([]LockDrift, error) {
+	lock, err := e.readLock()
+	if err != nil {
+		return nil, err
+	}
+	digest, err := e.tmpl.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	var drift []LockDrift
+	if lock.Templates != digest {
+		drift = append(drift, LockDrift{Path: lockFile, Kind: DriftOutdated})
+	}
+
+	paths := make([]string, 0, len(lock.Files))
+	for p := range lock.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		data, err := e.fs.ReadFile(filepath.Join(e.config.TargetDir, filepath.FromSlash(p)))
+		if err != nil {
+			drift = append(drift, LockDrift{Path: p, Kind: DriftMissing})
+			continue
+		}
+		if contentDigest(data) == lock.Files[p] {
+			continue
+		}
+		d := LockDrift{Path: p, Kind: DriftModified}
+		if rel, ok := templateSource(p); ok {
+			if template, err := e.tmpl.ReadFile(rel); err == nil {
+				for _, line := range diffLines(splitLines(string(template)), splitLines(string(data))) {
+					switch line.Kind {
+					case '+':
+						d.Added++
+					case '-':
+						d.Removed++
+					}
+				}
+			}
+		}
+		drift = append(drift, d)
+	}
+	return drift, nil
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 // ANSI color codes
@@ -16,109 +20,296 @@ const (
 	ColorGray   = "\033[90m"
 )
 
+// Log output formats accepted by --log-format
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// logFormats lists the accepted --log-format values
+var logFormats = []string{LogFormatText, LogFormatJSON}
+
+// Log levels accepted by --log-level, from most to least verbose
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// logLevels lists the accepted --log-level values in increasing severity
+var logLevels = []string{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError}
+
+// logLevelRank orders a level name by severity; unknown names rank as info
+func logLevelRank(level string) int {
+	for i, l := range logLevels {
+		if l == level {
+			return i
+		}
+	}
+	return 1
+}
+
 // Logger handles formatted output with optional colors
 type Logger struct {
-	verbose bool
+	level   int
 	noColor bool
 	writer  io.Writer
+	format  string
+	file    io.Writer
+	theme   Theme
+	linkDir string
+}
+
+// LogEvent is one line of --log-format=json output
+type LogEvent struct {
+	Time    string `json:"timestamp"`
+	Level   string `json:"level"`
+	Message string `json:"msg"`
+	Path    string `json:"path,omitempty"`
+	Action  string `json:"action,omitempty"`
+	Diff    string `json:"diff,omitempty"`
 }
 
 // NewLogger creates a new Logger instance
 func NewLogger(verbose, noColor bool) *Logger {
 	return &Logger{
-		verbose: verbose,
+		level:   verboseLevel(verbose),
 		noColor: noColor,
 		writer:  os.Stdout,
+		format:  LogFormatText,
+		theme:   defaultTheme,
 	}
 }
 
 // NewLoggerWithWriter creates a new Logger with a custom writer
 func NewLoggerWithWriter(verbose, noColor bool, writer io.Writer) *Logger {
 	return &Logger{
-		verbose: verbose,
+		level:   verboseLevel(verbose),
 		noColor: noColor,
 		writer:  writer,
+		format:  LogFormatText,
+		theme:   defaultTheme,
+	}
+}
+
+// verboseLevel maps the verbose shortcut to a level rank
+func verboseLevel(verbose bool) int {
+	if verbose {
+		return logLevelRank(LogLevelDebug)
+	}
+	return logLevelRank(LogLevelInfo)
+}
+
+// SetLevel sets the minimum level of events that are logged
+func (l *Logger) SetLevel(level string) {
+	l.level = logLevelRank(level)
+}
+
+// validateLogLevel checks the --log-level value
+func validateLogLevel(level string) error {
+	for _, l := range logLevels {
+		if level == l {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log level %q (expected one of: %s)", level, strings.Join(logLevels, ", "))
+}
+
+// SetFormat selects text or JSON lines output
+func (l *Logger) SetFormat(format string) {
+	l.format = format
+}
+
+// SetTheme sets the symbols and colors of text output
+func (l *Logger) SetTheme(theme Theme) {
+	l.theme = theme
+}
+
+// SetHyperlinks makes file paths in console output clickable; relative
+// paths are resolved against dir
+func (l *Logger) SetHyperlinks(dir string) {
+	l.linkDir = dir
+}
+
+// linkPath returns message with its trailing path turned into a hyperlink
+// when hyperlinks are enabled
+func (l *Logger) linkPath(message, path string) string {
+	if l.linkDir == "" || path == "" || !strings.HasSuffix(message, path) {
+		return message
+	}
+	target := path
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(l.linkDir, filepath.FromSlash(path))
 	}
+	return strings.TrimSuffix(message, path) + hyperlink(fileURI(target), path)
+}
+
+// SetFile copies every log event, uncolored, to w
+func (l *Logger) SetFile(w io.Writer) {
+	l.file = w
+}
+
+// validateLogFormat checks the --log-format value
+func validateLogFormat(format string) error {
+	for _, f := range logFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log format %q (expected one of: %s)", format, strings.Join(logFormats, ", "))
 }
 
 // colorize adds color to text if colors are enabled
 func (l *Logger) colorize(color, text string) string {
-	if l.noColor {
+	if l.noColor || color == "" {
 		return text
 	}
 	return color + text + ColorReset
 }
 
+// emit writes one event to the console writer w and the log file. style is
+// the text-mode marker (no symbol for none) and its color.
+func (l *Logger) emit(w io.Writer, level string, style ThemeStyle, message, path, action string) {
+	if logLevelRank(level) < l.level {
+		return
+	}
+	if l.format == LogFormatJSON {
+		line, _ := json.Marshal(LogEvent{
+			Time:    time.Now().UTC().Format(time.RFC3339),
+			Level:   level,
+			Message: message,
+			Path:    path,
+			Action:  action,
+		})
+		line = append(line, '\n')
+		w.Write(line)
+		if l.file != nil {
+			l.file.Write(line)
+		}
+		return
+	}
+
+	plain := "  " + message
+	text := "  " + l.linkPath(message, path)
+	if style.Symbol != "" {
+		plain = style.Symbol + " " + message
+		text = l.colorize(style.code(), style.Symbol) + " " + l.linkPath(message, path)
+	}
+	fmt.Fprintln(w, text)
+	if l.file != nil {
+		fmt.Fprintln(l.file, plain)
+	}
+}
+
+// Blank writes an empty separator line in text mode
+func (l *Logger) Blank() {
+	if l.format != LogFormatJSON && l.level <= logLevelRank(LogLevelInfo) {
+		fmt.Fprintln(l.writer)
+	}
+}
+
 // Success logs a success message
 func (l *Logger) Success(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	prefix := l.colorize(ColorGreen, "✓")
-	fmt.Fprintf(l.writer, "%s %s\n", prefix, message)
+	l.emit(l.writer, "info", l.theme.Success, fmt.Sprintf(tr(format), args...), "", "")
 }
 
 // Info logs an informational message
 func (l *Logger) Info(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	fmt.Fprintf(l.writer, "  %s\n", message)
+	l.emit(l.writer, "info", ThemeStyle{}, fmt.Sprintf(tr(format), args...), "", "")
 }
 
 // Warning logs a warning message
 func (l *Logger) Warning(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	prefix := l.colorize(ColorYellow, "⚠")
-	fmt.Fprintf(l.writer, "%s %s\n", prefix, message)
+	l.emit(l.writer, "warn", l.theme.Warning, fmt.Sprintf(tr(format), args...), "", "")
 }
 
 // Error logs an error message
 func (l *Logger) Error(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	prefix := l.colorize(ColorRed, "✗")
-	fmt.Fprintf(os.Stderr, "%s %s\n", prefix, message)
+	w := io.Writer(os.Stderr)
+	if l.format == LogFormatJSON {
+		// Keep the JSON stream in one place for log shippers
+		w = l.writer
+	}
+	l.emit(w, "error", l.theme.Error, fmt.Sprintf(tr(format), args...), "", "")
 }
 
-// Debug logs a debug message if verbose mode is enabled
+// Debug logs a debug message at the debug level
 func (l *Logger) Debug(format string, args ...interface{}) {
-	if !l.verbose {
-		return
-	}
-	message := fmt.Sprintf(format, args...)
-	prefix := l.colorize(ColorGray, "[DEBUG]")
-	fmt.Fprintf(l.writer, "%s %s\n", prefix, message)
+	l.emit(l.writer, "debug", l.theme.Debug, fmt.Sprintf(tr(format), args...), "", "")
 }
 
-// Verbose logs a message only if verbose mode is enabled
+// Verbose logs a progress message at the debug level
 func (l *Logger) Verbose(format string, args ...interface{}) {
-	if !l.verbose {
+	l.emit(l.writer, "debug", l.theme.Progress, fmt.Sprintf(tr(format), args...), "", "")
+}
+
+// Diff shows the change from old to new content of path as a unified diff,
+// colored unless colors are disabled
+func (l *Logger) Diff(path string, old, new []byte) {
+	plain := renderUnifiedDiff(path, old, new, false)
+	if plain == "" || logLevelRank(LogLevelInfo) < l.level {
+		return
+	}
+	if l.format == LogFormatJSON {
+		line, _ := json.Marshal(LogEvent{
+			Time:    time.Now().UTC().Format(time.RFC3339),
+			Level:   LogLevelInfo,
+			Message: "Diff: " + path,
+			Path:    path,
+			Diff:    plain,
+		})
+		line = append(line, '\n')
+		l.writer.Write(line)
+		if l.file != nil {
+			l.file.Write(line)
+		}
 		return
 	}
-	message := fmt.Sprintf(format, args...)
-	prefix := l.colorize(ColorBlue, "→")
-	fmt.Fprintf(l.writer, "%s %s\n", prefix, message)
+	io.WriteString(l.writer, renderUnifiedDiff(path, old, new, !l.noColor))
+	if l.file != nil {
+		io.WriteString(l.file, plain)
+	}
+}
+
+// fileEvent logs a file or directory operation with its path and action
+func (l *Logger) fileEvent(style ThemeStyle, message, path, action string) {
+	l.emit(l.writer, "info", style, tr(message)+": "+path, path, action)
 }
 
 // FileCreated logs a file creation
 func (l *Logger) FileCreated(path string) {
-	l.Success("Created file: %s", path)
+	l.fileEvent(l.theme.Success, "Created file", path, ActionCreated)
 }
 
 // FileSkipped logs a skipped file
 func (l *Logger) FileSkipped(path string) {
-	l.Info("Skipped existing file: %s", path)
+	l.fileEvent(ThemeStyle{}, "Skipped existing file", path, ActionSkipped)
+}
+
+// FileUpdated logs an update to an existing file
+func (l *Logger) FileUpdated(path string) {
+	l.fileEvent(l.theme.Success, "Updated file", path, ActionUpdated)
+}
+
+// FileRemoved logs the removal of a file
+func (l *Logger) FileRemoved(path string) {
+	l.fileEvent(l.theme.Warning, "Removed file", path, ActionRemoved)
 }
 
 // DirCreated logs a directory creation
 func (l *Logger) DirCreated(path string) {
-	l.Success("Created directory: %s", path)
+	l.fileEvent(l.theme.Success, "Created directory", path, ActionCreated)
 }
 
 // DirSkipped logs a skipped directory
 func (l *Logger) DirSkipped(path string) {
-	l.Info("Skipped existing directory: %s", path)
+	l.fileEvent(ThemeStyle{}, "Skipped existing directory", path, ActionSkipped)
 }
 
 // Summary logs a summary of operations
 func (l *Logger) Summary(created, skipped int) {
-	l.writer.Write([]byte("\n"))
+	l.Blank()
 	
 	if created > 0 {
 		l.Success("Created %d %s", created, pluralize("item", created))
@@ -2,15 +2,71 @@ package main
 
 import (
 	"embed"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/exec"
+	"time"
 )
 
-//go:embed .claude/*
-var templateFS embed.FS
+// The templates live in .claude and sets/ and are embedded by
+// templates_embed.go, or packed into templates.tar.gz for binaries built with
+// -tags compressed. `go generate` refreshes them from the upstream pinned in
+// templates.upstream.json, if any, then packs them.
+//
+//go:generate go run ./tools/synctemplates
+//go:generate go run ./tools/packtemplates
+
+// presetFS holds optional scaffolding (hook scripts, etc.) selected by flags
+//
+//go:embed presets
+var presetFS embed.FS
 
 func main() {
+	start := time.Now()
+	applyUserLocale()
+
+	// Aliases of the user configuration stand for their arguments
+	args, err := expandAlias(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+		os.Exit(1)
+	}
+	os.Args = append(os.Args[:1], args...)
+
+	// Dispatch subcommands before parsing the init flags
+	if len(os.Args) > 1 {
+		if cmd := findCommand(os.Args[1]); cmd != nil {
+			err := cmd.Run(os.Args[2:])
+			if cmd.Name != "telemetry" {
+				sendTelemetry(cmd.Name, time.Since(start), err)
+			}
+			if errors.Is(err, flag.ErrHelp) {
+				os.Exit(0)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+				os.Exit(exitCode(err))
+			}
+			os.Exit(0)
+		}
+		if plugin := findPlugin(os.Args[1]); plugin != "" {
+			err := runPlugin(plugin, os.Args[2:])
+			sendTelemetry("plugin", time.Since(start), err)
+			// The plugin reports its own errors
+			var exit *exec.ExitError
+			if errors.As(err, &exit) {
+				os.Exit(exit.ExitCode())
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+				os.Exit(ExitError)
+			}
+			os.Exit(0)
+		}
+	}
+
 	// Parse command-line flags
 	config := parseFlags()
 
@@ -28,14 +84,34 @@ func main() {
 
 	// Validate configuration
 	if err := validateConfig(config); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
 		os.Exit(1)
 	}
 
+	// Re-apply a template pack under development until interrupted
+	if config.Watch {
+		if err := runWatch(templateFiles(), config); err != nil {
+			fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
 	// Create and run the engine
-	engine := NewEngine(templateFS, config)
-	if err := engine.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	engine, err := NewEngine(templateFiles(), config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
 		os.Exit(1)
 	}
+	err = engine.Run()
+	if closeErr := engine.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && config.Submodules {
+		err = runSubmodules(templateFiles(), config)
+	}
+	sendTelemetry("init", time.Since(start), err)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+		os.Exit(exitCode(err))
+	}
 }
\ No newline at end of file
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// managedSettingsEnv names the environment variable that points at the
+// managed settings file when it is not in the standard location
+const managedSettingsEnv = "CC_INIT_MANAGED_SETTINGS"
+
+// Modes of --managed-settings
+const (
+	ManagedWarn  = "warn"
+	ManagedStrip = "strip"
+	ManagedOff   = "off"
+)
+
+// validateManagedMode checks the --managed-settings value
+func validateManagedMode(mode string) error {
+	switch mode {
+	case "", ManagedWarn, ManagedStrip, ManagedOff:
+		return nil
+	default:
+		return fmt.Errorf("unknown --managed-settings mode %q (expected %s, %s or %s)", mode, ManagedWarn, ManagedStrip, ManagedOff)
+	}
+}
+
+// managedSettingsPaths returns where administrators install the managed
+// settings file on this OS, newest location first
+func managedSettingsPaths() []string {
+	if path := os.Getenv(managedSettingsEnv); path != "" {
+		return []string{path}
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"/Library/Application Support/ClaudeCode/managed-settings.json"}
+	case "windows":
+		return []string{`C:\Program Files\ClaudeCode\managed-settings.json`, `C:\ProgramData\ClaudeCode\managed-settings.json`}
+	}
+	return []string{"/etc/claude-code/managed-settings.json"}
+}
+
+// loadManagedSettings reads the managed settings of this machine and
+// returns them with their path, or nil when there are none
+func loadManagedSettings() (Settings, string, error) {
+	for _, path := range managedSettingsPaths() {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read managed settings: %w", err)
+		}
+		settings, err := ParseSettings(data)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid managed settings %s: %w", path, err)
+		}
+		return settings, path, nil
+	}
+	return nil, "", nil
+}
+
+// ManagedOverride is a project setting that managed settings take away:
+// the value at Path, or only Rule of the permission list at Path
+type ManagedOverride struct {
+	Path   string
+	Rule   string
+	Reason string
+}
+
+// String describes the override for a warning
+func (o ManagedOverride) String() string {
+	if o.Rule != "" {
+		return fmt.Sprintf("%s %q: %s", o.Path, o.Rule, o.Reason)
+	}
+	return fmt.Sprintf("%s: %s", o.Path, o.Reason)
+}
+
+// permissionLists are the rule lists of the permissions block, which Claude
+// Code merges across scopes instead of overriding
+var permissionLists = []string{"allow", "ask", "deny"}
+
+// managedOverrides returns the settings of project that managed takes away.
+// Managed settings win over every other scope: a scalar they also set is
+// replaced, objects are compared key by key and lists are merged, except
+// that a managed deny rule beats the same project allow rule and the
+// allowManaged*Only switches drop the project's rules and hooks entirely.
+func managedOverrides(project, managed Settings) []ManagedOverride {
+	var overrides []ManagedOverride
+	permissions, _ := project["permissions"].(map[string]interface{})
+	managedPermissions, _ := managed["permissions"].(map[string]interface{})
+
+	if managed["allowManagedPermissionRulesOnly"] == true {
+		for _, list := range permissionLists {
+			if rules, _ := permissions[list].([]interface{}); len(rules) > 0 {
+				overrides = append(overrides, ManagedOverride{Path: "permissions." + list, Reason: "ignored; managed settings allow only their own permission rules"})
+			}
+		}
+	} else {
+		denied := map[interface{}]bool{}
+		managedDeny, _ := managedPermissions["deny"].([]interface{})
+		for _, rule := range managedDeny {
+			denied[rule] = true
+		}
+		allow, _ := permissions["allow"].([]interface{})
+		for _, rule := range allow {
+			if denied[rule] {
+				overrides = append(overrides, ManagedOverride{Path: "permissions.allow", Rule: fmt.Sprint(rule), Reason: "denied by managed settings"})
+			}
+		}
+	}
+	if managed["allowManagedHooksOnly"] == true && project["hooks"] != nil {
+		overrides = append(overrides, ManagedOverride{Path: "hooks", Reason: "ignored; managed settings allow only their own hooks"})
+	}
+
+	var compare func(path string, ours, theirs interface{})
+	compare = func(path string, ours, theirs interface{}) {
+		oursMap, oursIsMap := ours.(map[string]interface{})
+		theirsMap, theirsIsMap := theirs.(map[string]interface{})
+		if oursIsMap && theirsIsMap {
+			for _, key := range sortedKeys(oursMap) {
+				if value, ok := theirsMap[key]; ok {
+					compare(path+"."+key, oursMap[key], value)
+				}
+			}
+			return
+		}
+		if _, isList := ours.([]interface{}); isList {
+			return
+		}
+		if !sameJSON(ours, theirs) {
+			overrides = append(overrides, ManagedOverride{Path: path, Reason: "managed settings set " + formatJSONValue(theirs)})
+		}
+	}
+	for _, key := range sortedKeys(project) {
+		value, ok := managed[key]
+		if !ok || (key == "hooks" && managed["allowManagedHooksOnly"] == true) {
+			continue
+		}
+		compare(key, project[key], value)
+	}
+	return overrides
+}
+
+// sortedKeys returns the keys of an object in order, for stable output
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sameJSON reports whether two decoded JSON values are equal
+func sameJSON(a, b interface{}) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	return errX == nil && errY == nil && string(x) == string(y)
+}
+
+// stripOverrides removes the overridden settings from settings, leaving only
+// those the project still controls
+func stripOverrides(settings Settings, overrides []ManagedOverride) {
+	for _, o := range overrides {
+		keys := strings.Split(o.Path, ".")
+		parent := map[string]interface{}(settings)
+		for _, key := range keys[:len(keys)-1] {
+			parent, _ = parent[key].(map[string]interface{})
+		}
+		if parent == nil {
+			continue
+		}
+		last := keys[len(keys)-1]
+		if o.Rule == "" {
+			delete(parent, last)
+			continue
+		}
+		rules, _ := parent[last].([]interface{})
+		kept := rules[:0]
+		for _, rule := range rules {
+			if fmt.Sprint(rule) != o.Rule {
+				kept = append(kept, rule)
+			}
+		}
+		parent[last] = kept
+	}
+}
+
+// loadManagedForTarget returns the managed settings that apply to the
+// target, or nil when --managed-settings is off or the target is remote,
+// where this machine's policy does not apply
+func (e *Engine) loadManagedForTarget() (Settings, string, error) {
+	if e.config.ManagedSettings == ManagedOff || e.config.Remote != nil {
+		return nil, "", nil
+	}
+	return loadManagedSettings()
+}
+
+// stripManagedSettings removes from settings what the managed settings
+// override when --managed-settings is strip
+func (e *Engine) stripManagedSettings(settings Settings) error {
+	if e.config.ManagedSettings != ManagedStrip {
+		return nil
+	}
+	managed, path, err := e.loadManagedForTarget()
+	if err != nil || managed == nil {
+		return err
+	}
+	overrides := managedOverrides(settings, managed)
+	stripOverrides(settings, overrides)
+	for _, o := range overrides {
+		e.logger.Info("Left out %s (%s)", o, filepath.ToSlash(path))
+	}
+	return nil
+}
+
+// checkManagedSettings warns about the settings of the target that the
+// managed settings of this machine override
+func (e *Engine) checkManagedSettings() error {
+	managed, managedPath, err := e.loadManagedForTarget()
+	if err != nil || managed == nil {
+		return err
+	}
+	for _, path := range e.settingsFilesToValidate() {
+		data, err := e.fs.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		settings, err := ParseSettings(data)
+		if err != nil {
+			continue
+		}
+		for _, o := range managedOverrides(settings, managed) {
+			e.logger.Warning("%s: %s (%s)", e.formatPath(path), o, filepath.ToSlash(managedPath))
+		}
+	}
+	return nil
+}
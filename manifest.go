@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// packManifestFile describes a template pack; it sits at the root of the
+// pack and is never installed
+const packManifestFile = "pack.yaml"
+
+// PackManifest is the content of pack.yaml
+type PackManifest struct {
+	// Name identifies the pack, e.g. acme-go
+	Name string `yaml:"name"`
+	// Description is a one-line summary shown by cc-init serve
+	Description string `yaml:"description"`
+	// Version is the version of the pack itself, e.g. 1.2.0
+	Version string `yaml:"version"`
+	// WASM lists WebAssembly modules in the pack, relative to its root, that
+	// provide template functions or a conflict policy; they are not installed
+	WASM []string `yaml:"wasm"`
+	// Variables are asked for, or given with --var, and available to the
+	// templates of the pack
+	Variables []PackVariable `yaml:"variables"`
+	// Changelog lists the changes of each version, newest first or in any
+	// order; `cc-init changelog` shows those an update brings
+	Changelog []ChangelogEntry `yaml:"changelog"`
+}
+
+// ChangelogEntry describes one version of a pack in pack.yaml
+type ChangelogEntry struct {
+	Version string   `yaml:"version"`
+	Date    string   `yaml:"date"`
+	Changes []string `yaml:"changes"`
+}
+
+// loadPackManifest reads pack.yaml from a template pack; packs without one,
+// like the embedded templates, yield nil
+func loadPackManifest(pack fs.FS) (*PackManifest, error) {
+	data, err := fs.ReadFile(pack, packManifestFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest PackManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", packManifestFile, err)
+	}
+	return &manifest, nil
+}
+
+// packOnly reports whether p, a path in the pack, belongs to the pack rather
+// than the project: the manifest and the WASM modules it lists
+func (m *PackManifest) packOnly(p string) bool {
+	if p == packManifestFile {
+		return true
+	}
+	if m == nil {
+		return false
+	}
+	for _, module := range m.WASM {
+		if path.Clean(module) == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// mcpFile is the project-level MCP server configuration at the target root
+const mcpFile = ".mcp.json"
+
+// mcpCatalog lists the MCP servers that can be selected with --mcp
+var mcpCatalog = map[string]map[string]interface{}{
+	"context7": {
+		"type":    "stdio",
+		"command": "npx",
+		"args":    []interface{}{"-y", "@upstash/context7-mcp"},
+	},
+	"fetch": {
+		"type":    "stdio",
+		"command": "uvx",
+		"args":    []interface{}{"mcp-server-fetch"},
+	},
+	"filesystem": {
+		"type":    "stdio",
+		"command": "npx",
+		"args":    []interface{}{"-y", "@modelcontextprotocol/server-filesystem", "."},
+	},
+	"github": {
+		"type": "http",
+		"url":  "https://api.githubcopilot.com/mcp/",
+	},
+	"playwright": {
+		"type":    "stdio",
+		"command": "npx",
+		"args":    []interface{}{"@playwright/mcp@latest"},
+	},
+	"sequential-thinking": {
+		"type":    "stdio",
+		"command": "npx",
+		"args":    []interface{}{"-y", "@modelcontextprotocol/server-sequential-thinking"},
+	},
+}
+
+// MCPConfig is a decoded .mcp.json document
+type MCPConfig map[string]interface{}
+
+// ParseMCPConfig decodes a .mcp.json document, treating empty input as an empty
+// object and tolerating JSONC comments and trailing commas
+func ParseMCPConfig(data []byte) (MCPConfig, error) {
+	config := MCPConfig{}
+	data = stripJSONC(data)
+	if len(bytes.TrimSpace(data)) == 0 {
+		return config, nil
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid MCP config JSON: %w", err)
+	}
+	return config, nil
+}
+
+// AddServer adds a server definition unless one with the same name already exists
+func (c MCPConfig) AddServer(name string, server map[string]interface{}) bool {
+	servers, _ := c["mcpServers"].(map[string]interface{})
+	if servers == nil {
+		servers = map[string]interface{}{}
+		c["mcpServers"] = servers
+	}
+	if _, exists := servers[name]; exists {
+		return false
+	}
+	servers[name] = server
+	return true
+}
+
+// Validate checks the structure of the mcpServers block
+func (c MCPConfig) Validate() error {
+	raw, ok := c["mcpServers"]
+	if !ok {
+		return nil
+	}
+	servers, ok := raw.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("mcpServers must be an object")
+	}
+
+	for name, value := range servers {
+		server, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("mcpServers.%s must be an object", name)
+		}
+
+		serverType := "stdio"
+		if t, ok := server["type"]; ok {
+			serverType, ok = t.(string)
+			if !ok {
+				return fmt.Errorf("mcpServers.%s.type must be a string", name)
+			}
+		}
+
+		switch serverType {
+		case "stdio":
+			if command, ok := server["command"].(string); !ok || command == "" {
+				return fmt.Errorf("mcpServers.%s: stdio servers require a command", name)
+			}
+			if args, ok := server["args"]; ok {
+				list, ok := args.([]interface{})
+				if !ok {
+					return fmt.Errorf("mcpServers.%s.args must be an array", name)
+				}
+				for _, arg := range list {
+					if _, ok := arg.(string); !ok {
+						return fmt.Errorf("mcpServers.%s.args must contain only strings", name)
+					}
+				}
+			}
+		case "http", "sse":
+			if url, ok := server["url"].(string); !ok || url == "" {
+				return fmt.Errorf("mcpServers.%s: %s servers require a url", name, serverType)
+			}
+		default:
+			return fmt.Errorf("mcpServers.%s: unknown server type %q", name, serverType)
+		}
+
+		if env, ok := server["env"]; ok {
+			vars, ok := env.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("mcpServers.%s.env must be an object", name)
+			}
+			for key, val := range vars {
+				if _, ok := val.(string); !ok {
+					return fmt.Errorf("mcpServers.%s.env.%s must be a string", name, key)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Marshal encodes the config as indented JSON with a trailing newline
+func (c MCPConfig) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode MCP config: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// validateMCPServers checks that every requested server is in the catalog
+func validateMCPServers(names []string) error {
+	for _, name := range names {
+		if _, ok := mcpCatalog[name]; !ok {
+			return fmt.Errorf("unknown MCP server %q (available: %s)", name, strings.Join(mcpServerNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// mcpServerNames returns the sorted names of the catalog servers
+func mcpServerNames() []string {
+	names := make([]string, 0, len(mcpCatalog))
+	for name := range mcpCatalog {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// generateMCPConfig merges the selected MCP servers into the project .mcp.json;
+// servers already defined in the file are left untouched
+func (e *Engine) generateMCPConfig() error {
+	if len(e.config.MCPServers) == 0 {
+		return nil
+	}
+
+	servers := map[string]map[string]interface{}{}
+	for _, name := range e.config.MCPServers {
+		servers[name] = mcpCatalog[name]
+	}
+	return e.addMCPServers(servers)
+}
+
+// addMCPServers merges servers into .mcp.json, keeping existing entries
+func (e *Engine) addMCPServers(servers map[string]map[string]interface{}) error {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	targetPath := filepath.Join(e.config.TargetDir, mcpFile)
+	return e.updateFile(targetPath, 0644, func(existing []byte) ([]byte, error) {
+		config, err := ParseMCPConfig(existing)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if !config.AddServer(name, servers[name]) {
+				e.logger.Debug("MCP server %s already configured, keeping existing entry", name)
+			}
+		}
+		if err := config.Validate(); err != nil {
+			return nil, err
+		}
+		if existing != nil && jsonEqual(existing, config) {
+			return existing, nil
+		}
+		data, err := config.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		data, dropped := preserveJSONComments(existing, data)
+		if dropped {
+			e.logger.Warning("Comments inside %s could not be preserved; only leading comments were kept", e.formatPath(targetPath))
+		}
+		return data, nil
+	})
+}
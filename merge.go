@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conflict markers written around the two sides of an unresolved hunk, as
+// git writes them
+const (
+	markerOurs   = "<<<<<<< local"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>> template"
+)
+
+// Strategies of --conflict for the hunks a merge cannot settle
+const (
+	ConflictOurs    = "ours"
+	ConflictTheirs  = "theirs"
+	ConflictUnion   = "union"
+	ConflictMarkers = "markers"
+)
+
+// validateConflictStrategy checks the --conflict value; empty means ask in a
+// terminal and skip the file otherwise
+func validateConflictStrategy(strategy string) error {
+	switch strategy {
+	case "", ConflictOurs, ConflictTheirs, ConflictUnion, ConflictMarkers:
+		return nil
+	default:
+		return fmt.Errorf("unknown --conflict strategy %q (expected %s, %s, %s or %s)", strategy, ConflictOurs, ConflictTheirs, ConflictUnion, ConflictMarkers)
+	}
+}
+
+// mergeChunk is a run of merged lines, or a conflict between the local lines
+// and the template lines; Base holds the lines both sides started from
+type mergeChunk struct {
+	Conflict bool
+	Lines    []string
+	Ours     []string
+	Theirs   []string
+	Base     []string
+}
+
+// baseMatches maps each line of base to its line in other when the diff of
+// the two keeps it, or -1
+func baseMatches(base, other []string) []int {
+	matches := make([]int, len(base))
+	i, j := 0, 0
+	for _, line := range diffLines(base, other) {
+		switch line.Kind {
+		case ' ':
+			matches[i] = j
+			i++
+			j++
+		case '-':
+			matches[i] = -1
+			i++
+		case '+':
+			j++
+		}
+	}
+	return matches
+}
+
+// equalLines reports whether two line slices are the same
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// merge3 merges the local and template changes made to base. Between lines
+// both sides kept, a region changed on one side only takes that change, and
+// a region both changed differently is a conflict.
+func merge3(base, ours, theirs []string) []mergeChunk {
+	var chunks []mergeChunk
+	take := func(lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		if n := len(chunks); n > 0 && !chunks[n-1].Conflict {
+			chunks[n-1].Lines = append(chunks[n-1].Lines, lines...)
+			return
+		}
+		chunks = append(chunks, mergeChunk{Lines: append([]string(nil), lines...)})
+	}
+
+	matchOurs, matchTheirs := baseMatches(base, ours), baseMatches(base, theirs)
+	i, o, t := 0, 0, 0
+	for {
+		// The next base line kept by both sides anchors the region before it
+		k := i
+		for k < len(base) && (matchOurs[k] < 0 || matchTheirs[k] < 0) {
+			k++
+		}
+		oEnd, tEnd := len(ours), len(theirs)
+		if k < len(base) {
+			oEnd, tEnd = matchOurs[k], matchTheirs[k]
+		}
+		b, x, y := base[i:k], ours[o:oEnd], theirs[t:tEnd]
+		switch {
+		case equalLines(x, b):
+			take(y)
+		case equalLines(y, b), equalLines(x, y):
+			take(x)
+		default:
+			chunks = append(chunks, mergeChunk{Conflict: true, Ours: x, Theirs: y, Base: b})
+		}
+		if k == len(base) {
+			return chunks
+		}
+		take(base[k : k+1])
+		i, o, t = k+1, oEnd+1, tEnd+1
+	}
+}
+
+// merge2 compares the local and template versions without a common base:
+// lines they share are kept and every hunk where they differ is a conflict
+func merge2(ours, theirs []string) []mergeChunk {
+	var chunks []mergeChunk
+	lines := diffLines(ours, theirs)
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == ' ' {
+			var same []string
+			for ; i < len(lines) && lines[i].Kind == ' '; i++ {
+				same = append(same, lines[i].Text)
+			}
+			chunks = append(chunks, mergeChunk{Lines: same})
+			continue
+		}
+		chunk := mergeChunk{Conflict: true}
+		for ; i < len(lines) && lines[i].Kind != ' '; i++ {
+			if lines[i].Kind == '-' {
+				chunk.Ours = append(chunk.Ours, lines[i].Text)
+			} else {
+				chunk.Theirs = append(chunk.Theirs, lines[i].Text)
+			}
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// settleConflicts resolves every conflict of a merge by strategy: ours keeps
+// the local lines, theirs takes the template lines and union keeps both,
+// local first. Other strategies leave the conflicts in place.
+func settleConflicts(chunks []mergeChunk, strategy string) []mergeChunk {
+	settled := make([]mergeChunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.Conflict {
+			var lines []string
+			switch strategy {
+			case ConflictOurs:
+				lines = chunk.Ours
+			case ConflictTheirs:
+				lines = chunk.Theirs
+			case ConflictUnion:
+				lines = append(append([]string(nil), chunk.Ours...), chunk.Theirs...)
+			default:
+				settled = append(settled, chunk)
+				continue
+			}
+			chunk = mergeChunk{Lines: lines}
+		}
+		settled = append(settled, chunk)
+	}
+	return settled
+}
+
+// countConflicts returns the number of conflicting hunks in a merge
+func countConflicts(chunks []mergeChunk) int {
+	n := 0
+	for _, chunk := range chunks {
+		if chunk.Conflict {
+			n++
+		}
+	}
+	return n
+}
+
+// renderMerge joins merged chunks into a document, writing conflicts between
+// conflict markers
+func renderMerge(chunks []mergeChunk) string {
+	var lines []string
+	for _, chunk := range chunks {
+		if !chunk.Conflict {
+			lines = append(lines, chunk.Lines...)
+			continue
+		}
+		lines = append(lines, markerOurs)
+		lines = append(lines, chunk.Ours...)
+		lines = append(lines, markerSep)
+		lines = append(lines, chunk.Theirs...)
+		lines = append(lines, markerTheirs)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// hasConflictMarkers reports whether a document still holds conflict markers
+func hasConflictMarkers(doc string) bool {
+	for _, line := range splitLines(strings.ReplaceAll(doc, "\r\n", "\n")) {
+		if line == markerOurs || line == markerTheirs {
+			return true
+		}
+	}
+	return false
+}
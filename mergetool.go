@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// mergeToolEnv sets the merge tool when --merge-tool is not given
+const mergeToolEnv = "CC_INIT_MERGE_TOOL"
+
+// interactiveMerge reports whether conflicts can be resolved with the user:
+// a local target and a terminal that cc-init may prompt on
+func (e *Engine) interactiveMerge() bool {
+	return e.config.Remote == nil && !e.config.DryRun && !e.config.CI && !e.config.Yes && stdinIsTerminal()
+}
+
+// mergeToolCommand returns the command that resolves conflicts: --merge-tool
+// (default $CC_INIT_MERGE_TOOL), else $VISUAL, else $EDITOR
+func (e *Engine) mergeToolCommand() string {
+	for _, tool := range []string{e.config.MergeTool, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if strings.TrimSpace(tool) != "" {
+			return tool
+		}
+	}
+	return ""
+}
+
+// runMergeTool offers to open the merge tool on a file with conflict markers
+// and waits for it, asking again while markers remain. An answer in the
+// answers file replaces the question, and the tool is then opened only once.
+func (e *Engine) runMergeTool(targetPath string, base, ours, theirs []byte) error {
+	rel := filepath.ToSlash(e.formatPath(targetPath))
+	tool := e.mergeToolCommand()
+	if tool == "" {
+		e.logger.Info("Resolve the conflict markers in %s by hand, or set --merge-tool, $VISUAL or $EDITOR", rel)
+		return nil
+	}
+	open, answered := e.config.Answers.confirmMergeTool()
+	input := bufio.NewReader(os.Stdin)
+	for {
+		if !answered {
+			answer, err := promptVariable(os.Stderr, input, PackVariable{
+				Name:    "merge " + rel,
+				Type:    VarBool,
+				Help:    fmt.Sprintf(tr("Open %s with %s to resolve the conflicts?"), rel, tool),
+				Default: "yes",
+			})
+			if err != nil {
+				return err
+			}
+			open, _ = parseVarBool(answer)
+		}
+		if !open {
+			e.logger.Info("Resolve the conflict markers in %s by hand", rel)
+			return nil
+		}
+		if err := launchMergeTool(tool, e.config.TargetDir, targetPath, base, ours, theirs); err != nil {
+			e.logger.Warning("%s: %v", tool, err)
+		}
+		merged, err := e.fs.ReadFile(targetPath)
+		if err != nil {
+			return err
+		}
+		if !hasConflictMarkers(string(merged)) {
+			e.logger.Success("Resolved the conflicts in %s", rel)
+			return nil
+		}
+		e.logger.Warning("%s still has conflict markers", rel)
+		if answered {
+			// the answers file opens the tool once; it cannot be asked again
+			e.logger.Info("Resolve the conflict markers in %s by hand", rel)
+			return nil
+		}
+	}
+}
+
+// launchMergeTool runs tool the way git runs a merge.tool command: through
+// the shell, with $BASE, $LOCAL and $REMOTE naming temporary copies of the
+// base, local and template versions and $MERGED the file to resolve. The
+// file is appended when the command does not mention $MERGED.
+func launchMergeTool(tool, dir, merged string, base, ours, theirs []byte) error {
+	tmp, err := os.MkdirTemp("", "cc-init-merge-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	name := filepath.Base(merged)
+	env := []string{"MERGED=" + merged}
+	for _, side := range []struct {
+		label   string
+		content []byte
+	}{{"BASE", base}, {"LOCAL", ours}, {"REMOTE", theirs}} {
+		p := filepath.Join(tmp, side.label+"."+name)
+		if err := os.WriteFile(p, side.content, 0600); err != nil {
+			return err
+		}
+		env = append(env, side.label+"="+p)
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		if !strings.Contains(tool, "%MERGED%") {
+			tool += ` "%MERGED%"`
+		}
+		cmd = exec.Command("cmd", "/C", tool)
+	} else {
+		if !strings.Contains(tool, "MERGED") {
+			tool += ` "$MERGED"`
+		}
+		cmd = exec.Command("sh", "-c", tool)
+	}
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,401 @@
+package main
+
+// zhCNMessages is the Simplified Chinese message catalog
+var zhCNMessages = map[string]string{
+	// Usage and flags
+	"cc-init - Initialize Claude Code configuration\n\n": "cc-init - 初始化 Claude Code 配置\n\n",
+	"Usage: %s [flags]\n":                                "用法：%s [选项]\n",
+	"       %s <command> [args] [flags]\n":               "      %s <命令> [参数] [选项]\n",
+	"Usage: cc-init %s\n\n%s\n\nFlags:\n":                "用法：cc-init %s\n\n%s\n\n选项：\n",
+	"Flags:\n":                                           "选项：\n",
+	"\nExamples:\n":                                      "\n示例：\n",
+	"\nCommands:\n":                                      "\n命令：\n",
+	"\nPlugins:\n":                                       "\n插件：\n",
+	"\nAliases:\n":                                       "\n别名：\n",
+	"\nWorkflows:\n":                                     "\n工作流：\n",
+	"  %s                    # Initialize in current directory\n": "  %s                    # 在当前目录初始化\n",
+	"  %s -t ./myproject     # Initialize in ./myproject\n":       "  %s -t ./myproject     # 在 ./myproject 中初始化\n",
+	"  %s --dry-run          # Preview what would be created\n":   "  %s --dry-run          # 预览将要创建的内容\n",
+	"  %s -v                 # Show detailed output\n":            "  %s -v                 # 显示详细输出\n",
+	"  %s --report json      # Print a JSON summary\n":            "  %s --report json      # 输出 JSON 格式的摘要\n",
+	"\nPermission presets:\n":                                     "\n权限预设：\n",
+	"Permission rule groups: %s\n":                                "权限规则组：%s\n",
+	"MCP servers: %s\n":                                           "MCP 服务器：%s\n",
+	"Statusline styles:\n":                                        "状态栏样式：\n",
+	"Output styles:\n":                                            "输出样式：\n",
+	"Hook presets:\n":                                             "钩子预设：\n",
+	"List the packages of a go.work, pnpm, npm or Cargo workspace in CLAUDE.md and give each package its own CLAUDE.md":    "在 CLAUDE.md 中列出 go.work、pnpm、npm 或 Cargo 工作区的包，并为每个包生成自己的 CLAUDE.md",
+	"No workspace packages found; --workspaces reads go.work, pnpm-workspace.yaml, package.json workspaces and Cargo.toml": "未找到工作区包；--workspaces 读取 go.work、pnpm-workspace.yaml、package.json 的 workspaces 和 Cargo.toml",
+	"Also initialize the checked-out git submodules of the target":                                                         "同时初始化目标中已检出的 git 子模块",
+	"Initializing submodule %s":                                                                          "正在初始化子模块 %s",
+	"Using the lockfile of the main work tree %s":                                                        "使用主工作树 %s 的锁文件",
+	"Without -t, use the current directory instead of the git repository root":                           "未指定 -t 时使用当前目录而不是 git 仓库根目录",
+	"Using the git repository root %s; pass -t . or --no-git-root for the current directory":             "使用 git 仓库根目录 %s；传入 -t . 或 --no-git-root 以使用当前目录",
+	"Target directory (default: the git repository root, else the current directory)":                    "目标目录（默认：git 仓库根目录，否则为当前目录）",
+	"Target directory (shorthand)":                                                                       "目标目录（简写）",
+	"Preview operations without making changes":                                                          "预览操作而不做任何修改",
+	"Line endings of written files: lf, crlf or auto (.gitattributes, then OS)":                          "写入文件的换行符：lf、crlf 或 auto（先看 .gitattributes，再看操作系统）",
+	"Apply the umask to file modes; false sets modes exactly":                                            "对文件权限应用 umask；设为 false 时精确设置权限",
+	"Mode for matching paths as PATTERN=MODE, e.g. '*.sh=0700' (repeatable)":                             "为匹配的路径设置权限，格式为 PATTERN=MODE，例如 '*.sh=0700'（可重复）",
+	"Owner of created files as user[:group], e.g. when running as root":                                  "创建文件的所有者，格式为 user[:group]，例如以 root 运行时",
+	"Modification time of written files: release, RFC 3339 or Unix seconds (default $SOURCE_DATE_EPOCH)": "写入文件的修改时间：release、RFC 3339 或 Unix 秒数（默认为 $SOURCE_DATE_EPOCH）",
+	"Write the result to a .tar, .tar.gz or .zip archive instead of the target":                          "将结果写入 .tar、.tar.gz 或 .zip 归档，而不是写入目标目录",
+	"Write the changes as a patch for git apply instead of making them":                                  "将变更写成可供 git apply 使用的补丁，而不是直接修改",
+	"Patch written by --emit-patch or git diff":                                                          "由 --emit-patch 或 git diff 生成的补丁",
+	"Retries for file operations that fail with transient errors such as ESTALE or EIO":                  "因 ESTALE 或 EIO 等暂时性错误失败的文件操作的重试次数",
+	"Delay before the first retry; doubles after each attempt":                                           "首次重试前的等待时间；每次重试后加倍",
+	"Enable verbose output (same as --log-level debug)":                                                  "启用详细输出（等同于 --log-level debug）",
+	"Enable verbose output (shorthand)":                                                                  "启用详细输出（简写）",
+	"Enable verbose output with timing statistics":                                                       "启用详细输出并显示耗时统计",
+	"Minimum log level: ":                       "最低日志级别：",
+	" (default info)":                           "（默认 info）",
+	"Disable colored output":                    "禁用彩色输出",
+	"Use ASCII symbols instead of Unicode ones": "使用 ASCII 符号代替 Unicode 符号",
+	"JSON file overriding the symbols and colors of console output": "用于覆盖控制台输出符号和颜色的 JSON 文件",
+	"Show version information":                                      "显示版本信息",
+	"Summary format: ":                                              "摘要格式：",
+	"Append a JSON record of every change to this file (default $CC_INIT_AUDIT_LOG)":                                                            "将每次变更的 JSON 记录追加到此文件（默认为 $CC_INIT_AUDIT_LOG）",
+	"POST the JSON report to this URL when done (default $CC_INIT_NOTIFY_URL)":                                                                  "完成后将 JSON 报告 POST 到此 URL（默认为 $CC_INIT_NOTIFY_URL）",
+	"Also write the JSON summary to this file":                                                                                                  "同时将 JSON 摘要写入此文件",
+	"Upgrade every project listed under projects in the user configuration":                                                                     "升级用户配置中 projects 列出的所有项目",
+	"Only report which projects are behind, without updating them":                                                                              "仅报告哪些项目落后，不进行更新",
+	"Also update checkouts with uncommitted changes":                                                                                            "同时更新有未提交修改的检出",
+	"With clean, only remove packs last used longer ago than this, e.g. 30d or 12h":                                                             "配合 clean 使用，仅删除上次使用早于此时长的模板包，例如 30d 或 12h",
+	"With create, the bundle to write":                                                                                                          "配合 create 使用，要写入的捆绑包",
+	"With create, the bundle to write (shorthand)":                                                                                              "配合 create 使用，要写入的捆绑包（简写）",
+	"With create, the template pack to bundle: a directory laid out like .claude or the URL of cc-init serve (default: the embedded templates)": "配合 create 使用，要打包的模板包：按 .claude 布局的目录或 cc-init serve 的 URL（默认：内置模板）",
+	"With create, the embedded template set to bundle: ":                                                                                        "配合 create 使用，要打包的内置模板集：",
+	"With create, an Ed25519 private key in PEM to sign the bundle with":                                                                        "配合 create 使用，用于签名捆绑包的 PEM 格式 Ed25519 私钥",
+	"With install, the base64 Ed25519 public key to verify the bundle with (default: $CC_INIT_POLICY_KEY)":                                      "配合 install 使用，用于验证捆绑包的 base64 Ed25519 公钥（默认：$CC_INIT_POLICY_KEY）",
+	"With install, only store the templates instead of making them the default --template-dir":                                                  "配合 install 使用，仅存储模板，而不将其设为默认的 --template-dir",
+	"Project whose .cc-init.yaml to check or write (default: the git repository root, else the current directory)":                              "要检查或写入其 .cc-init.yaml 的项目（默认：git 仓库根目录，否则为当前目录）",
+	"With init, ask nothing and write the defaults":                                                                                             "配合 init 使用，不提问并写入默认内容",
+	"With init, replace an existing .cc-init.yaml":                                                                                              "配合 init 使用，替换已有的 .cc-init.yaml",
+	"Project whose .cc-init.yaml to check or write (shorthand)":                                                                                 "要检查或写入其 .cc-init.yaml 的项目（简写）",
+	"Automation defaults: plain output, JSON summary file, exit code 2 when --dry-run finds changes":                                            "自动化默认设置：纯文本输出、JSON 摘要文件，--dry-run 发现变更时退出码为 2",
+	"Go template for the summary, or @file; overrides --report":                                                                                 "摘要的 Go 模板或 @文件；优先于 --report",
+	"Log output format: ":                                                                                 "日志输出格式：",
+	"Also append log output to this file":                                                                 "同时将日志追加写入此文件",
+	"Permission rule to allow in settings.json, or @group (repeatable)":                                   "在 settings.json 中允许的权限规则或 @规则组（可重复）",
+	"Permission rule to deny in settings.json, or @group (repeatable)":                                    "在 settings.json 中拒绝的权限规则或 @规则组（可重复）",
+	"Permission policy preset: ":                                                                          "权限策略预设：",
+	"Comma-separated MCP servers to add to .mcp.json":                                                     "要添加到 .mcp.json 的 MCP 服务器（逗号分隔）",
+	"Comma-separated environment variables, or patterns such as ACME_*, that templates may read with env": "模板可通过 env 读取的环境变量或模式（如 ACME_*，逗号分隔）",
+	"Value of a template pack variable as NAME=VALUE (repeatable)":                                        "模板包变量的值，格式为 NAME=VALUE（可重复）",
+	"YAML file answering the questions of the template pack":                                              "回答模板包问题的 YAML 文件",
+	"Never ask; take defaults for questions not answered by --var or --answers":                           "从不提问；未由 --var 或 --answers 回答的问题使用默认值",
+	"Never ask (shorthand)":                                                                               "从不提问（简写）",
+	"Allow the template pack to compute variables by running commands":                                    "允许模板包运行命令来计算变量",
+	"Running %s for variable %s":                                                                          "正在为变量 %[2]s 运行 %[1]s",
+	"Command for variable %s failed, using the default %q: %v":                                            "变量 %s 的命令失败，使用默认值 %q：%v",
+	"  Choose":                "  请选择",
+	"  A value is required\n": "  必须填写一个值\n",
+	"Seed for uuid and randomToken in templates, for reproducible output":                                                                          "模板中 uuid 和 randomToken 的随机种子，用于可重现的输出",
+	"What to do when written content looks like a credential: warn, error (refuse the file) or off":                                                "写入内容疑似凭据时的处理方式：warn、error（拒绝写入该文件）或 off",
+	"What to do with settings that the managed settings of this machine override: warn, strip (leave them out of settings.json) or off":            "如何处理被本机托管设置覆盖的设置：warn（警告）、strip（不写入 settings.json）或 off（关闭）",
+	"Generate a project section in CLAUDE.md from repository analysis":                                                                             "根据仓库分析在 CLAUDE.md 中生成项目章节",
+	"Also maintain AGENTS.md: sync (mirror sections) or pointer (CLAUDE.md imports AGENTS.md)":                                                     "同时维护 AGENTS.md：sync（同步章节）或 pointer（CLAUDE.md 引用 AGENTS.md）",
+	"Also maintain AGENTS.md: sync or pointer":                                                                                                     "同时维护 AGENTS.md：sync 或 pointer",
+	"Generate .devcontainer/ configured for Claude Code":                                                                                           "生成为 Claude Code 配置的 .devcontainer/",
+	"Rewrite deprecated keys in .claude/settings.json to their current form":                                                                       "将 .claude/settings.json 中已弃用的配置项改写为当前格式",
+	"Remove managed files the templates no longer produce, after asking; removed files are backed up":                                              "询问后删除模板已不再生成的托管文件；删除的文件会被备份",
+	"Move files about to be overwritten into .claude/.backup/<run> instead of replacing them in place":                                             "将即将被覆盖的文件移入 .claude/.backup/<run>，而不是原地替换",
+	"Bring existing template files up to date, merging the template changes into files edited since":                                               "将已存在的模板文件更新到最新，并把模板变更合并到之后被编辑过的文件中",
+	"Command that resolves --update conflicts, with $BASE, $LOCAL, $REMOTE and $MERGED as for git (default $CC_INIT_MERGE_TOOL, $VISUAL, $EDITOR)": "解决 --update 冲突的命令，可像 git 一样使用 $BASE、$LOCAL、$REMOTE 和 $MERGED（默认 $CC_INIT_MERGE_TOOL、$VISUAL、$EDITOR）",
+	"How --update settles conflicting hunks: ours, theirs, union (both, local first) or markers (default: ask in a terminal, skip otherwise)":      "--update 处理冲突块的方式：ours、theirs、union（保留双方，本地在前）或 markers（默认：终端中询问，否则跳过）",
+	"Create example CLAUDE.local.md and settings.local.json and gitignore them":                                                                    "创建示例 CLAUDE.local.md 和 settings.local.json 并加入 .gitignore",
+	"Shell that templates render for: bash, zsh, fish or pwsh (default: detected from $SHELL)":                                                     "模板渲染所针对的 shell：bash、zsh、fish 或 pwsh（默认：根据 $SHELL 检测）",
+	"Hook scripts to install: auto (this OS), unix (bash), windows (PowerShell) or both":                                                           "要安装的钩子脚本：auto（当前操作系统）、unix（bash）、windows（PowerShell）或 both",
+	"Do not add the hook presets of the languages detected in the target":                                                                          "不要添加在目标中检测到的语言对应的钩子预设",
+	"Detected %s; adding the %s hook":              "检测到 %s；添加 %s 钩子",
+	"Framework preset to layer on the templates: ": "叠加在模板之上的框架预设：",
+	"Framework presets:\n":                         "框架预设：\n",
+	"Configurations of other assistants found in the target: ask (default), auto (convert them) or off": "目标中发现的其他助手配置：ask（默认，询问）、auto（自动转换）或 off",
+	"Found %s; pass --import=auto to convert it, or run cc-init import %s":                              "发现 %s；传入 --import=auto 进行转换，或运行 cc-init import %s",
+	"Found %s; convert it into the Claude configuration?":                                               "发现 %s；是否将其转换为 Claude 配置？",
+	"Comma-separated hook presets to install and wire into settings.json":                               "要安装并写入 settings.json 的钩子预设（逗号分隔）",
+	"Comma-separated output styles to install into .claude/output-styles":                               "要安装到 .claude/output-styles 的输出样式（逗号分隔）",
+	"Install a status line script: ":                                                                    "安装状态栏脚本：",
+	"Target format: ":                                                                                   "目标格式：",
+
+	// Commands
+	"Add optional scaffolding such as GitHub workflows":                                                                      "添加可选的脚手架，例如 GitHub 工作流",
+	"Render CLAUDE.md sections and commands for another assistant (%s)":                                                      "将 CLAUDE.md 章节和命令导出为其他助手的格式（%s）",
+	"Convert another assistant's rules (%s) into Claude config":                                                              "将其他助手的规则（%s）转换为 Claude 配置",
+	"Fail when .claude drifted from its lockfile, for pre-commit and husky":                                                  "当 .claude 与锁文件不一致时失败，用于 pre-commit 和 husky",
+	"Fail when required files are missing or templates are older than .cc-init.yaml allows":                                  "当缺少必需文件或模板版本低于 .cc-init.yaml 的要求时失败",
+	"Report problems as warnings and always exit 0":                                                                          "将问题报告为警告，并始终以 0 退出",
+	"Apply cc-init to many repositories on a branch and open pull requests":                                                  "在多个仓库的分支上应用 cc-init 并创建拉取请求",
+	"YAML manifest listing the repositories and flags to roll out":                                                           "列出要推广的仓库和选项的 YAML 清单",
+	"Directory for the clones (default: a temporary directory)":                                                              "存放克隆仓库的目录（默认：临时目录）",
+	"Push the rollout branch of every updated repository":                                                                    "推送每个已更新仓库的推广分支",
+	"Also open a pull or merge request (implies --push)":                                                                     "同时创建拉取请求或合并请求（隐含 --push）",
+	"Check .claude settings files against the Claude Code settings schema":                                                   "根据 Claude Code 设置模式检查 .claude 中的设置文件",
+	"Pack templates into one archive, or install such an archive as the template source of a machine without network access": "将模板打包为单个归档，或将此类归档安装为无网络机器的模板来源",
+	"Check the organization, user and project configuration, or write .cc-init.yaml by answering a few questions":            "检查组织、用户和项目配置，或通过回答几个问题写入 .cc-init.yaml",
+	"Run cc-init --update in several projects and show which were behind, dirty or clean":                                    "在多个项目中运行 cc-init --update，并显示哪些落后、有未提交修改或已是最新",
+	"List, locate or clean the template packs downloaded from HTTP template sources":                                         "列出、定位或清理从 HTTP 模板源下载的模板包",
+	"Print where cc-init keeps its user configuration, caches and state":                                                     "显示 cc-init 存放用户配置、缓存和状态的位置",
+	"Apply a patch written by --emit-patch, if every file is still as it was when the patch was made":                        "应用由 --emit-patch 生成的补丁，前提是每个文件仍与生成补丁时一致",
+	"Show the changelog entries between the installed pack version and the one about to be installed":                        "显示已安装的模板包版本与即将安装的版本之间的变更日志条目",
+	"List the backups under .claude/.backup or remove those outside the retention policy":                                    "列出 .claude/.backup 中的备份，或删除超出保留策略的备份",
+	"Keep this many most recent runs; negative keeps any number (default: .cc-init.yaml, else 10)":                           "保留最近的若干次运行；负数表示不限数量（默认：.cc-init.yaml，否则为 10）",
+	"Remove runs older than this, e.g. 30d or 12h; 0 keeps any age (default: .cc-init.yaml, else 30d)":                       "删除早于此时长的运行，例如 30d 或 12h；0 表示不限时长（默认：.cc-init.yaml，否则为 30d）",
+	"Remove the oldest runs beyond this total size, e.g. 50MB (default: .cc-init.yaml, else no limit)":                       "总大小超过此值时删除最旧的运行，例如 50MB（默认：.cc-init.yaml，否则不限）",
+
+	// Progress
+	"Created file":                                                 "已创建文件",
+	"Updated file":                                                 "已更新文件",
+	"Removed file":                                                 "已删除文件",
+	"Skipped existing file":                                        "跳过已存在的文件",
+	"Created directory":                                            "已创建目录",
+	"Skipped existing directory":                                   "跳过已存在的目录",
+	"Would create directory: %s (mode: %v)":                        "将创建目录：%s（权限：%v）",
+	"Would create file: %s (mode: %v, size: %d bytes)":             "将创建文件：%s（权限：%v，大小：%d 字节）",
+	"Would update file: %s (mode: %v, size: %d bytes)":             "将更新文件：%s（权限：%v，大小：%d 字节）",
+	"Would skip existing directory: %s":                            "将跳过已存在的目录：%s",
+	"Would skip existing file: %s":                                 "将跳过已存在的文件：%s",
+	"Starting cc-init with target directory: %s":                   "启动 cc-init，目标目录：%s",
+	"Found %d template files":                                      "找到 %d 个模板文件",
+	"Processing directory: %s":                                     "正在处理目录：%s",
+	"Processing file: %s -> %s":                                    "正在处理文件：%s -> %s",
+	"Detected languages: %v":                                       "检测到的语言：%v",
+	"Importing %s":                                                 "正在导入 %s",
+	"Error accessing %s: %v":                                       "访问 %s 时出错：%v",
+	"Failed to create directory %s: %v":                            "创建目录 %s 失败：%v",
+	"Failed to create file %s: %v":                                 "创建文件 %s 失败：%v",
+	"Failed to read template file %s: %v":                          "读取模板文件 %s 失败：%v",
+	"Cannot load theme: %v":                                        "无法加载主题：%v",
+	"Cannot open log file %s: %v":                                  "无法打开日志文件 %s：%v",
+	"MCP server %s already configured, keeping existing entry":     "MCP 服务器 %s 已配置，保留现有条目",
+	"Invalid template path %v":                                     "无效的模板路径 %v",
+	"Template path is not portable: %v":                            "模板路径不可移植：%v",
+	"%s:%d looks like it contains a %s":                            "%s:%d 似乎包含%s",
+	"--chown is not supported on %s; keeping the default owner":    "%s 不支持 --chown，保留默认所有者",
+	"Cannot change owner of generated files: %v":                   "无法更改生成文件的所有者：%v",
+	"Retrying %s of %s in %v (attempt %d of %d): %v":               "%[3]v 后重试对 %[2]s 的 %[1]s 操作（第 %[4]d 次，共 %[5]d 次）：%[6]v",
+	".claude configuration drifted from the cc-init %s templates:": ".claude 配置与 cc-init %s 的模板不一致：",
+	"%s: written by a different cc-init version":                   "%s：由其他版本的 cc-init 写入",
+	"%s: %s (+%d -%d against the template)":                        "%s：%s（相对模板 +%d -%d）",
+	"%s: %s":                                                       "%s：%s",
+	"Run cc-init and commit the result, including %s":              "请运行 cc-init 并提交结果，包括 %s",
+	"Required file is missing: %s":                                 "缺少必需文件：%s",
+	"No %s to read the installed version from; cc-init %s or newer is required": "没有可读取已安装版本的 %s；需要 cc-init %s 或更高版本",
+	"Templates are from cc-init %s; %s or newer is required":                    "模板来自 cc-init %s；需要 %s 或更高版本",
+	"No %s to read the installed template version from; %s is pinned":           "没有可读取已安装模板版本的 %s；已固定为 %s",
+	"Templates are at version %s; %s pins %s":                                   "模板版本为 %s；%s 固定为 %s",
+	"Templates are at version %s; the policy requires %s or newer":              "模板版本为 %s；策略要求 %s 或更高版本",
+	"settings.json does not deny %s, which the policy requires":                 "settings.json 未拒绝策略要求拒绝的 %s",
+	"All checks passed": "所有检查均已通过",
+	"Rolled out to %d %s: %d updated, %d unchanged, %d failed":                        "已推广到 %d %s：%d 个已更新，%d 个无变化，%d 个失败",
+	"Print the content digest of the embedded templates or a template directory":      "输出内置模板或模板目录的内容摘要",
+	"Check a template pack for invalid settings, frontmatter and risky permissions":   "检查模板包中无效的设置、frontmatter 和有风险的权限",
+	"Template pack to check, laid out like .claude (default: the embedded templates)": "要检查的模板包，目录结构与 .claude 相同（默认：内置模板）",
+	"file is world-writable (%v)":                                                     "文件对所有用户可写（%v）",
+	"invalid JSON":                                                                    "无效的 JSON",
+	"allow rule %q grants the tool without restriction":                               "允许规则 %q 不加限制地授予该工具",
+	"allow rule %q exposes secret files":                                              "允许规则 %q 暴露了机密文件",
+	"allow rule %q runs %q without confirmation":                                      "允许规则 %q 无需确认即可运行 %q",
+	"agent has no frontmatter":                                                        "代理缺少 frontmatter",
+	"frontmatter is not closed with ---":                                              "frontmatter 未以 --- 结束",
+	"invalid frontmatter: %v":                                                         "无效的 frontmatter：%v",
+	"agent frontmatter is missing %q":                                                 "代理 frontmatter 缺少 %q",
+	"agent name %q should be lowercase letters, digits and hyphens":                   "代理名称 %q 应只包含小写字母、数字和连字符",
+	"Template pack passed lint":                                                       "模板包通过检查",
+	"Template pack laid out like .claude to use instead of the embedded templates":    "代替内置模板使用的模板包，目录结构与 .claude 相同",
+	"Render a template pack for each fixture and compare with golden snapshots":       "为每个测试用例渲染模板包并与基准快照比较",
+	"Template pack to test, laid out like .claude (default: the embedded templates)":  "要测试的模板包，目录结构与 .claude 相同（默认：内置模板）",
+	"Directory with one subdirectory per test case":                                   "每个测试用例一个子目录的目录",
+	"Write the rendered trees as the new golden snapshots":                            "将渲染结果写入为新的基准快照",
+	"%s: missing":                 "%s：缺失",
+	"case":                        "个用例",
+	"cases":                       "个用例",
+	"%s: not in snapshot":         "%s：不在快照中",
+	"%s: differs (+%d -%d)":       "%s：有差异（+%d -%d）",
+	"%s: updated snapshot":        "%s：已更新快照",
+	"%s: matches snapshot":        "%s：与快照一致",
+	"%s: does not match snapshot": "%s：与快照不一致",
+	"Apply --template-dir again, overwriting earlier output, whenever the pack changes": "模板包变化时重新应用 --template-dir，并覆盖之前的输出",
+	"Template pack changed, applying again":                                             "模板包已变化，重新应用",
+	"Watching %s for changes (press Ctrl+C to stop)":                                    "正在监视 %s 的变化（按 Ctrl+C 停止）",
+	"Share the templates over HTTP for use with --template-dir <url>":                   "通过 HTTP 共享模板，供 --template-dir <url> 使用",
+	"Address to listen on": "监听地址",
+	"Template pack to serve, laid out like .claude (default: the embedded templates)": "要共享的模板包，目录结构与 .claude 相同（默认：内置模板）",
+	"template":                 "个模板",
+	"templates":                "个模板",
+	"%s %s from %s":            "%s %s 来自 %s",
+	"Serving %d %s (%s) on %s": "正在 %[4]s 上提供 %[1]d %[2]s（%[3]s）",
+	"Use it with: cc-init --template-dir http://%s/":                                "使用方式：cc-init --template-dir http://%s/",
+	"Create a template pack with an example agent, command, hook and snapshot test": "创建包含示例代理、命令、钩子和快照测试的模板包",
+	"Pack name recorded in pack.yaml (default: the directory name)":                 "记录在 pack.yaml 中的包名（默认：目录名）",
+	"Created template pack %s in %s":                                                "已在 %[2]s 中创建模板包 %[1]s",
+	"Next: cc-init lint --template-dir %s":                                          "下一步：cc-init lint --template-dir %s",
+	"manifest is missing %q":                                                        "清单缺少 %q",
+	"version %q is not a dotted version such as 1.2.0":                              "版本 %q 不是 1.2.0 这样的点分版本号",
+	"Embedded template set: ":                                                       "内置模板集：",
+	" (default: the set recorded in the lockfile, else full)":                       "（默认：锁文件中记录的模板集，否则为 full）",
+	"Embedded template set to check: ":                                              "要检查的内置模板集：",
+	"Embedded template set to serve: ":                                              "要共享的内置模板集：",
+	"Embedded template set to hash: ":                                               "要计算摘要的内置模板集：",
+	"Opt in to or out of anonymous usage metrics":                                   "选择加入或退出匿名使用统计",
+	"Telemetry is on (%s)\n":                                                        "遥测已开启（%s）\n",
+	"Telemetry is off (%s)\n":                                                       "遥测已关闭（%s）\n",
+	"Events are sent to %s\n":                                                       "事件将发送到 %s\n",
+	"No events are sent until %s is set\n":                                          "在设置 %s 之前不会发送任何事件\n",
+	"Cannot notify %s: %v":                                                          "无法通知 %s：%v",
+	"Notified %s":                                                                   "已通知 %s",
+	"Migrated %s: %s":                                                               "已迁移 %s：%s",
+	"Not exported: %s":                                                              "未导出：%s",
+	"Not fully mapped: %s":                                                          "未完全转换：%s",
+	"Add the ANTHROPIC_API_KEY repository secret before enabling %s":                "启用 %s 之前，请先添加 ANTHROPIC_API_KEY 仓库密钥",
+	"Comments inside %s could not be preserved; only leading comments were kept":    "无法保留 %s 内部的注释，仅保留了开头的注释",
+
+	"Updating the templates from %s to %s:":                                       "正在将模板从 %s 更新到 %s：",
+	"No pack version recorded in %s; showing every change up to %s":               "%s 中没有记录模板包版本；显示 %s 及之前的所有变更",
+	"Already at version %s":                                                       "已是版本 %s",
+	"Installing %s would downgrade the templates from %s and undo these changes:": "安装 %s 会将模板从 %s 降级并撤销以下变更：",
+	"The changelog has no entries between %s and %s":                              "变更日志中没有 %s 与 %s 之间的条目",
+
+	"No orphaned files to prune": "没有需要清理的孤立文件",
+	"Not pruning: this run had errors, so it may not have produced every file it manages": "未清理：本次运行出现错误，可能没有生成其管理的全部文件",
+	"%d pristine %s no longer produced by the templates:":                                 "%d %s未经修改且模板已不再生成：",
+	"%d %s no longer produced by the templates, modified since cc-init wrote them:":       "%d %s模板已不再生成，且在 cc-init 写入后被修改过：",
+	"Pass --prune --yes to remove them":                                                   "传入 --prune --yes 以删除它们",
+	"Keeping them, as the answers file says":                                              "按应答文件的要求保留它们",
+	"Remove %d %s? Copies are kept in %s":                                                 "删除 %d %s？副本将保存在 %s",
+	"Run %s to compute variable %s?":                                                      "运行 %s 来计算变量 %s？",
+	"Backed up the removed files to %s":                                                   "已将删除的文件备份到 %s",
+	"Would remove: %s":                                                                    "将删除：%s",
+
+	"Removed %d old backup %s (%s) from %s": "已从 %[4]s 删除 %[1]d %[2]s旧备份（%[3]s）",
+	"No backups in %s":                      "%s 中没有备份",
+	"No backups to remove":                  "没有需要删除的备份",
+
+	"Moved the previous %s to %s": "已将之前的 %s 移至 %s",
+
+	"Merged the template changes into %s": "已将模板变更合并到 %s",
+	"Not updating %s: %d conflicting %s with local changes; run cc-init --update in a terminal to merge them, or pass --conflict": "未更新 %s：与本地修改有 %d %s冲突；请在终端中运行 cc-init --update 进行合并，或传入 --conflict",
+	"%s has %d conflicting %s between local changes and the template":                                                             "%s 的本地修改与模板之间有 %d %s冲突",
+	"Resolve the conflict markers in %s by hand, or set --merge-tool, $VISUAL or $EDITOR":                                         "请手动解决 %s 中的冲突标记，或设置 --merge-tool、$VISUAL 或 $EDITOR",
+	"Resolve the conflict markers in %s by hand":                                                                                  "请手动解决 %s 中的冲突标记",
+	"Resolved the conflicts in %s":                                                                                                "已解决 %s 中的冲突",
+	"%s still has conflict markers":                                                                                               "%s 中仍有冲突标记",
+	"Open %s with %s to resolve the conflicts?":                                                                                   "用 %[2]s 打开 %[1]s 以解决冲突？",
+
+	"Settled %d conflicting %s in %s with --conflict %s": "已按 --conflict %[4]s 处理 %[3]s 中的 %[1]d %[2]s冲突",
+
+	"Nothing to change; wrote an empty patch to %s": "没有需要修改的内容；已写入空补丁 %s",
+	"Wrote a patch of %d %s to %s":                  "已将 %d %s的补丁写入 %s",
+
+	"Backed up the replaced and removed files to %s": "已将被替换和删除的文件备份到 %s",
+	"Applied %s to %d %s":                            "已将 %s 应用到 %d %s",
+
+	"Installing templates version %s, but %s pins %s": "正在安装版本为 %s 的模板，但 %s 固定为 %s",
+
+	"%d %s to update; uncommitted changes": "%d %s待更新；有未提交的修改",
+	"%d %s to update":                      "%d %s待更新",
+	"%d %s to update; commit or stash the uncommitted changes, or pass --allow-dirty": "%d %s待更新；请提交或暂存未提交的修改，或传入 --allow-dirty",
+	"%d %s changed": "%d %s已变更",
+	"PROJECT":       "项目",
+	"STATUS":        "状态",
+	"DETAILS":       "详情",
+
+	"The template cache %s is empty":    "模板缓存 %s 为空",
+	"Nothing to remove from %s":         "%s 中没有可删除的内容",
+	"Removed %d cached %s (%s) from %s": "已从 %[4]s 删除 %[1]d %[2]s缓存（%[3]s）",
+	"DIGEST":                            "摘要",
+	"SOURCE":                            "来源",
+	"REF":                               "版本",
+	"SIZE":                              "大小",
+	"LAST USED":                         "上次使用",
+	"NAME":                              "名称",
+	"PATH":                              "路径",
+
+	"Left out %s (%s)": "已略去 %s（%s）",
+
+	"Template source: a pack directory, the URL of cc-init serve, or embedded": "模板来源：模板包目录、cc-init serve 的 URL 或 embedded（内置）",
+	"  Cannot fetch the pack: %v\n":                                            "  无法获取模板包：%v\n",
+	"  No such directory\n":                                                    "  目录不存在\n",
+	"Permission preset":                                                        "权限预设",
+	"How --update settles conflicting hunks":                                   "--update 如何处理冲突块",
+	"Wrote %s":                                                                 "已写入 %s",
+
+	"Bundled %d %s (%s) from %s into %s":                                        "已将 %[4]s 中的 %[1]d %[2]s（%[3]s）打包到 %[5]s",
+	"The bundle is not signed; pass --sign-key so installs can verify it":       "捆绑包未签名；传入 --sign-key 以便安装时可以验证",
+	"%s is signed, but no key was given to verify it; pass --key":               "%s 已签名，但未提供用于验证的公钥；请传入 --key",
+	"%s is not signed; its templates are only checked against its own manifest": "%s 未签名；其模板仅对照自身清单检查",
+	"Installed %d %s (%s) into %s":                                              "已将 %d %s（%s）安装到 %s",
+	"Installed the policy of %s; set %s=%s to enforce it":                       "已安装 %s 的策略；设置 %s=%s 以强制执行",
+	"Use it with: cc-init --template-dir %s":                                    "使用方式：cc-init --template-dir %s",
+	"Runs now use these templates by default (template-dir in %s)":              "之后的运行默认使用这些模板（%s 中的 template-dir）",
+
+	"Set template-dir in %s, since a project may not choose its template source": "已在 %s 中设置 template-dir，因为项目不能选择自己的模板来源",
+
+	"The policy cannot be cached: %v":                             "无法缓存策略：%v",
+	"Failed to cache the policy in %s: %v":                        "无法将策略缓存到 %s：%v",
+	"The serial of the policy cannot be recorded: %v":             "无法记录策略的序号：%v",
+	"Failed to record the serial of the policy in %s: %v":         "无法将策略的序号记录到 %s：%v",
+	"Could not pin %s@%s to a commit, so it stays on the tag: %v": "无法将 %s@%s 固定到提交，仍使用标签：%v",
+	// Summary
+	"DRY RUN - No changes were made":                   "试运行 - 未做任何修改",
+	"Created %s":                                       "已创建 %s",
+	"Updated %s":                                       "已更新 %s",
+	"Removed %s":                                       "已删除 %s",
+	"Skipped %s (already exist)":                       "跳过 %s（已存在）",
+	"Encountered %d %s during initialization":          "初始化过程中遇到 %d %s",
+	"Finished in %v (resolve %v, render %v, write %v)": "耗时 %v（解析 %v，渲染 %v，写入 %v）",
+	"Wrote %d %s to %s":                                "已将 %d %s写入 %s",
+	"All Claude configuration files already exist":     "所有 Claude 配置文件均已存在",
+	"Claude configuration initialized successfully":    "Claude 配置初始化成功",
+	"Created %d %s":                                    "已创建 %d %s",
+	"Skipped %d existing %s":                           "跳过 %d %s（已存在）",
+	"All files and directories already exist":          "所有文件和目录均已存在",
+	"%d settings %s passed validation":                 "%d %s通过设置校验",
+	"%d configuration %s passed the check":             "%d %s通过配置检查",
+	"No configuration files apply to %s":               "没有适用于 %s 的配置文件",
+	"Error: %v\n":                                      "错误：%v\n",
+	" and ":                                            "和 ",
+	"created":                                          "已创建",
+	"updated":                                          "已更新",
+	"skipped":                                          "已跳过",
+	"failed":                                           "失败",
+	"entry":                                            "个条目",
+	"entries":                                          "个条目",
+	"modified":                                         "已修改",
+	"missing":                                          "缺失",
+	"path":                                             "个路径",
+	"paths":                                            "个路径",
+	"unchanged":                                        "无变化",
+	"target":                                           "个目标",
+	"targets":                                          "个目标",
+	"run":                                              "次运行的",
+	"runs":                                             "次运行的",
+	"hunk":                                             "处",
+	"hunks":                                            "处",
+	"Configure every subdirectory listed in .cc-init/targets.yaml in one run":        "一次性配置 .cc-init/targets.yaml 中列出的每个子目录",
+	"Repository root (default: the git repository root, else the current directory)": "仓库根目录（默认：git 仓库根目录，否则为当前目录）",
+	"Repository root (shorthand)":                                                    "仓库根目录（简写）",
+	"Target map (default: .cc-init/targets.yaml in the repository root)":             "目标映射文件（默认：仓库根目录下的 .cc-init/targets.yaml）",
+	"Usage: cc-init %s\n\n%s\n\nFlags after -- are passed to every run.\n\nFlags:\n": "用法：cc-init %s\n\n%s\n\n-- 之后的参数会传给每次运行。\n\n参数：\n",
+	"Applied the map to %d %s: %d failed":                                            "已将映射应用到 %d %s：%d 个失败",
+	"repository":                                                                     "个仓库",
+	"repositories":                                                                   "个仓库",
+	"project":                                                                        "个项目",
+	"projects":                                                                       "个项目",
+	"pack":                                                                           "个模板包",
+	"packs":                                                                          "个模板包",
+	"clean":                                                                          "最新",
+	"behind":                                                                         "落后",
+	"dirty":                                                                          "有修改",
+	"nothing":                                                                        "无",
+	"file":                                                                           "个文件",
+	"files":                                                                          "个文件",
+	"directory":                                                                      "个目录",
+	"directories":                                                                    "个目录",
+	"error":                                                                          "个错误",
+	"errors":                                                                         "个错误",
+	"item":                                                                           "项",
+	"items":                                                                          "项",
+	"problem":                                                                        "个问题",
+	"problems":                                                                       "个问题",
+}
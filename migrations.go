@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SettingsMigration rewrites a settings key that Claude Code renamed or removed
+type SettingsMigration struct {
+	// Path is the settings path the deprecated form lives under
+	Path        string
+	Description string
+	Applies     func(Settings) bool
+	Migrate     func(Settings)
+}
+
+// settingsMigrations lists the known deprecated settings forms, oldest first
+var settingsMigrations = []SettingsMigration{
+	{
+		Path:        "allowedTools",
+		Description: "allowedTools was replaced by permissions.allow",
+		Applies:     func(s Settings) bool { _, ok := s["allowedTools"]; return ok },
+		Migrate: func(s Settings) {
+			s.AddPermissionRules(PermissionAllow, stringList(s["allowedTools"]))
+			delete(s, "allowedTools")
+		},
+	},
+	{
+		Path:        "ignorePatterns",
+		Description: "ignorePatterns was replaced by Read(...) rules in permissions.deny",
+		Applies:     func(s Settings) bool { _, ok := s["ignorePatterns"]; return ok },
+		Migrate: func(s Settings) {
+			var rules []string
+			for _, pattern := range stringList(s["ignorePatterns"]) {
+				rules = append(rules, "Read("+pattern+")")
+			}
+			s.AddPermissionRules(PermissionDeny, rules)
+			delete(s, "ignorePatterns")
+		},
+	},
+	{
+		Path:        "hooks",
+		Description: "hook entries must be grouped as {matcher, hooks: [{type, command}]}",
+		Applies:     func(s Settings) bool { return len(flatHookEntries(s)) > 0 },
+		Migrate: func(s Settings) {
+			for _, flat := range flatHookEntries(s) {
+				flat.group["hooks"] = []interface{}{map[string]interface{}{
+					"type":    "command",
+					"command": flat.group["command"],
+				}}
+				delete(flat.group, "command")
+				delete(flat.group, "type")
+				if _, ok := flat.group["matcher"]; !ok {
+					flat.group["matcher"] = ""
+				}
+			}
+		},
+	},
+}
+
+// flatHookEntry is a hook written in the old {matcher, command} form
+type flatHookEntry struct {
+	event string
+	group map[string]interface{}
+}
+
+// flatHookEntries finds hook entries that carry a command directly
+func flatHookEntries(s Settings) []flatHookEntry {
+	hooks, _ := s["hooks"].(map[string]interface{})
+	var found []flatHookEntry
+	for event, value := range hooks {
+		groups, _ := value.([]interface{})
+		for _, g := range groups {
+			group, ok := g.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, ok := group["command"].(string); ok {
+				if _, nested := group["hooks"]; !nested {
+					found = append(found, flatHookEntry{event: event, group: group})
+				}
+			}
+		}
+	}
+	return found
+}
+
+// stringList returns the string elements of a decoded JSON array
+func stringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	var list []string
+	for _, item := range items {
+		if str, ok := item.(string); ok {
+			list = append(list, str)
+		}
+	}
+	return list
+}
+
+// deprecatedSettings returns the migrations that apply to settings
+func deprecatedSettings(s Settings) []SettingsMigration {
+	var found []SettingsMigration
+	for _, migration := range settingsMigrations {
+		if migration.Applies(s) {
+			found = append(found, migration)
+		}
+	}
+	return found
+}
+
+// MigrateSettings rewrites every deprecated form in settings and returns the
+// migrations that were applied
+func MigrateSettings(s Settings) []SettingsMigration {
+	applied := deprecatedSettings(s)
+	for _, migration := range applied {
+		migration.Migrate(s)
+	}
+	return applied
+}
+
+// coveredByMigration reports whether a schema error at path is explained by
+// one of the deprecated forms in found
+func coveredByMigration(path string, found []SettingsMigration) bool {
+	for _, migration := range found {
+		if path == migration.Path || strings.HasPrefix(path, migration.Path+".") || strings.HasPrefix(path, migration.Path+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// describeMigration formats a deprecation warning for a settings file
+func describeMigration(file string, migration SettingsMigration) string {
+	return fmt.Sprintf("%s: %s: deprecated: %s (run cc-init --migrate to update)", file, migration.Path, migration.Description)
+}
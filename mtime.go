@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MtimeRelease selects the release timestamp of the embedded templates
+const MtimeRelease = "release"
+
+// releaseDate is the RFC 3339 release timestamp of the template pack, set at
+// build time with -ldflags "-X main.releaseDate=..."; builds without it fall
+// back to the commit time recorded by the Go toolchain
+var releaseDate = ""
+
+// templateReleaseTime returns the release timestamp of the embedded templates
+func templateReleaseTime() (time.Time, error) {
+	if releaseDate != "" {
+		return time.Parse(time.RFC3339, releaseDate)
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.time" {
+				return time.Parse(time.RFC3339, setting.Value)
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("this build has no template release timestamp; pass an explicit --mtime")
+}
+
+// resolveMtime parses a --mtime value: "release", an RFC 3339 timestamp or
+// Unix seconds (optionally prefixed with @). An empty value falls back to
+// SOURCE_DATE_EPOCH, and the zero time means mtimes are left alone.
+func resolveMtime(value string) (time.Time, error) {
+	if value == "" {
+		value = os.Getenv("SOURCE_DATE_EPOCH")
+		if value == "" {
+			return time.Time{}, nil
+		}
+	}
+	if value == MtimeRelease {
+		return templateReleaseTime()
+	}
+	if seconds, err := strconv.ParseInt(strings.TrimPrefix(value, "@"), 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --mtime %q (expected %s, RFC 3339 or Unix seconds)", value, MtimeRelease)
+	}
+	return t, nil
+}
+
+// fixTimes sets the modification time of a file cc-init wrote when --mtime
+// or SOURCE_DATE_EPOCH asks for a fixed one
+func (e *Engine) fixTimes(targetPath string) error {
+	// validateConfig has already checked the value
+	mtime, _ := resolveMtime(e.config.Mtime)
+	if mtime.IsZero() {
+		return nil
+	}
+	if err := e.fs.Chtimes(targetPath, mtime); err != nil {
+		return fmt.Errorf("failed to set modification time of %s: %w", targetPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// newPackPreset is the skeleton copied by `cc-init new-pack`
+const newPackPreset = "presets/newpack"
+
+// runNewPack implements `cc-init new-pack <dir>`, which creates a working
+// template pack with an example agent, command and hook plus a snapshot test
+func runNewPack(args []string) error {
+	cmd := findCommand("new-pack")
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	name := fs.String("name", "", tr("Pack name recorded in pack.yaml (default: the directory name)"))
+	noColor := fs.Bool("no-color", false, tr("Disable colored output"))
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected the directory of the new pack")
+	}
+	dir := positional[0]
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s already exists and is not empty", dir)
+	}
+	if *name == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		*name = filepath.Base(abs)
+	}
+
+	logger := NewLogger(false, *noColor)
+	if err := writeNewPack(dir, *name, logger); err != nil {
+		return err
+	}
+
+	// Record the snapshot of the example, so `cc-init test` passes from the
+	// start
+	c := SnapshotCase{Name: "default", Dir: filepath.Join(dir, "testdata", "default")}
+	rendered, err := renderSnapshot(filepath.Join(dir, "pack"), c)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(rendered)
+	files, err := readSnapshotTree(rendered)
+	if err != nil {
+		return err
+	}
+	golden := filepath.Join(c.Dir, snapshotGoldenDir)
+	if err := writeSnapshot(golden, files); err != nil {
+		return err
+	}
+
+	logger.Success("Created template pack %s in %s", *name, dir)
+	logger.Info("Next: cc-init lint --template-dir %s", filepath.Join(dir, "pack"))
+	return nil
+}
+
+// writeNewPack copies the pack skeleton into dir, naming the pack
+func writeNewPack(dir, name string, logger *Logger) error {
+	return fs.WalkDir(presetFS, newPackPreset, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(p, newPackPreset), "/")
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := presetFS.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if path.Base(rel) == packManifestFile {
+			data = []byte(strings.Replace(string(data), "name: example-pack", "name: "+name, 1))
+		}
+		mode := os.FileMode(0644)
+		if path.Ext(rel) == ".sh" {
+			mode = 0755
+		}
+		if err := os.WriteFile(target, data, mode); err != nil {
+			return err
+		}
+		logger.FileCreated(filepath.ToSlash(target))
+		return nil
+	})
+}
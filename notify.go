@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// notifyURLEnv names the environment variable that sets the webhook when
+// --notify-url is not given
+const notifyURLEnv = "CC_INIT_NOTIFY_URL"
+
+// notifyTimeout bounds how long a slow endpoint can hold up a run
+const notifyTimeout = 10 * time.Second
+
+// NotifyReporter runs another reporter, then POSTs the JSON report to a
+// webhook. A failing webhook is logged as a warning and does not fail the
+// run.
+type NotifyReporter struct {
+	Reporter
+	url    string
+	logger *Logger
+	client *http.Client
+}
+
+// NewNotifyReporter wraps reporter to notify url after every run
+func NewNotifyReporter(reporter Reporter, url string, logger *Logger) *NotifyReporter {
+	return &NotifyReporter{
+		Reporter: reporter,
+		url:      url,
+		logger:   logger,
+		client:   &http.Client{Timeout: notifyTimeout},
+	}
+}
+
+// Report runs the wrapped reporter, then sends the notification
+func (r *NotifyReporter) Report(report *Report) error {
+	if err := r.Reporter.Report(report); err != nil {
+		return err
+	}
+	if err := r.notify(report); err != nil {
+		r.logger.Warning("Cannot notify %s: %v", r.url, err)
+	}
+	return nil
+}
+
+// notify POSTs the report in the --report json format
+func (r *NotifyReporter) notify(report *Report) error {
+	body, err := json.Marshal(newJSONReport(report))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cc-init/"+version)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	r.logger.Debug("Notified %s", r.url)
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// outputStylesDir holds output style templates, both in presets and in .claude
+const outputStylesDir = "output-styles"
+
+// outputStyleNames returns the sorted names of the bundled output styles
+func outputStyleNames() []string {
+	entries, err := presetFS.ReadDir(path.Join("presets", outputStylesDir))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
+			names = append(names, strings.TrimSuffix(entry.Name(), ".md"))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// outputStyleDescription returns the frontmatter description of a bundled style
+func outputStyleDescription(name string) string {
+	content, err := presetFS.ReadFile(path.Join("presets", outputStylesDir, name+".md"))
+	if err != nil {
+		return ""
+	}
+	return frontmatterValue(string(content), "description")
+}
+
+// validateOutputStyles checks that every requested output style is bundled
+func validateOutputStyles(names []string) error {
+	available := outputStyleNames()
+	for _, name := range names {
+		found := false
+		for _, a := range available {
+			if a == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown output style %q (available: %s)", name, strings.Join(available, ", "))
+		}
+	}
+	return nil
+}
+
+// generateOutputStyles installs the selected output styles into .claude/output-styles
+func (e *Engine) generateOutputStyles() error {
+	if len(e.config.OutputStyles) == 0 {
+		return nil
+	}
+
+	dir := filepath.Join(e.config.TargetDir, ".claude", outputStylesDir)
+	if err := e.processDirectory(dir); err != nil {
+		return err
+	}
+
+	for _, name := range e.config.OutputStyles {
+		content, err := presetFS.ReadFile(path.Join("presets", outputStylesDir, name+".md"))
+		if err != nil {
+			return fmt.Errorf("failed to read output style %s: %w", name, err)
+		}
+		if err := e.installFile(filepath.Join(dir, name+".md"), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// noNewline marks, inside a patch line, a last line without a newline
+const noNewline = "\x00"
+
+// patchEntry is a file written or removed through PatchFileSystem
+type patchEntry struct {
+	content []byte
+	mode    os.FileMode
+	dir     bool
+	removed bool
+}
+
+// PatchFileSystem reads through to another FileSystem but captures writes
+// and removals in memory and, on Close, stores the difference from the
+// files on disk as a patch that git apply accepts, with paths relative to
+// root. Like ArchiveFileSystem, merges behave exactly as they would on disk.
+type PatchFileSystem struct {
+	wrapped FileSystem
+	root    string
+	output  string
+	logger  *Logger
+	entries map[string]*patchEntry
+}
+
+// NewPatchFileSystem creates a new PatchFileSystem
+func NewPatchFileSystem(wrapped FileSystem, root, output string, logger *Logger) *PatchFileSystem {
+	return &PatchFileSystem{
+		wrapped: wrapped,
+		root:    root,
+		output:  output,
+		logger:  logger,
+		entries: map[string]*patchEntry{},
+	}
+}
+
+// Exists reports captured paths as well as existing ones, but not removed ones
+func (fs *PatchFileSystem) Exists(p string) bool {
+	if entry := fs.entries[p]; entry != nil {
+		return !entry.removed
+	}
+	return fs.wrapped.Exists(p)
+}
+
+// CreateDir captures a directory
+func (fs *PatchFileSystem) CreateDir(p string, perm os.FileMode) error {
+	if info, err := fs.Stat(p); err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("path exists but is not a directory: %s", p)
+		}
+		return nil
+	}
+	fs.entries[p] = &patchEntry{mode: perm, dir: true}
+	return nil
+}
+
+// CreateFile captures a file unless it already exists
+func (fs *PatchFileSystem) CreateFile(p string, content []byte, perm os.FileMode) error {
+	if info, err := fs.Stat(p); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("path exists but is a directory: %s", p)
+		}
+		return nil
+	}
+	return fs.WriteFile(p, content, perm)
+}
+
+// WriteFile captures a file, replacing any captured content. Like
+// os.WriteFile, an existing file keeps its mode.
+func (fs *PatchFileSystem) WriteFile(p string, content []byte, perm os.FileMode) error {
+	if info, err := fs.Stat(p); err == nil && !info.IsDir() {
+		perm = info.Mode().Perm()
+	}
+	fs.entries[p] = &patchEntry{content: append([]byte(nil), content...), mode: perm}
+	return nil
+}
+
+// ReadFile returns captured content, falling back to the wrapped filesystem
+func (fs *PatchFileSystem) ReadFile(p string) ([]byte, error) {
+	if entry := fs.entries[p]; entry != nil {
+		if entry.removed {
+			return nil, &os.PathError{Op: "open", Path: p, Err: os.ErrNotExist}
+		}
+		if !entry.dir {
+			return entry.content, nil
+		}
+	}
+	return fs.wrapped.ReadFile(p)
+}
+
+// Walk delegates to the wrapped filesystem
+func (fs *PatchFileSystem) Walk(root string, fn WalkFunc) error {
+	return fs.wrapped.Walk(root, fn)
+}
+
+// Stat returns info for captured paths, falling back to the wrapped filesystem
+func (fs *PatchFileSystem) Stat(p string) (fs.FileInfo, error) {
+	if entry := fs.entries[p]; entry != nil {
+		if entry.removed {
+			return nil, &os.PathError{Op: "stat", Path: p, Err: os.ErrNotExist}
+		}
+		return &archiveFileInfo{name: filepath.Base(p), entry: &archiveEntry{content: entry.content, mode: entry.mode, dir: entry.dir}}, nil
+	}
+	return fs.wrapped.Stat(p)
+}
+
+// Chmod sets the mode of a path, capturing an existing file so that the
+// patch carries the mode change
+func (fs *PatchFileSystem) Chmod(p string, mode os.FileMode) error {
+	entry := fs.entries[p]
+	if entry == nil {
+		info, err := fs.wrapped.Stat(p)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		content, err := fs.wrapped.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		entry = &patchEntry{content: content}
+		fs.entries[p] = entry
+	}
+	entry.mode = mode
+	return nil
+}
+
+// Chown does nothing; patches do not carry owners
+func (fs *PatchFileSystem) Chown(p string, uid, gid int) error {
+	return nil
+}
+
+// Chtimes does nothing; patches do not carry modification times
+func (fs *PatchFileSystem) Chtimes(p string, mtime time.Time) error {
+	return nil
+}
+
+// Remove captures the removal of a file
+func (fs *PatchFileSystem) Remove(p string) error {
+	if !fs.Exists(p) {
+		return &os.PathError{Op: "remove", Path: p, Err: os.ErrNotExist}
+	}
+	fs.entries[p] = &patchEntry{removed: true}
+	return nil
+}
+
+// Close writes the difference of the captured files from the files on disk
+// to the patch
+func (fs *PatchFileSystem) Close() error {
+	paths := make([]string, 0, len(fs.entries))
+	for p, entry := range fs.entries {
+		if !entry.dir {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var b strings.Builder
+	files := 0
+	for _, p := range paths {
+		rel, err := filepath.Rel(fs.root, p)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			fs.logger.Debug("Leaving %s out of the patch; it is outside the target", p)
+			continue
+		}
+		// The bases and backups cc-init keeps are gitignored local state
+		if slash := filepath.ToSlash(rel); strings.HasPrefix(slash, baseDir+"/") || strings.HasPrefix(slash, backupDir+"/") {
+			continue
+		}
+		var old []byte
+		var oldMode os.FileMode
+		existed := false
+		if info, err := fs.wrapped.Stat(p); err == nil && !info.IsDir() {
+			if old, err = fs.wrapped.ReadFile(p); err != nil {
+				return fmt.Errorf("failed to read %s: %w", p, err)
+			}
+			oldMode, existed = info.Mode(), true
+		}
+		entry := fs.entries[p]
+		if entry.removed && !existed {
+			continue
+		}
+		diff, err := renderGitDiff(filepath.ToSlash(rel), old, oldMode, existed, entry)
+		if err != nil {
+			return err
+		}
+		if diff != "" {
+			b.WriteString(diff)
+			files++
+		}
+	}
+
+	if err := os.WriteFile(fs.output, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write patch %s: %w", fs.output, err)
+	}
+	if files == 0 {
+		fs.logger.Info("Nothing to change; wrote an empty patch to %s", fs.output)
+		return nil
+	}
+	fs.logger.Success("Wrote a patch of %d %s to %s", files, pluralize("file", files), fs.output)
+	return nil
+}
+
+// gitMode returns the mode git records for a file: executable or not
+func gitMode(mode os.FileMode) string {
+	if mode&0111 != 0 {
+		return "100755"
+	}
+	return "100644"
+}
+
+// gitBlobHash returns the object name git gives content, so that a patch
+// names the exact versions it goes between
+func gitBlobHash(content []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// zeroHash is the object name of a missing side of a patch
+const zeroHash = "0000000000000000000000000000000000000000"
+
+// renderGitDiff renders the change of the file at name, relative to the
+// target, from old, if it existed, to entry as a diff --git section. It
+// returns "" when nothing changed.
+func renderGitDiff(name string, old []byte, oldMode os.FileMode, existed bool, entry *patchEntry) (string, error) {
+	var new []byte
+	if !entry.removed {
+		new = entry.content
+	}
+	if bytes.IndexByte(old, 0) >= 0 || bytes.IndexByte(new, 0) >= 0 {
+		return "", fmt.Errorf("cannot write %s to a patch: binary content", name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n", name, name)
+	oldHash, newHash := zeroHash, zeroHash
+	oldName, newName := "a/"+name, "b/"+name
+	switch {
+	case !existed:
+		newHash = gitBlobHash(new)
+		oldName = "/dev/null"
+		fmt.Fprintf(&b, "new file mode %s\nindex %s..%s\n", gitMode(entry.mode), oldHash, newHash)
+	case entry.removed:
+		oldHash = gitBlobHash(old)
+		newName = "/dev/null"
+		fmt.Fprintf(&b, "deleted file mode %s\nindex %s..%s\n", gitMode(oldMode), oldHash, newHash)
+	default:
+		oldHash, newHash = gitBlobHash(old), gitBlobHash(new)
+		modeChanged := gitMode(oldMode) != gitMode(entry.mode)
+		if oldHash == newHash && !modeChanged {
+			return "", nil
+		}
+		if modeChanged {
+			fmt.Fprintf(&b, "old mode %s\nnew mode %s\n", gitMode(oldMode), gitMode(entry.mode))
+		}
+		if oldHash == newHash {
+			return b.String(), nil
+		}
+		fmt.Fprintf(&b, "index %s..%s", oldHash, newHash)
+		if !modeChanged {
+			b.WriteString(" " + gitMode(oldMode))
+		}
+		b.WriteString("\n")
+	}
+	if len(old) == 0 && len(new) == 0 {
+		return b.String(), nil
+	}
+
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, hunk := range diffHunks(diffLines(patchLines(old), patchLines(new))) {
+		b.WriteString(hunk.header() + "\n")
+		for _, line := range hunk.Lines {
+			text, missing := strings.CutSuffix(line.Text, noNewline)
+			b.WriteString(string(line.Kind) + text + "\n")
+			if missing {
+				b.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// patchLines splits content into lines like splitLines, marking a last line
+// without a newline so that it differs from the same line with one
+func patchLines(content []byte) []string {
+	text := string(content)
+	if text == "" || strings.HasSuffix(text, "\n") {
+		return splitLines(text)
+	}
+	return splitLines(text + noNewline + "\n")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// pluginPrefix starts the name of every plugin executable: `cc-init foo`
+// runs cc-init-plugin-foo from PATH
+const pluginPrefix = "cc-init-plugin-"
+
+// pluginBinaryEnv tells a plugin how to run cc-init itself
+const pluginBinaryEnv = "CC_INIT_BIN"
+
+// PluginContext is the JSON document a plugin reads from stdin
+type PluginContext struct {
+	// Version is the version of the cc-init running the plugin
+	Version string `json:"version"`
+	// Target is the default target of cc-init: the root of the git work
+	// tree around the current directory, else the current directory
+	Target string `json:"target"`
+	// Config is the .cc-init.yaml of the target, if any
+	Config *ProjectConfig `json:"config,omitempty"`
+	// Report is what the last run installed, as recorded in the lockfile
+	Report *Lock `json:"report,omitempty"`
+}
+
+// findPlugin returns the path of the plugin for a subcommand name, or ""
+// when there is none. Names that look like paths never match, so
+// `cc-init ./foo` still initializes the directory foo.
+func findPlugin(name string) string {
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return ""
+	}
+	path, err := exec.LookPath(pluginPrefix + name)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// listPlugins returns the names of the plugins on PATH; when two directories
+// hold the same plugin, the first one wins, as it does for findPlugin
+func listPlugins() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), pluginPrefix)
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, ".exe")
+			}
+			if !ok || name == "" || seen[name] || entry.IsDir() {
+				continue
+			}
+			if info, err := entry.Info(); err != nil || (runtime.GOOS != "windows" && info.Mode()&0111 == 0) {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newPluginContext describes the default target for a plugin
+func newPluginContext() (*PluginContext, error) {
+	target, err := defaultTargetDir(&Config{})
+	if err != nil {
+		return nil, err
+	}
+	engine := &Engine{config: &Config{TargetDir: target}, fs: NewOSFileSystem()}
+	context := &PluginContext{Version: version, Target: target}
+	if _, err := os.Stat(filepath.Join(target, projectConfigFile)); err == nil {
+		if context.Config, err = engine.loadProjectConfig(); err != nil {
+			return nil, err
+		}
+	}
+	if lock, err := engine.readLock(); err == nil {
+		context.Report = lock
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return context, nil
+}
+
+// runPlugin runs a plugin with the remaining arguments, passing it the
+// context on stdin. A plugin exiting non-zero yields an *exec.ExitError.
+func runPlugin(path string, args []string) error {
+	context, err := newPluginContext()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(context)
+	if err != nil {
+		return err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), pluginBinaryEnv+"="+self)
+	return cmd.Run()
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables of the organization policy: the URL of the policy
+// document and the base64 Ed25519 public key its signature is checked with
+const (
+	policyURLEnv = "CC_INIT_POLICY_URL"
+	policyKeyEnv = "CC_INIT_POLICY_KEY"
+)
+
+// policySignatureSuffix is appended to the policy URL to fetch its detached
+// signature
+const policySignatureSuffix = ".sig"
+
+// policyCacheFile keeps, in the user cache directory, the last policy whose
+// signature verified, for runs while offline
+const policyCacheFile = "policy.yaml"
+
+// policySerialFile keeps, in the user state directory, the highest serial
+// of a policy that verified, so an older signed policy cannot be replayed
+const policySerialFile = "policy-serial"
+
+// policyTimeout bounds fetching the policy and its signature
+const policyTimeout = 10 * time.Second
+
+// Policy is the organization policy: rules that cc-init enforces on every
+// run, unlike the defaults of the configuration layers, which flags override
+type Policy struct {
+	// AllowedSources lists the template sources runs may use: "embedded"
+	// for the templates built into cc-init, or a URL that the URL of
+	// cc-init serve or the file:// URL of a pack directory must lie within
+	AllowedSources []string `yaml:"allowed_sources"`
+	// MinTemplateVersion is the oldest template version runs may install
+	MinTemplateVersion string `yaml:"min_template_version"`
+	// Deny lists permission rules every settings.json must deny
+	Deny []string `yaml:"deny"`
+	// Serial grows with every policy published; runs refuse a policy whose
+	// serial is lower than one they have seen
+	Serial int64 `yaml:"serial"`
+	// Expires is when the policy stops being valid, fetched or cached; it is
+	// required, so a signed policy cannot be replayed forever
+	Expires time.Time `yaml:"expires"`
+
+	// source is where the policy was fetched from, for messages
+	source string
+}
+
+// loadPolicy fetches the policy at CC_INIT_POLICY_URL and verifies its
+// signature with the key in CC_INIT_POLICY_KEY. Without the URL there is no
+// policy. A policy that cannot be fetched falls back to the copy verified
+// last; one whose signature does not verify, that has expired or that is
+// older than a policy seen before is refused. Failing to keep the copy or
+// the serial only warns.
+func loadPolicy(logger *Logger) (*Policy, error) {
+	url := os.Getenv(policyURLEnv)
+	if url == "" {
+		return nil, nil
+	}
+	key, err := policyKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var cached string
+	if dir, err := userCacheDir(); err == nil {
+		cached = filepath.Join(dir, policyCacheFile)
+	} else {
+		logger.Warning("The policy cannot be cached: %v", err)
+	}
+	data, signature, err := fetchPolicy(url)
+	if err == nil {
+		if !ed25519.Verify(key, data, signature) {
+			return nil, fmt.Errorf("the signature of the policy at %s does not verify with %s", url, policyKeyEnv)
+		}
+		if cached != "" {
+			if err := cachePolicy(cached, data, signature); err != nil {
+				logger.Warning("Failed to cache the policy in %s: %v", cached, err)
+			}
+		}
+	} else {
+		fetchErr := err
+		if cached == "" {
+			return nil, fmt.Errorf("failed to fetch the policy: %w", fetchErr)
+		}
+		data, err = os.ReadFile(cached)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the policy: %w", fetchErr)
+		}
+		signature, err = os.ReadFile(cached + policySignatureSuffix)
+		if err != nil || !ed25519.Verify(key, data, decodeSignature(signature)) {
+			return nil, fmt.Errorf("failed to fetch the policy (%v), and the cached copy %s does not verify", fetchErr, cached)
+		}
+	}
+	policy, err := parsePolicy(data, url)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.checkCurrent(time.Now(), logger); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// cachePolicy stores a verified policy and its signature at path and path
+// with .sig appended. Each is written to a temporary file renamed into place,
+// so an interrupted write never leaves a truncated copy behind.
+func cachePolicy(path string, data, signature []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := replaceFile(path, data); err != nil {
+		return err
+	}
+	return replaceFile(path+policySignatureSuffix, signature)
+}
+
+// replaceFile writes content to a temporary file beside path and renames it
+// over path
+func replaceFile(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// policyKey decodes the public key in CC_INIT_POLICY_KEY
+func policyKey() (ed25519.PublicKey, error) {
+	encoded := strings.TrimSpace(os.Getenv(policyKeyEnv))
+	if encoded == "" {
+		return nil, fmt.Errorf("%s is set, but %s holds no public key to verify the policy with", policyURLEnv, policyKeyEnv)
+	}
+	return decodePublicKey(encoded, policyKeyEnv)
+}
+
+// decodePublicKey decodes a base64 Ed25519 public key; name says where it
+// came from
+func decodePublicKey(encoded, name string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%s is not a base64 Ed25519 public key", name)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// decodeSignature decodes a detached signature, which is base64 text or
+// the raw signature bytes
+func decodeSignature(data []byte) []byte {
+	if signature, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data))); err == nil {
+		return signature
+	}
+	return data
+}
+
+// fetchPolicy downloads the policy document and its detached signature
+func fetchPolicy(url string) ([]byte, []byte, error) {
+	client := &http.Client{Timeout: policyTimeout}
+	get := func(url string) ([]byte, error) {
+		body, err := httpGet(client, url)
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	data, err := get(url)
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := get(url + policySignatureSuffix)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, decodeSignature(signature), nil
+}
+
+// parsePolicy decodes a verified policy document. Unknown keys are errors:
+// a rule cc-init does not understand must not be dropped silently.
+func parsePolicy(data []byte, source string) (*Policy, error) {
+	policy := &Policy{source: source}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(policy); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid policy %s: %w", source, err)
+	}
+	deny, err := expandPermissionRules(policy.Deny)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", source, err)
+	}
+	policy.Deny = deny
+	return policy, nil
+}
+
+// checkCurrent refuses a policy past its expiry or with a serial lower than
+// the highest one verified before, which it otherwise records
+func (p *Policy) checkCurrent(now time.Time, logger *Logger) error {
+	if p.Expires.IsZero() {
+		return fmt.Errorf("the policy %s has no expires date; sign one that says when it stops being valid", p.source)
+	}
+	if !now.Before(p.Expires) {
+		return fmt.Errorf("the policy %s expired on %s; publish a newer one", p.source, p.Expires.Format(time.RFC3339))
+	}
+	dir, err := userStateDir()
+	if err != nil {
+		logger.Warning("The serial of the policy cannot be recorded: %v", err)
+		return nil
+	}
+	path := filepath.Join(dir, policySerialFile)
+	var seen int64
+	if data, err := os.ReadFile(path); err == nil {
+		fmt.Sscan(string(data), &seen)
+	}
+	if p.Serial < seen {
+		return fmt.Errorf("the policy %s has serial %d, older than serial %d verified before; it may have been rolled back", p.source, p.Serial, seen)
+	}
+	if p.Serial > seen {
+		if err := os.MkdirAll(dir, 0755); err == nil {
+			err = replaceFile(path, []byte(fmt.Sprintln(p.Serial)))
+		}
+		if err != nil {
+			logger.Warning("Failed to record the serial of the policy in %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// allowsSource reports whether the policy lets runs use the template source,
+// "embedded" or a URL
+func (p *Policy) allowsSource(source string) bool {
+	if len(p.AllowedSources) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedSources {
+		if sourceWithin(source, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceWithin reports whether the template source lies within an allowed
+// source: the same scheme and host, and a path at or below the allowed one
+// on "/" boundaries: https://templates.corp does not admit
+// https://templates.corp.evil.com, nor file:///srv/packs file:///srv/packs-x
+func sourceWithin(source, allowed string) bool {
+	if source == embeddedSource || allowed == embeddedSource {
+		return source == allowed
+	}
+	s, err := url.Parse(source)
+	if err != nil {
+		return false
+	}
+	a, err := url.Parse(allowed)
+	if err != nil || a.Scheme == "" || a.Opaque != "" || s.Opaque != "" {
+		return false
+	}
+	if !strings.EqualFold(s.Scheme, a.Scheme) || !strings.EqualFold(s.Host, a.Host) || s.User != nil {
+		return false
+	}
+	sourcePath := path.Clean("/" + s.Path)
+	allowedPath := strings.TrimSuffix(path.Clean("/"+a.Path), "/")
+	return sourcePath == allowedPath || strings.HasPrefix(sourcePath, allowedPath+"/")
+}
+
+// templateSourceURI names the source of --template-dir as the policy sees
+// it: "embedded", the URL of cc-init serve, or the file:// URL of a pack
+// directory. A pack in the template cache is named by the source it was
+// fetched or bundled from.
+func templateSourceURI(templateDir string) (string, error) {
+	switch {
+	case templateDir == "":
+		return embeddedSource, nil
+	case isTemplateURL(templateDir):
+		return templateDir, nil
+	}
+	dir, err := filepath.Abs(templateDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid template directory: %w", err)
+	}
+	if source := cachedPackSource(dir); source != "" {
+		return source, nil
+	}
+	return fileURL(dir), nil
+}
+
+// fileURL returns the file:// URL of an absolute path, with the drive of a
+// Windows path in its path rather than its host
+func fileURL(dir string) string {
+	slashed := filepath.ToSlash(dir)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed
+	}
+	return "file://" + slashed
+}
+
+// checkSource refuses a --template-dir the policy does not allow; it runs
+// before a pack is fetched, so a refused source is never downloaded
+func (p *Policy) checkSource(templateDir string) error {
+	source, err := templateSourceURI(templateDir)
+	if err != nil {
+		return err
+	}
+	if !p.allowsSource(source) {
+		return fmt.Errorf("the policy %s does not allow the template source %s (allowed: %s)", p.source, source, strings.Join(p.AllowedSources, ", "))
+	}
+	return nil
+}
+
+// enforcePolicy refuses templates older than the policy allows
+func (e *Engine) enforcePolicy() error {
+	policy := e.config.Policy
+	if policy == nil || policy.MinTemplateVersion == "" {
+		return nil
+	}
+	if current := e.templateVersion(); compareVersions(current, policy.MinTemplateVersion) < 0 {
+		return fmt.Errorf("the templates are version %s but the policy %s requires %s or newer", current, policy.source, policy.MinTemplateVersion)
+	}
+	return nil
+}
+
+// missingPolicyDenyRules returns the deny rules of the policy that the
+// target's settings.json lacks
+func (e *Engine) missingPolicyDenyRules() ([]string, error) {
+	if e.config.Policy == nil || len(e.config.Policy.Deny) == 0 {
+		return nil, nil
+	}
+	data, err := e.fs.ReadFile(filepath.Join(e.config.TargetDir, ".claude", settingsFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	settings, err := ParseSettings(data)
+	if err != nil {
+		return nil, err
+	}
+	permissions, _ := settings["permissions"].(map[string]interface{})
+	deny, _ := permissions[PermissionDeny].([]interface{})
+	denied := map[interface{}]bool{}
+	for _, rule := range deny {
+		denied[rule] = true
+	}
+	var missing []string
+	for _, rule := range e.config.Policy.Deny {
+		if !denied[rule] {
+			missing = append(missing, rule)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSourceWithin(t *testing.T) {
+	tests := []struct {
+		source, allowed string
+		within          bool
+	}{
+		{"embedded", "embedded", true},
+		{"embedded", "https://templates.corp", false},
+		{"https://templates.corp/", "embedded", false},
+		{"https://templates.corp/", "https://templates.corp", true},
+		{"https://templates.corp", "https://templates.corp/", true},
+		{"https://templates.corp/team/go/", "https://templates.corp/team", true},
+		{"https://TEMPLATES.corp/team", "https://templates.corp/team/", true},
+		{"https://templates.corp.evil.com/", "https://templates.corp", false},
+		{"https://templates.corp:8443/", "https://templates.corp", false},
+		{"https://evil.com/templates.corp/", "https://templates.corp", false},
+		{"https://templates.corp@evil.com/", "https://templates.corp", false},
+		{"https://user@templates.corp/", "https://templates.corp", false},
+		{"http://templates.corp/", "https://templates.corp", false},
+		{"https://templates.corp/team-b/", "https://templates.corp/team", false},
+		{"https://templates.corp/team/../other/", "https://templates.corp/team", false},
+		{"https://templates.corp/team/%2e%2e/other/", "https://templates.corp/team", false},
+		{"file:///srv/packs", "file:///srv/packs", true},
+		{"file:///srv/packs/go", "file:///srv/packs/", true},
+		{"file:///srv/packs-untrusted", "file:///srv/packs", false},
+		{"file:///srv", "file:///srv/packs", false},
+		{"file:///C:/packs/go", "file:///C:/packs", true},
+		{"https://templates.corp/", "templates.corp", false},
+	}
+	for _, tt := range tests {
+		if got := sourceWithin(tt.source, tt.allowed); got != tt.within {
+			t.Errorf("sourceWithin(%q, %q) = %v, want %v", tt.source, tt.allowed, got, tt.within)
+		}
+	}
+}
+
+func TestFileURLIsAbsolute(t *testing.T) {
+	dir := t.TempDir()
+	if got := fileURL(dir); !sourceWithin(got, "file:///") || !strings.HasSuffix(got, filepath.ToSlash(dir)) {
+		t.Fatalf("fileURL(%q) = %q", dir, got)
+	}
+}
+
+func TestPolicyExpiresAndSerial(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	parse := func(doc string) *Policy {
+		t.Helper()
+		policy, err := parsePolicy([]byte(doc), "https://policy.corp/policy.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return policy
+	}
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	logger := NewLogger(false, true)
+
+	if err := parse("serial: 1\n").checkCurrent(now, logger); err == nil || !strings.Contains(err.Error(), "no expires") {
+		t.Fatalf("a policy without expires = %v, want an error", err)
+	}
+	if err := parse("serial: 1\nexpires: 2026-05-31T00:00:00Z\n").checkCurrent(now, logger); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("an expired policy = %v, want an error", err)
+	}
+	if err := parse("serial: 5\nexpires: 2026-12-31\n").checkCurrent(now, logger); err != nil {
+		t.Fatal(err)
+	}
+	if err := parse("serial: 5\nexpires: 2026-12-31\n").checkCurrent(now, logger); err != nil {
+		t.Fatalf("the same serial again = %v", err)
+	}
+	if err := parse("serial: 4\nexpires: 2027-12-31\n").checkCurrent(now, logger); err == nil || !strings.Contains(err.Error(), "rolled back") {
+		t.Fatalf("an older serial = %v, want an error", err)
+	}
+	if err := parse("serial: 6\nexpires: 2026-12-31\n").checkCurrent(now, logger); err != nil {
+		t.Fatal(err)
+	}
+	if err := parse("serial: 5\nexpires: 2026-12-31\n").checkCurrent(now, logger); err == nil {
+		t.Fatal("serial 5 was accepted after serial 6")
+	}
+}
+
+func TestCachePolicyReplacesFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", policyCacheFile)
+	for _, doc := range []string{"serial: 1\n", "serial: 2\n"} {
+		if err := cachePolicy(path, []byte(doc), []byte("sig "+doc)); err != nil {
+			t.Fatal(err)
+		}
+		if data, err := os.ReadFile(path); err != nil || string(data) != doc {
+			t.Fatalf("cached policy = %q, %v, want %q", data, err, doc)
+		}
+		if data, err := os.ReadFile(path + policySignatureSuffix); err != nil || string(data) != "sig "+doc {
+			t.Fatalf("cached signature = %q, %v", data, err)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("the cache holds %d files, want the policy and its signature only", len(entries))
+	}
+}
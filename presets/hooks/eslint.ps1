@@ -0,0 +1,17 @@
+# Run ESLint with --fix on JavaScript and TypeScript files after Claude edits
+# them, and report the problems it cannot fix.
+$ErrorActionPreference = 'Stop'
+
+$payload = [Console]::In.ReadToEnd() | ConvertFrom-Json
+$file = $payload.tool_input.file_path
+$eslint = 'node_modules\.bin\eslint.cmd'
+
+if ($file -match '\.(js|jsx|mjs|cjs|ts|tsx|mts|cts)$' -and (Test-Path -LiteralPath $file) -and (Test-Path -LiteralPath $eslint)) {
+    $output = & $eslint --fix $file 2>&1 | Out-String
+    if ($LASTEXITCODE -ne 0) {
+        [Console]::Error.WriteLine($output)
+        exit 2
+    }
+}
+
+exit 0
@@ -0,0 +1,19 @@
+#!/usr/bin/env bash
+# Run ESLint with --fix on JavaScript and TypeScript files after Claude edits
+# them, and report the problems it cannot fix.
+set -euo pipefail
+
+file=$(jq -r '.tool_input.file_path // empty')
+
+case "$file" in
+  *.js|*.jsx|*.mjs|*.cjs|*.ts|*.tsx|*.mts|*.cts)
+    if [ -f "$file" ] && [ -x node_modules/.bin/eslint ]; then
+      if ! output=$(node_modules/.bin/eslint --fix "$file" 2>&1); then
+        echo "$output" >&2
+        exit 2
+      fi
+    fi
+    ;;
+esac
+
+exit 0
@@ -0,0 +1,18 @@
+# Run the tests of the Go package Claude edited and report failures back.
+$ErrorActionPreference = 'Stop'
+
+$payload = [Console]::In.ReadToEnd() | ConvertFrom-Json
+$file = $payload.tool_input.file_path
+
+if ($file -like '*.go' -and (Test-Path -LiteralPath $file) -and (Get-Command go -ErrorAction SilentlyContinue)) {
+    Push-Location -LiteralPath (Split-Path -Parent $file)
+    $output = go test . 2>&1 | Out-String
+    $code = $LASTEXITCODE
+    Pop-Location
+    if ($code -ne 0) {
+        [Console]::Error.WriteLine($output)
+        exit 2
+    }
+}
+
+exit 0
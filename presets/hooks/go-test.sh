@@ -0,0 +1,18 @@
+#!/usr/bin/env bash
+# Run the tests of the Go package Claude edited and report failures back.
+set -euo pipefail
+
+file=$(jq -r '.tool_input.file_path // empty')
+
+case "$file" in
+  *.go)
+    if [ -f "$file" ] && command -v go >/dev/null 2>&1; then
+      if ! output=$(cd "$(dirname "$file")" && go test . 2>&1); then
+        echo "$output" >&2
+        exit 2
+      fi
+    fi
+    ;;
+esac
+
+exit 0
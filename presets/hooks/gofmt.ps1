@@ -0,0 +1,11 @@
+# Run gofmt on Go files after Claude edits them.
+$ErrorActionPreference = 'Stop'
+
+$payload = [Console]::In.ReadToEnd() | ConvertFrom-Json
+$file = $payload.tool_input.file_path
+
+if ($file -like '*.go' -and (Test-Path -LiteralPath $file) -and (Get-Command gofmt -ErrorAction SilentlyContinue)) {
+    gofmt -w $file
+}
+
+exit 0
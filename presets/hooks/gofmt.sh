@@ -0,0 +1,15 @@
+#!/usr/bin/env bash
+# Run gofmt on Go files after Claude edits them.
+set -euo pipefail
+
+file=$(jq -r '.tool_input.file_path // empty')
+
+case "$file" in
+  *.go)
+    if [ -f "$file" ] && command -v gofmt >/dev/null 2>&1; then
+      gofmt -w "$file"
+    fi
+    ;;
+esac
+
+exit 0
@@ -0,0 +1,33 @@
+# Send a desktop notification when a Bash command run by Claude takes longer
+# than CC_INIT_NOTIFY_SECONDS (default 30). Invoked with "start" before the
+# command and "stop" after it. Uses the BurntToast module when installed.
+param([string]$Phase)
+$ErrorActionPreference = 'Stop'
+
+$threshold = if ($env:CC_INIT_NOTIFY_SECONDS) { [int]$env:CC_INIT_NOTIFY_SECONDS } else { 30 }
+$payload = [Console]::In.ReadToEnd() | ConvertFrom-Json
+$session = if ($payload.session_id) { $payload.session_id } else { 'default' }
+$stamp = Join-Path ([IO.Path]::GetTempPath()) "claude-bash-$session.start"
+
+switch ($Phase) {
+    'start' {
+        [DateTimeOffset]::UtcNow.ToUnixTimeSeconds() | Set-Content -LiteralPath $stamp
+    }
+    'stop' {
+        if (-not (Test-Path -LiteralPath $stamp)) { exit 0 }
+        $elapsed = [DateTimeOffset]::UtcNow.ToUnixTimeSeconds() - [long](Get-Content -LiteralPath $stamp)
+        Remove-Item -LiteralPath $stamp -Force
+        if ($elapsed -ge $threshold) {
+            $cmd = "$($payload.tool_input.command)"
+            if ($cmd.Length -gt 80) { $cmd = $cmd.Substring(0, 80) }
+            $msg = "Bash finished after ${elapsed}s: $cmd"
+            if (Get-Module -ListAvailable -Name BurntToast) {
+                New-BurntToastNotification -Text 'Claude Code', $msg
+            } else {
+                [Console]::Error.WriteLine("`a$msg")
+            }
+        }
+    }
+}
+
+exit 0
@@ -0,0 +1,34 @@
+#!/usr/bin/env bash
+# Send a desktop notification when a Bash command run by Claude takes longer
+# than CC_INIT_NOTIFY_SECONDS (default 30). Invoked with "start" before the
+# command and "stop" after it.
+set -euo pipefail
+
+threshold=${CC_INIT_NOTIFY_SECONDS:-30}
+input=$(cat)
+session=$(printf '%s' "$input" | jq -r '.session_id // "default"')
+stamp="${TMPDIR:-/tmp}/claude-bash-${session}.start"
+
+case "${1:-}" in
+  start)
+    date +%s > "$stamp"
+    ;;
+  stop)
+    [ -f "$stamp" ] || exit 0
+    elapsed=$(( $(date +%s) - $(cat "$stamp") ))
+    rm -f "$stamp"
+    if [ "$elapsed" -ge "$threshold" ]; then
+      cmd=$(printf '%s' "$input" | jq -r '.tool_input.command // "command"' | head -c 80)
+      msg="Bash finished after ${elapsed}s: ${cmd//\"/\'}"
+      if command -v osascript >/dev/null 2>&1; then
+        osascript -e "display notification \"$msg\" with title \"Claude Code\""
+      elif command -v notify-send >/dev/null 2>&1; then
+        notify-send "Claude Code" "$msg"
+      else
+        printf '\a%s\n' "$msg" >&2
+      fi
+    fi
+    ;;
+esac
+
+exit 0
@@ -0,0 +1,20 @@
+# Block Claude from reading or writing .env files (.env.example is allowed).
+# Any failure exits 2, which blocks the tool call: a guard that cannot read
+# the request must not let it through.
+$ErrorActionPreference = 'Stop'
+
+try {
+    $payload = [Console]::In.ReadToEnd() | ConvertFrom-Json
+    $file = $payload.tool_input.file_path
+    $name = if ($file) { Split-Path -Leaf $file } else { 'none' }
+} catch {
+    [Console]::Error.WriteLine("The protect-env hook could not parse the tool input: $_")
+    exit 2
+}
+
+if ($name -ne '.env.example' -and ($name -eq '.env' -or $name -like '.env.*')) {
+    [Console]::Error.WriteLine("Access to $file is blocked by the protect-env hook")
+    exit 2
+}
+
+exit 0
@@ -0,0 +1,26 @@
+#!/usr/bin/env bash
+# Block Claude from reading or writing .env files (.env.example is allowed).
+# Any failure exits 2, which blocks the tool call: a guard that cannot read
+# the request must not let it through.
+set -euo pipefail
+
+if ! command -v jq >/dev/null 2>&1; then
+  echo "The protect-env hook needs jq; install it to allow file access" >&2
+  exit 2
+fi
+if ! file=$(jq -r '.tool_input.file_path // empty'); then
+  echo "The protect-env hook could not parse the tool input" >&2
+  exit 2
+fi
+name=$(basename "${file:-none}")
+
+case "$name" in
+  .env.example)
+    ;;
+  .env|.env.*)
+    echo "Access to $file is blocked by the protect-env hook" >&2
+    exit 2
+    ;;
+esac
+
+exit 0
@@ -0,0 +1,17 @@
+# Run ruff on Python files after Claude edits them: fix what it can, format,
+# and report the problems left.
+$ErrorActionPreference = 'Stop'
+
+$payload = [Console]::In.ReadToEnd() | ConvertFrom-Json
+$file = $payload.tool_input.file_path
+
+if ($file -like '*.py' -and (Test-Path -LiteralPath $file) -and (Get-Command ruff -ErrorAction SilentlyContinue)) {
+    ruff format --quiet $file
+    $output = ruff check --fix --quiet $file 2>&1 | Out-String
+    if ($LASTEXITCODE -ne 0) {
+        [Console]::Error.WriteLine($output)
+        exit 2
+    }
+}
+
+exit 0
@@ -0,0 +1,20 @@
+#!/usr/bin/env bash
+# Run ruff on Python files after Claude edits them: fix what it can, format,
+# and report the problems left.
+set -euo pipefail
+
+file=$(jq -r '.tool_input.file_path // empty')
+
+case "$file" in
+  *.py)
+    if [ -f "$file" ] && command -v ruff >/dev/null 2>&1; then
+      ruff format --quiet "$file" || true
+      if ! output=$(ruff check --fix --quiet "$file" 2>&1); then
+        echo "$output" >&2
+        exit 2
+      fi
+    fi
+    ;;
+esac
+
+exit 0
@@ -0,0 +1,11 @@
+#!/usr/bin/env bash
+# Example hook: log every file Claude edits to .claude/edits.log.
+set -euo pipefail
+
+file=$(jq -r '.tool_input.file_path // empty')
+
+if [ -n "$file" ]; then
+  echo "$(date -u +%Y-%m-%dT%H:%M:%SZ) $file" >> "$CLAUDE_PROJECT_DIR/.claude/edits.log"
+fi
+
+exit 0
@@ -0,0 +1,20 @@
+#!/usr/bin/env bash
+# Claude Code status line: session cost and context window usage.
+# CC_INIT_CONTEXT_LIMIT overrides the context window size (default 200000).
+
+input=$(cat)
+cost=$(printf '%s' "$input" | jq -r '.cost.total_cost_usd // 0')
+transcript=$(printf '%s' "$input" | jq -r '.transcript_path // empty')
+limit=${CC_INIT_CONTEXT_LIMIT:-200000}
+
+used=0
+if [ -n "$transcript" ] && [ -f "$transcript" ]; then
+  used=$(tail -n 200 "$transcript" | jq -s '
+    [.[] | .message.usage? | select(. != null)] | last
+    | if . == null then 0
+      else .input_tokens + (.cache_read_input_tokens // 0) + (.cache_creation_input_tokens // 0)
+      end' 2>/dev/null || echo 0)
+fi
+
+pct=$(( used * 100 / limit ))
+printf '$%.2f | ctx %d%%' "$cost" "$pct"
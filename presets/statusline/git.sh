@@ -0,0 +1,13 @@
+#!/usr/bin/env bash
+# Claude Code status line: current git branch and model name.
+
+input=$(cat)
+model=$(printf '%s' "$input" | jq -r '.model.display_name // "Claude"')
+dir=$(printf '%s' "$input" | jq -r '.workspace.current_dir // "."')
+branch=$(git -C "$dir" branch --show-current 2>/dev/null)
+
+if [ -n "$branch" ]; then
+  printf '\033[35m%s\033[0m | %s' "$branch" "$model"
+else
+  printf '%s' "$model"
+fi
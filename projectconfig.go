@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// projectConfigFile is the per-project cc-init configuration, relative to
+// the target directory
+const projectConfigFile = ".cc-init.yaml"
+
+// ProjectConfig is the content of .cc-init.yaml
+type ProjectConfig struct {
+	// MinVersion is the oldest cc-init version whose templates are accepted
+	MinVersion string `yaml:"min_version" json:"min_version,omitempty"`
+	// TemplateVersion pins the version of the templates: the pack version,
+	// or the cc-init version for the embedded templates
+	TemplateVersion string `yaml:"template_version" json:"template_version,omitempty"`
+	// Required lists files that must exist, relative to the target; nil
+	// means every template file
+	Required []string `yaml:"required" json:"required,omitempty"`
+	// Severity maps check rules to error, warn or off
+	Severity map[string]string `yaml:"severity" json:"severity,omitempty"`
+	// Owner is the .Owner of header templates
+	Owner string `yaml:"owner" json:"owner,omitempty"`
+	// Headers prepend license or ownership headers to written files
+	Headers []HeaderRule `yaml:"headers" json:"headers,omitempty"`
+	// Backups limits the runs kept under .claude/.backup
+	Backups *BackupPolicy `yaml:"backups" json:"backups,omitempty"`
+	// Defaults are flag values for runs in this project, over those of the
+	// organization and user configurations
+	Defaults map[string]interface{} `yaml:"defaults" json:"defaults,omitempty"`
+}
+
+// loadProjectConfig reads .cc-init.yaml from the target; a missing file
+// yields an empty configuration
+func (e *Engine) loadProjectConfig() (*ProjectConfig, error) {
+	config := &ProjectConfig{}
+	data, err := e.fs.ReadFile(filepath.Join(e.config.TargetDir, projectConfigFile))
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", projectConfigFile, err)
+	}
+	return config, nil
+}
+
+// severity returns the configured severity of a check rule
+func (p *ProjectConfig) severity(rule string) string {
+	if s, ok := p.Severity[rule]; ok {
+		return s
+	}
+	return defaultSeverity[rule]
+}
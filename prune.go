@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Orphan is a file the lockfile records but this run no longer produces
+type Orphan struct {
+	Path string
+	// Modified is set when the file changed since cc-init last wrote it
+	Modified bool
+	content  []byte
+}
+
+// findOrphans returns the files of the lockfile this run did not produce,
+// pristine ones first. Locked files that are already gone are dropped from
+// the lock.
+func (e *Engine) findOrphans() ([]Orphan, error) {
+	lock, err := e.readLock()
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	produced := map[string]bool{}
+	for _, rec := range e.stats.Records {
+		produced[rec.Path] = true
+	}
+
+	var orphans []Orphan
+	for rel, digest := range lock.Files {
+		if produced[rel] || lockExcluded(rel) {
+			continue
+		}
+		data, err := e.fs.ReadFile(filepath.Join(e.config.TargetDir, filepath.FromSlash(rel)))
+		if os.IsNotExist(err) {
+			e.logger.Debug("Dropping %s from the lockfile; it no longer exists", rel)
+			e.pruned = append(e.pruned, rel)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		orphans = append(orphans, Orphan{Path: rel, Modified: contentDigest(data) != digest, content: data})
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].Modified != orphans[j].Modified {
+			return !orphans[i].Modified
+		}
+		return orphans[i].Path < orphans[j].Path
+	})
+	return orphans, nil
+}
+
+// pruneOrphans removes, with --prune, the managed files the templates no
+// longer produce. It lists them as pristine or modified and asks before
+// removing anything unless --yes is given; every removed file is backed up
+// and recorded in the journal of the run.
+func (e *Engine) pruneOrphans() error {
+	if !e.config.Prune {
+		return nil
+	}
+	if len(e.stats.Errors) > 0 {
+		e.logger.Warning("Not pruning: this run had errors, so it may not have produced every file it manages")
+		return nil
+	}
+	orphans, err := e.findOrphans()
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		e.logger.Info("No orphaned files to prune")
+		return nil
+	}
+
+	modified := 0
+	for _, orphan := range orphans {
+		if orphan.Modified {
+			modified++
+		}
+	}
+	if pristine := len(orphans) - modified; pristine > 0 {
+		e.logger.Info("%d pristine %s no longer produced by the templates:", pristine, pluralize("file", pristine))
+		for _, orphan := range orphans[:pristine] {
+			e.logger.Info("  %s", orphan.Path)
+		}
+	}
+	if modified > 0 {
+		e.logger.Warning("%d %s no longer produced by the templates, modified since cc-init wrote them:", modified, pluralize("file", modified))
+		for _, orphan := range orphans[len(orphans)-modified:] {
+			e.logger.Warning("  %s", orphan.Path)
+		}
+	}
+
+	if !e.config.DryRun && !e.config.Yes {
+		remove, answered := e.config.Answers.confirmPrune()
+		if !answered {
+			if !stdinIsTerminal() || e.config.CI {
+				e.logger.Info("Pass --prune --yes to remove them")
+				return nil
+			}
+			answer, err := promptVariable(os.Stderr, bufio.NewReader(os.Stdin), PackVariable{
+				Name:    "prune",
+				Type:    VarBool,
+				Help:    fmt.Sprintf(tr("Remove %d %s? Copies are kept in %s"), len(orphans), pluralize("file", len(orphans)), backupDir),
+				Default: "no",
+			})
+			if err != nil {
+				return err
+			}
+			remove, _ = parseVarBool(answer)
+		} else if !remove {
+			e.logger.Info("Keeping them, as the answers file says")
+		}
+		if !remove {
+			return nil
+		}
+	}
+
+	for _, orphan := range orphans {
+		target := filepath.Join(e.config.TargetDir, filepath.FromSlash(orphan.Path))
+		if !e.config.DryRun {
+			if _, err := e.backupFile(JournalRemoved, orphan.Path, orphan.content); err != nil {
+				return fmt.Errorf("failed to back up %s: %w", orphan.Path, err)
+			}
+		}
+		if err := e.fs.Remove(target); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", orphan.Path, err)
+		}
+		if !e.config.DryRun {
+			e.logger.FileRemoved(e.formatPath(target))
+		}
+		e.stats.FilesRemoved++
+		e.record(target, false, ActionRemoved)
+		e.pruned = append(e.pruned, orphan.Path)
+	}
+	if !e.config.DryRun {
+		e.logger.Info("Backed up the removed files to %s", e.backupRunDir())
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPruneRefusesLockPathsOutsideTarget(t *testing.T) {
+	root := t.TempDir()
+	layerFixture(t, "", "")
+	t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+	victim := filepath.Join(root, "outside", "victim")
+	if err := os.MkdirAll(filepath.Dir(victim), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("not managed by cc-init\n")
+	if err := os.WriteFile(victim, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(root, "target")
+	for _, rel := range []string{"../outside/victim", filepath.ToSlash(victim), ".claude/../../outside/victim"} {
+		t.Run(rel, func(t *testing.T) {
+			lock := `{"files": {"` + rel + `": "` + contentDigest(content) + `"}}`
+			if err := os.MkdirAll(filepath.Join(target, ".claude"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(target, filepath.FromSlash(lockFile)), []byte(lock), 0644); err != nil {
+				t.Fatal(err)
+			}
+			config, err := parseWithLayers(t, "-t", target, "--prune", "--yes", "--no-color", "--report", "quiet")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := validateConfig(config); err != nil {
+				t.Fatal(err)
+			}
+			engine, err := NewEngine(templateFiles(), config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = engine.Run()
+			engine.Close()
+			if err == nil {
+				t.Fatalf("Run with %q in the lock succeeded, want it refused", rel)
+			}
+			if _, err := os.Stat(victim); err != nil {
+				t.Fatalf("prune removed a file outside the target: %v", err)
+			}
+		})
+	}
+}
+
+func TestLockPathValid(t *testing.T) {
+	for rel, valid := range map[string]bool{
+		".claude/settings.json":  true,
+		"CLAUDE.md":              true,
+		"pkg/a/CLAUDE.md":        true,
+		".devcontainer/x.json":   true,
+		"..":                     false,
+		".git/config":            false,
+		"../outside/victim":      false,
+		".claude/../../x":        false,
+		"./CLAUDE.md":            false,
+		"/etc/passwd":            false,
+		`C:\Windows\win.ini`:     false,
+		`..\outside`:             false,
+		"":                       false,
+		".":                      false,
+		".claude//settings.json": false,
+	} {
+		if got := lockPathValid(rel); got != valid {
+			t.Errorf("lockPathValid(%q) = %v, want %v", rel, got, valid)
+		}
+	}
+}
+
+func TestPruneFollowsAnswersFile(t *testing.T) {
+	root := t.TempDir()
+	layerFixture(t, "", "")
+	t.Setenv("XDG_STATE_HOME", filepath.Join(root, "state"))
+	for _, tc := range []struct {
+		answer string
+		remove bool
+	}{{"true", true}, {"false", false}} {
+		t.Run(tc.answer, func(t *testing.T) {
+			target := filepath.Join(root, tc.answer)
+			orphan := filepath.Join(target, ".claude", "retired.md")
+			if err := os.MkdirAll(filepath.Dir(orphan), 0755); err != nil {
+				t.Fatal(err)
+			}
+			content := []byte("no longer in the templates\n")
+			if err := os.WriteFile(orphan, content, 0644); err != nil {
+				t.Fatal(err)
+			}
+			lock := `{"files": {".claude/retired.md": "` + contentDigest(content) + `"}}`
+			if err := os.WriteFile(filepath.Join(target, filepath.FromSlash(lockFile)), []byte(lock), 0644); err != nil {
+				t.Fatal(err)
+			}
+			answers := filepath.Join(root, tc.answer+".yaml")
+			if err := os.WriteFile(answers, []byte("confirm:\n  prune: "+tc.answer+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			config, err := parseWithLayers(t, "-t", target, "--prune", "--answers", answers, "--no-color", "--report", "quiet")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := validateConfig(config); err != nil {
+				t.Fatal(err)
+			}
+			engine, err := NewEngine(templateFiles(), config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = engine.Run()
+			engine.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = os.Stat(orphan)
+			if removed := os.IsNotExist(err); removed != tc.remove {
+				t.Fatalf("prune: %s removed the orphan: %v, want %v", tc.answer, removed, tc.remove)
+			}
+		})
+	}
+}
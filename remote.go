@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// Remote target schemes accepted by --target
+const (
+	SchemeSSH    = "ssh"
+	SchemeDocker = "docker"
+	SchemeS3     = "s3"
+)
+
+// RemoteTarget is a target directory on another machine or in a container,
+// given as ssh://[user@]host[:port]/path, docker://container:/path or
+// s3://bucket/prefix
+type RemoteTarget struct {
+	Scheme string
+	User   string
+	Host   string
+	Port   string
+	Path   string
+}
+
+// dockerContainerPattern matches the names and IDs of Docker containers
+var dockerContainerPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// parseRemoteTarget parses a remote --target; ok is false for local paths
+func parseRemoteTarget(target string) (remote *RemoteTarget, ok bool, err error) {
+	if rest, found := strings.CutPrefix(target, "docker://"); found {
+		// docker://name:/path or docker://name/path
+		i := strings.IndexAny(rest, ":/")
+		if i <= 0 {
+			return nil, true, fmt.Errorf("invalid remote target %q: expected docker://container:/path", target)
+		}
+		// A name outside Docker's pattern, such as --privileged, would be
+		// read by docker exec as an option
+		if !dockerContainerPattern.MatchString(rest[:i]) {
+			return nil, true, fmt.Errorf("invalid remote target %q: %q is not a container name or ID", target, rest[:i])
+		}
+		return &RemoteTarget{
+			Scheme: SchemeDocker,
+			Host:   rest[:i],
+			Path:   path.Clean("/" + strings.TrimPrefix(rest[i:], ":")),
+		}, true, nil
+	}
+
+	if rest, found := strings.CutPrefix(target, "s3://"); found {
+		bucket, prefix, _ := strings.Cut(rest, "/")
+		if bucket == "" {
+			return nil, true, fmt.Errorf("invalid remote target %q: missing bucket", target)
+		}
+		return &RemoteTarget{
+			Scheme: SchemeS3,
+			Host:   bucket,
+			Path:   path.Clean("/" + prefix),
+		}, true, nil
+	}
+
+	if !strings.HasPrefix(target, "ssh://") && !strings.HasPrefix(target, "sftp://") {
+		return nil, false, nil
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid remote target: %w", err)
+	}
+	if u.Hostname() == "" {
+		return nil, true, fmt.Errorf("invalid remote target %q: missing host", target)
+	}
+	// ssh would read a host or user starting with - as one of its options,
+	// such as -oProxyCommand, which runs a local command
+	if strings.HasPrefix(u.Hostname(), "-") || strings.HasPrefix(u.User.Username(), "-") {
+		return nil, true, fmt.Errorf("invalid remote target %q: the host and user may not start with -", target)
+	}
+	remote = &RemoteTarget{
+		Scheme: SchemeSSH,
+		User:   u.User.Username(),
+		Host:   u.Hostname(),
+		Port:   u.Port(),
+		Path:   path.Clean("/" + u.Path),
+	}
+	return remote, true, nil
+}
+
+// String returns the target in URL form
+func (r *RemoteTarget) String() string {
+	switch r.Scheme {
+	case SchemeDocker:
+		return "docker://" + r.Host + ":" + r.Path
+	case SchemeS3:
+		return "s3://" + r.Host + r.Path
+	}
+	host := r.Host
+	if r.Port != "" {
+		host += ":" + r.Port
+	}
+	if r.User != "" {
+		host = r.User + "@" + host
+	}
+	return "ssh://" + host + r.Path
+}
+
+// RemoteFileSystem is a FileSystem backed by a connection that must be closed
+type RemoteFileSystem interface {
+	FileSystem
+	io.Closer
+}
+
+// openRemote connects to a remote target
+func openRemote(remote *RemoteTarget) (RemoteFileSystem, error) {
+	switch remote.Scheme {
+	case SchemeDocker:
+		return OpenDocker(remote.Host)
+	case SchemeS3:
+		return OpenS3(remote)
+	}
+	return DialSFTP(remote)
+}
+
+// sshArgs returns the ssh arguments that start the sftp subsystem on the
+// remote host
+func (r *RemoteTarget) sshArgs() []string {
+	args := []string{"-o", "BatchMode=yes"}
+	if r.Port != "" {
+		args = append(args, "-p", r.Port)
+	}
+	host := r.Host
+	if r.User != "" {
+		host = r.User + "@" + host
+	}
+	// -- ends the options, so the destination is never read as one
+	return append(args, "-s", "--", host, "sftp")
+}
+
+// SFTPFileSystem implements FileSystem on a remote host. It runs the system
+// ssh client, so ~/.ssh/config, agents and known_hosts apply as usual.
+type SFTPFileSystem struct {
+	client *sftp.Client
+	cmd    *exec.Cmd
+}
+
+// DialSFTP connects to the remote target's sftp subsystem
+func DialSFTP(remote *RemoteTarget) (*SFTPFileSystem, error) {
+	cmd := exec.Command("ssh", remote.sshArgs()...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ssh: %w", err)
+	}
+	client, err := sftp.NewClientPipe(stdout, stdin)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, fmt.Errorf("failed to connect to %s: %w", remote, err)
+	}
+	return &SFTPFileSystem{client: client, cmd: cmd}, nil
+}
+
+// Close ends the sftp session and waits for ssh to exit
+func (fs *SFTPFileSystem) Close() error {
+	fs.client.Close()
+	return fs.cmd.Wait()
+}
+
+// Exists checks if a file or directory exists
+func (fs *SFTPFileSystem) Exists(p string) bool {
+	_, err := fs.client.Stat(p)
+	return err == nil
+}
+
+// CreateDir creates a directory if it doesn't exist
+func (fs *SFTPFileSystem) CreateDir(p string, perm os.FileMode) error {
+	if info, err := fs.client.Stat(p); err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("path exists but is not a directory: %s", p)
+		}
+		return nil
+	}
+	if err := fs.client.MkdirAll(p); err != nil {
+		return err
+	}
+	return fs.client.Chmod(p, perm)
+}
+
+// CreateFile creates a file if it doesn't exist
+func (fs *SFTPFileSystem) CreateFile(p string, content []byte, perm os.FileMode) error {
+	if info, err := fs.client.Stat(p); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("path exists but is a directory: %s", p)
+		}
+		return nil
+	}
+	return fs.WriteFile(p, content, perm)
+}
+
+// WriteFile writes a file, replacing any existing content
+func (fs *SFTPFileSystem) WriteFile(p string, content []byte, perm os.FileMode) error {
+	if err := fs.client.MkdirAll(path.Dir(p)); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+	f, err := fs.client.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return fs.client.Chmod(p, perm)
+}
+
+// ReadFile reads the content of a file
+func (fs *SFTPFileSystem) ReadFile(p string) ([]byte, error) {
+	f, err := fs.client.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// Walk walks the file tree rooted at root
+func (fs *SFTPFileSystem) Walk(root string, fn WalkFunc) error {
+	walker := fs.client.Walk(root)
+	for walker.Step() {
+		err := fn(walker.Path(), walker.Stat(), walker.Err())
+		if err == filepath.SkipDir {
+			walker.SkipDir()
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stat returns file info for the given path
+func (fs *SFTPFileSystem) Stat(p string) (fs.FileInfo, error) {
+	return fs.client.Stat(p)
+}
+
+// Chmod sets the exact mode of a file or directory
+func (fs *SFTPFileSystem) Chmod(p string, mode os.FileMode) error {
+	return fs.client.Chmod(p, mode)
+}
+
+// Chown sets the owner of a file or directory; -1 keeps that id
+func (fs *SFTPFileSystem) Chown(p string, uid, gid int) error {
+	if uid == -1 || gid == -1 {
+		info, err := fs.client.Stat(p)
+		if err != nil {
+			return err
+		}
+		if stat, ok := info.Sys().(*sftp.FileStat); ok {
+			if uid == -1 {
+				uid = int(stat.UID)
+			}
+			if gid == -1 {
+				gid = int(stat.GID)
+			}
+		}
+	}
+	return fs.client.Chown(p, uid, gid)
+}
+
+// Chtimes sets the access and modification times of a file
+func (fs *SFTPFileSystem) Chtimes(p string, mtime time.Time) error {
+	return fs.client.Chtimes(p, mtime, mtime)
+}
+
+// Remove deletes a file or an empty directory
+func (fs *SFTPFileSystem) Remove(p string) error {
+	return fs.client.Remove(p)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRemoteTargetRefusesOptions(t *testing.T) {
+	for _, target := range []string{
+		"ssh://-oProxyCommand=id/x",
+		"sftp://-oProxyCommand=id:22/x",
+		"ssh://-oProxyCommand=id@host/x",
+	} {
+		if _, ok, err := parseRemoteTarget(target); !ok || err == nil || !strings.Contains(err.Error(), "may not start with -") {
+			t.Errorf("parseRemoteTarget(%q) = %v, %v, want it refused", target, ok, err)
+		}
+	}
+}
+
+func TestSSHArgsEndOptions(t *testing.T) {
+	remote, _, err := parseRemoteTarget("ssh://deploy@build.example.com:2222/srv/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-o", "BatchMode=yes", "-p", "2222", "-s", "--", "deploy@build.example.com", "sftp"}
+	if got := remote.sshArgs(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("sshArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestParseRemoteTargetContainerNames(t *testing.T) {
+	for target, valid := range map[string]bool{
+		"docker://app:/workspace":         true,
+		"docker://my_app.1-dev/workspace": true,
+		"docker://3f4e8a9b2c1d:/srv":      true,
+		"docker://--privileged:/x":        false,
+		"docker://-it:/x":                 false,
+		"docker://_app:/x":                false,
+		"docker://app name:/x":            false,
+	} {
+		_, ok, err := parseRemoteTarget(target)
+		if !ok || (err == nil) != valid {
+			t.Errorf("parseRemoteTarget(%q) = %v, %v, want valid %v", target, ok, err, valid)
+		}
+	}
+}
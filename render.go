@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	mathrand "math/rand/v2"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateSuffix marks pack files rendered with text/template; the suffix is
+// dropped from the installed name, so hooks/lint.sh.tmpl becomes hooks/lint.sh
+const templateSuffix = ".tmpl"
+
+// isTemplateFile reports whether a pack file is rendered before installing
+func isTemplateFile(p string) bool {
+	return strings.HasSuffix(p, templateSuffix) && path.Base(p) != templateSuffix
+}
+
+// builtinTemplateFuncs names the functions every template can call, in
+// addition to those of the pack's WASM modules
+var builtinTemplateFuncs = []string{"env", "uuid", "now", "date", "randomToken"}
+
+// envAllowed reports whether --allow-env permits reading name; entries are
+// names or glob patterns such as ACME_*
+func envAllowed(allowed []string, name string) bool {
+	for _, pattern := range allowed {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// templateFuncs returns the functions available to the templates of the pack
+func (e *Engine) templateFuncs() template.FuncMap {
+	funcs := template.FuncMap{
+		// env "VAR" reads an allowed environment variable; env "VAR" "default"
+		// falls back to default when it is unset
+		"env": func(name string, fallback ...string) (string, error) {
+			if !envAllowed(e.config.AllowEnv, name) {
+				return "", fmt.Errorf("environment variable %s is not allowed; pass --allow-env %s", name, name)
+			}
+			if value, ok := os.LookupEnv(name); ok {
+				return value, nil
+			}
+			if len(fallback) > 0 {
+				return fallback[0], nil
+			}
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		},
+		"uuid": e.newUUID,
+		"now":  e.renderTime,
+		// date "2006-01-02" formats the render time with a Go layout
+		"date": func(layout string) string {
+			return e.renderTime().Format(layout)
+		},
+		// randomToken n returns n random bytes as hex
+		"randomToken": func(n int) (string, error) {
+			if n <= 0 || n > 1024 {
+				return "", fmt.Errorf("randomToken: length %d is not between 1 and 1024", n)
+			}
+			buf := make([]byte, n)
+			e.randomBytes(buf)
+			return hex.EncodeToString(buf), nil
+		},
+	}
+	if e.wasm != nil {
+		for _, name := range e.wasm.Functions() {
+			name := name
+			funcs[name] = func(args ...string) (string, error) {
+				return e.wasm.CallFunction(name, args...)
+			}
+		}
+	}
+	return funcs
+}
+
+// randomBytes fills buf from the random source of the run: crypto/rand, or a
+// ChaCha8 stream keyed by --seed, so seeded runs render the same values
+func (e *Engine) randomBytes(buf []byte) {
+	if e.config.Seed == "" {
+		rand.Read(buf)
+		return
+	}
+	if e.seeded == nil {
+		e.seeded = mathrand.NewChaCha8(sha256.Sum256([]byte(e.config.Seed)))
+	}
+	e.seeded.Read(buf)
+}
+
+// newUUID returns a random (version 4) UUID
+func (e *Engine) newUUID() string {
+	var u [16]byte
+	e.randomBytes(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// renderTime is the time templates see: the fixed time of --mtime or
+// SOURCE_DATE_EPOCH when set, so reproducible runs render the same dates
+func (e *Engine) renderTime() time.Time {
+	// validateConfig has already checked --mtime
+	if mtime, _ := resolveMtime(e.config.Mtime); !mtime.IsZero() {
+		return mtime
+	}
+	return time.Now()
+}
+
+// templateData returns what templates see: the project facts found by
+// AnalyzeProject, overridden by --var, the platform of the hook scripts and
+// the user's shell, then the variables of the pack
+func (e *Engine) templateData() map[string]interface{} {
+	if e.project == nil {
+		e.project = AnalyzeProject(e.config.TargetDir)
+	}
+	data := e.project.TemplateVars()
+	data["Platform"] = e.targetPlatform()
+	data["Shell"] = e.userShell()
+	// validateConfig has already checked the assignments
+	assigned, _ := parseVarAssignments(e.config.Vars)
+	for name, value := range assigned {
+		if _, ok := data[name]; ok && name != "Languages" && name != "Platform" && name != "Shell" {
+			data[name] = value
+		}
+	}
+	for name, value := range e.vars {
+		data[name] = value
+	}
+	return data
+}
+
+// renderTemplate renders a .tmpl file of the pack with its variables
+func (e *Engine) renderTemplate(name string, content []byte) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(e.templateFuncs()).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, e.templateData()); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// parseTemplate checks the syntax of a .tmpl file without rendering it;
+// funcs names the functions available besides the built-in ones
+func parseTemplate(name string, content []byte, funcs []string) error {
+	stubs := template.FuncMap{}
+	for _, fn := range append(builtinTemplateFuncs, funcs...) {
+		stubs[fn] = func(...interface{}) string { return "" }
+	}
+	_, err := template.New(name).Funcs(stubs).Parse(string(content))
+	return err
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Supported report formats
+const (
+	ReportConsole  = "console"
+	ReportJSON     = "json"
+	ReportMarkdown = "markdown"
+	ReportQuiet    = "quiet"
+	ReportTree     = "tree"
+)
+
+// reportFormats lists the accepted values for the --report flag
+var reportFormats = []string{ReportConsole, ReportTree, ReportJSON, ReportMarkdown, ReportQuiet}
+
+// Report is the outcome of a run handed to a Reporter
+type Report struct {
+	TargetDir string
+	Templates string
+	DryRun    bool
+	Verbose   bool
+	Stats     Statistics
+	Duration  time.Duration
+	Timings   Timings
+	// ShowTimings adds a performance summary to console output (-vv)
+	ShowTimings bool
+}
+
+// Reporter renders the summary of a completed run
+type Reporter interface {
+	Report(report *Report) error
+}
+
+// validateReportFormat checks that format names a known Reporter
+func validateReportFormat(format string) error {
+	for _, f := range reportFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown report format %q (expected one of: %s)", format, strings.Join(reportFormats, ", "))
+}
+
+// NewReporter returns the Reporter for the given format
+func NewReporter(format string, logger *Logger, writer io.Writer) (Reporter, error) {
+	switch format {
+	case ReportConsole, "":
+		return &ConsoleReporter{logger: logger}, nil
+	case ReportTree:
+		return &TreeReporter{logger: logger, writer: writer}, nil
+	case ReportJSON:
+		return &JSONReporter{writer: writer}, nil
+	case ReportMarkdown:
+		return &MarkdownReporter{writer: writer}, nil
+	case ReportQuiet:
+		return QuietReporter{}, nil
+	default:
+		return nil, validateReportFormat(format)
+	}
+}
+
+// ConsoleReporter prints a human-readable summary through the Logger
+type ConsoleReporter struct {
+	logger *Logger
+}
+
+// Report displays the operation summary
+func (r *ConsoleReporter) Report(report *Report) error {
+	stats := report.Stats
+	totalCreated := stats.FilesCreated + stats.DirsCreated
+	totalSkipped := stats.FilesSkipped + stats.DirsSkipped
+
+	r.logger.Blank() // Empty line before summary
+
+	if report.DryRun {
+		r.logger.Info("DRY RUN - No changes were made")
+		r.logger.Blank()
+	}
+
+	// Show what was created
+	if totalCreated > 0 {
+		r.logger.Success("Created %s", describeCounts(stats.FilesCreated, stats.DirsCreated))
+	}
+
+	// Show what was updated
+	if stats.FilesUpdated > 0 {
+		r.logger.Success("Updated %s", describeCounts(stats.FilesUpdated, 0))
+	}
+
+	// Show what was removed
+	if stats.FilesRemoved > 0 {
+		r.logger.Success("Removed %s", describeCounts(stats.FilesRemoved, 0))
+	}
+
+	// Show what was skipped
+	if totalSkipped > 0 {
+		r.logger.Info("Skipped %s (already exist)", describeCounts(stats.FilesSkipped, stats.DirsSkipped))
+	}
+
+	// Show errors if any
+	if len(stats.Errors) > 0 {
+		r.logger.Error("Encountered %d %s during initialization", len(stats.Errors), pluralize("error", len(stats.Errors)))
+		if report.Verbose {
+			for _, err := range stats.Errors {
+				r.logger.Error("  - %v", err)
+			}
+		}
+	}
+
+	if report.ShowTimings {
+		t := report.Timings
+		r.logger.Info("Finished in %v (resolve %v, render %v, write %v)", t.Total.Round(time.Microsecond), t.Resolve.Round(time.Microsecond), t.Render.Round(time.Microsecond), t.Write.Round(time.Microsecond))
+		for _, rec := range slowestRecords(stats.Records, 5) {
+			r.logger.Info("  %8.3fms  %s", rec.DurationMS, rec.Path)
+		}
+	}
+
+	// Final status
+	if totalCreated == 0 && stats.FilesUpdated == 0 && stats.FilesRemoved == 0 && totalSkipped > 0 {
+		r.logger.Info("All Claude configuration files already exist")
+	} else if len(stats.Errors) == 0 {
+		r.logger.Success("Claude configuration initialized successfully")
+	}
+
+	return nil
+}
+
+// jsonReport is the wire format written by JSONReporter
+type jsonReport struct {
+	Version      string       `json:"version"`
+	Templates    string       `json:"templates,omitempty"`
+	TargetDir    string       `json:"target_dir"`
+	DryRun       bool         `json:"dry_run"`
+	FilesCreated int          `json:"files_created"`
+	FilesSkipped int          `json:"files_skipped"`
+	FilesUpdated int          `json:"files_updated"`
+	FilesRemoved int          `json:"files_removed"`
+	DirsCreated  int          `json:"dirs_created"`
+	DirsSkipped  int          `json:"dirs_skipped"`
+	Files        []FileRecord `json:"files"`
+	Errors       []string     `json:"errors"`
+	Timings      jsonTimings  `json:"timings"`
+}
+
+// JSONReporter writes the summary as a single JSON document
+type JSONReporter struct {
+	writer io.Writer
+}
+
+// Report encodes the report as indented JSON
+func (r *JSONReporter) Report(report *Report) error {
+	encoder := json.NewEncoder(r.writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(newJSONReport(report)); err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+	return nil
+}
+
+// newJSONReport converts a report to its JSON wire format
+func newJSONReport(report *Report) jsonReport {
+	stats := report.Stats
+	out := jsonReport{
+		Version:      version,
+		Templates:    report.Templates,
+		TargetDir:    report.TargetDir,
+		DryRun:       report.DryRun,
+		FilesCreated: stats.FilesCreated,
+		FilesSkipped: stats.FilesSkipped,
+		FilesUpdated: stats.FilesUpdated,
+		FilesRemoved: stats.FilesRemoved,
+		DirsCreated:  stats.DirsCreated,
+		DirsSkipped:  stats.DirsSkipped,
+		Files:        stats.Records,
+		Errors:       []string{},
+		Timings: jsonTimings{
+			TotalMS:   milliseconds(report.Timings.Total),
+			ResolveMS: milliseconds(report.Timings.Resolve),
+			RenderMS:  milliseconds(report.Timings.Render),
+			WriteMS:   milliseconds(report.Timings.Write),
+		},
+	}
+	if out.Files == nil {
+		out.Files = []FileRecord{}
+	}
+	for _, err := range stats.Errors {
+		out.Errors = append(out.Errors, err.Error())
+	}
+	return out
+}
+
+// MarkdownReporter writes the summary as a markdown document, e.g. for PR comments
+type MarkdownReporter struct {
+	writer io.Writer
+}
+
+// Report renders the report as a markdown table of files
+func (r *MarkdownReporter) Report(report *Report) error {
+	stats := report.Stats
+	var b strings.Builder
+
+	b.WriteString("## cc-init summary\n\n")
+	if report.DryRun {
+		b.WriteString("> Dry run - no changes were made.\n\n")
+	}
+	fmt.Fprintf(&b, "- Created: %s\n", describeCounts(stats.FilesCreated, stats.DirsCreated))
+	fmt.Fprintf(&b, "- Updated: %s\n", describeCounts(stats.FilesUpdated, 0))
+	if stats.FilesRemoved > 0 {
+		fmt.Fprintf(&b, "- Removed: %s\n", describeCounts(stats.FilesRemoved, 0))
+	}
+	fmt.Fprintf(&b, "- Skipped: %s\n", describeCounts(stats.FilesSkipped, stats.DirsSkipped))
+	fmt.Fprintf(&b, "- Errors: %d\n", len(stats.Errors))
+
+	if len(stats.Records) > 0 {
+		b.WriteString("\n| Path | Type | Action |\n")
+		b.WriteString("| ---- | ---- | ------ |\n")
+		for _, rec := range stats.Records {
+			kind := "file"
+			if rec.IsDir {
+				kind = "directory"
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", rec.Path, kind, rec.Action)
+		}
+	}
+
+	if len(stats.Errors) > 0 {
+		b.WriteString("\n### Errors\n\n")
+		for _, err := range stats.Errors {
+			fmt.Fprintf(&b, "- %v\n", err)
+		}
+	}
+
+	_, err := io.WriteString(r.writer, b.String())
+	return err
+}
+
+// QuietReporter suppresses the summary entirely
+type QuietReporter struct{}
+
+// Report does nothing
+func (QuietReporter) Report(report *Report) error {
+	return nil
+}
+
+// describeCounts formats file and directory counts, e.g. "3 files and 1 directory"
+func describeCounts(files, dirs int) string {
+	items := []string{}
+	if files > 0 {
+		items = append(items, fmt.Sprintf("%d %s", files, pluralize("file", files)))
+	}
+	if dirs > 0 {
+		items = append(items, fmt.Sprintf("%d %s", dirs, pluralize("directory", dirs)))
+	}
+	if len(items) == 0 {
+		return tr("nothing")
+	}
+	return strings.Join(items, tr(" and "))
+}
+
+// displayWidth returns the terminal columns of s, counting the wide CJK
+// characters of translated cells as two
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n++
+		if unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana) || (r >= 0x3000 && r <= 0x303f) || (r >= 0xff00 && r <= 0xff60) {
+			n++
+		}
+	}
+	return n
+}
+
+// writeTable writes rows under header in columns two spaces apart
+func writeTable(w io.Writer, header []string, rows [][]string) {
+	rows = append([][]string{header}, rows...)
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], displayWidth(cell))
+		}
+	}
+	for _, row := range rows {
+		line := ""
+		for i, cell := range row[:len(row)-1] {
+			line += cell + strings.Repeat(" ", widths[i]-displayWidth(cell)+2)
+		}
+		fmt.Fprintln(w, strings.TrimRight(line+row[len(row)-1], " "))
+	}
+}
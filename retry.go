@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+)
+
+// Defaults for --retries and --retry-delay
+const (
+	defaultRetries    = 3
+	defaultRetryDelay = 100 * time.Millisecond
+)
+
+// RetryPolicy controls how often a file operation that failed with a
+// transient error is tried again. The delay doubles after every attempt.
+type RetryPolicy struct {
+	Retries int
+	Delay   time.Duration
+}
+
+// retryableErrnos are errors that network file systems such as NFS and SMB
+// report for conditions that usually clear up on their own
+var retryableErrnos = []syscall.Errno{
+	syscall.ESTALE,
+	syscall.EIO,
+	syscall.EAGAIN,
+	syscall.EINTR,
+	syscall.ETIMEDOUT,
+}
+
+// isRetryable reports whether err is worth another attempt
+func isRetryable(err error) bool {
+	for _, errno := range retryableErrnos {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRetryPolicy checks the --retries and --retry-delay values
+func validateRetryPolicy(retries int, delay time.Duration) error {
+	if retries < 0 {
+		return fmt.Errorf("invalid --retries %d (expected 0 or more)", retries)
+	}
+	if delay < 0 {
+		return fmt.Errorf("invalid --retry-delay %v (expected a positive duration)", delay)
+	}
+	return nil
+}
+
+// retry runs op, trying again with backoff while it fails with a
+// retryable error. Every retry is logged at the debug level.
+func (fs *OSFileSystem) retry(name, path string, op func() error) error {
+	delay := fs.policy.Delay
+	err := op()
+	for attempt := 1; attempt <= fs.policy.Retries && err != nil && isRetryable(err); attempt++ {
+		if fs.logger != nil {
+			fs.logger.Debug("Retrying %s of %s in %v (attempt %d of %d): %v", name, path, delay, attempt+1, fs.policy.Retries+1, err)
+		}
+		time.Sleep(delay)
+		delay *= 2
+		err = op()
+	}
+	return err
+}
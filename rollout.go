@@ -0,0 +1,405 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults of a rollout manifest
+const (
+	defaultRolloutBranch  = "cc-init/update"
+	defaultRolloutMessage = "Update Claude Code configuration"
+)
+
+// Code hosts that rollout can open pull or merge requests on
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// RolloutManifest is the repos.yaml file read by `cc-init rollout`
+type RolloutManifest struct {
+	// Branch receives the changes in every repository
+	Branch string `yaml:"branch"`
+	// Message is the commit message and pull request title
+	Message string `yaml:"message"`
+	// Flags are cc-init flags applied to every repository
+	Flags []string      `yaml:"flags"`
+	Repos []RolloutRepo `yaml:"repos"`
+}
+
+// RolloutRepo is one repository of a rollout
+type RolloutRepo struct {
+	URL string `yaml:"url"`
+	// Base is the branch to start from and target; default is the remote HEAD
+	Base string `yaml:"base"`
+	// Provider is github or gitlab; default is guessed from the host
+	Provider string `yaml:"provider"`
+	// Flags are added to the manifest flags for this repository
+	Flags []string `yaml:"flags"`
+}
+
+// Rollout outcomes of a repository
+const (
+	RolloutUpdated   = "updated"
+	RolloutUnchanged = "unchanged"
+	RolloutFailed    = "failed"
+)
+
+// RolloutResult is the outcome of one repository
+type RolloutResult struct {
+	URL         string `json:"url"`
+	Status      string `json:"status"`
+	PullRequest string `json:"pull_request,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// loadRolloutManifest reads and checks a rollout manifest
+func loadRolloutManifest(path string) (*RolloutManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest RolloutManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if len(manifest.Repos) == 0 {
+		return nil, fmt.Errorf("%s lists no repos", path)
+	}
+	for i, repo := range manifest.Repos {
+		if repo.URL == "" {
+			return nil, fmt.Errorf("%s: repo %d has no url", path, i+1)
+		}
+		switch repo.Provider {
+		case "", ProviderGitHub, ProviderGitLab:
+		default:
+			return nil, fmt.Errorf("%s: unknown provider %q for %s (expected github or gitlab)", path, repo.Provider, repo.URL)
+		}
+	}
+	if manifest.Branch == "" {
+		manifest.Branch = defaultRolloutBranch
+	}
+	if manifest.Message == "" {
+		manifest.Message = defaultRolloutMessage
+	}
+	return &manifest, nil
+}
+
+// parseRepoURL splits an HTTPS, ssh:// or scp-style git URL into its host
+// and repository path without .git, e.g. github.com and org/api
+func parseRepoURL(raw string) (host, repoPath string, err error) {
+	if !strings.Contains(raw, "://") {
+		// git@host:org/repo.git
+		if at := strings.Index(raw, "@"); at >= 0 {
+			raw = raw[at+1:]
+		}
+		var found bool
+		host, repoPath, found = strings.Cut(raw, ":")
+		if !found {
+			return "", "", fmt.Errorf("cannot parse repository URL %q", raw)
+		}
+	} else {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return "", "", fmt.Errorf("cannot parse repository URL %q: %w", raw, err)
+		}
+		host, repoPath = u.Hostname(), u.Path
+	}
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if host == "" || repoPath == "" {
+		return "", "", fmt.Errorf("cannot parse repository URL %q", raw)
+	}
+	return host, repoPath, nil
+}
+
+// repoProvider returns the configured provider or guesses it from the host
+func repoProvider(repo RolloutRepo, host string) string {
+	if repo.Provider != "" {
+		return repo.Provider
+	}
+	if strings.Contains(host, "gitlab") {
+		return ProviderGitLab
+	}
+	return ProviderGitHub
+}
+
+// gitRun runs git in dir and returns its trimmed output
+func gitRun(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// The last line carries git's fatal error; earlier ones are hints
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		if msg := lines[len(lines)-1]; msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// rolloutOptions are the command-line options of `cc-init rollout`
+type rolloutOptions struct {
+	push bool
+	pr   bool
+}
+
+// rolloutRepo clones one repository, runs cc-init on a branch and commits,
+// pushes and opens a pull request as requested
+func rolloutRepo(manifest *RolloutManifest, repo RolloutRepo, dir string, opts rolloutOptions) (result RolloutResult) {
+	result = RolloutResult{URL: repo.URL, Status: RolloutFailed}
+	fail := func(err error) RolloutResult {
+		result.Status = RolloutFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	// Clones from an earlier rollout in the same --workdir are replaced
+	if err := os.RemoveAll(dir); err != nil {
+		return fail(err)
+	}
+	cloneArgs := []string{"clone", "--quiet", "--depth", "1"}
+	if repo.Base != "" {
+		cloneArgs = append(cloneArgs, "--branch", repo.Base)
+	}
+	if _, err := gitRun("", append(cloneArgs, repo.URL, dir)...); err != nil {
+		return fail(err)
+	}
+	base := repo.Base
+	if base == "" {
+		head, err := gitRun(dir, "rev-parse", "--abbrev-ref", "HEAD")
+		if err != nil {
+			return fail(err)
+		}
+		base = head
+	}
+	if _, err := gitRun(dir, "checkout", "--quiet", "-B", manifest.Branch); err != nil {
+		return fail(err)
+	}
+
+	// Run this binary so every cc-init flag works exactly as on the command line
+	self, err := os.Executable()
+	if err != nil {
+		return fail(err)
+	}
+	args := append([]string{"--target", dir, "--report", ReportQuiet, "--log-level", LogLevelWarn, "--no-color"}, manifest.Flags...)
+	cmd := exec.Command(self, append(args, repo.Flags...)...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fail(fmt.Errorf("cc-init failed: %s", strings.TrimSpace(output.String())))
+	}
+
+	if _, err := gitRun(dir, "add", "-A"); err != nil {
+		return fail(err)
+	}
+	if status, err := gitRun(dir, "status", "--porcelain"); err != nil {
+		return fail(err)
+	} else if status == "" {
+		result.Status = RolloutUnchanged
+		return result
+	}
+	if _, err := gitRun(dir, "commit", "--quiet", "-m", manifest.Message); err != nil {
+		return fail(err)
+	}
+	result.Status = RolloutUpdated
+
+	if !opts.push && !opts.pr {
+		return result
+	}
+	// The rollout branch belongs to cc-init, so an earlier rollout is replaced
+	if _, err := gitRun(dir, "push", "--quiet", "--force", "origin", manifest.Branch); err != nil {
+		return fail(err)
+	}
+	if !opts.pr {
+		return result
+	}
+	link, err := openPullRequest(repo, manifest.Branch, base, manifest.Message)
+	if err != nil {
+		return fail(err)
+	}
+	result.PullRequest = link
+	return result
+}
+
+// openPullRequest opens a GitHub pull request or GitLab merge request from
+// branch into base and returns its URL. Tokens come from GITHUB_TOKEN or
+// GITLAB_TOKEN; GITHUB_API_URL and CI_API_V4_URL override the API location.
+func openPullRequest(repo RolloutRepo, branch, base, title string) (string, error) {
+	host, repoPath, err := parseRepoURL(repo.URL)
+	if err != nil {
+		return "", err
+	}
+	body := "Applied by `cc-init rollout` (cc-init " + version + ")."
+
+	var req *http.Request
+	switch repoProvider(repo, host) {
+	case ProviderGitLab:
+		token := os.Getenv("GITLAB_TOKEN")
+		if token == "" {
+			return "", fmt.Errorf("GITLAB_TOKEN is not set")
+		}
+		api := os.Getenv("CI_API_V4_URL")
+		if api == "" {
+			api = "https://" + host + "/api/v4"
+		}
+		payload, _ := json.Marshal(map[string]string{
+			"source_branch": branch, "target_branch": base, "title": title, "description": body,
+		})
+		req, err = http.NewRequest(http.MethodPost, api+"/projects/"+url.PathEscape(repoPath)+"/merge_requests", bytes.NewReader(payload))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("PRIVATE-TOKEN", token)
+	default:
+		token := os.Getenv("GITHUB_TOKEN")
+		if token == "" {
+			return "", fmt.Errorf("GITHUB_TOKEN is not set")
+		}
+		api := os.Getenv("GITHUB_API_URL")
+		if api == "" {
+			api = "https://api.github.com"
+			if host != "github.com" {
+				// GitHub Enterprise Server
+				api = "https://" + host + "/api/v3"
+			}
+		}
+		payload, _ := json.Marshal(map[string]string{
+			"head": branch, "base": base, "title": title, "body": body,
+		})
+		req, err = http.NewRequest(http.MethodPost, api+"/repos/"+repoPath+"/pulls", bytes.NewReader(payload))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	var created struct {
+		HTMLURL string `json:"html_url"`
+		WebURL  string `json:"web_url"`
+	}
+	switch {
+	case resp.StatusCode == http.StatusCreated:
+		json.Unmarshal(data, &created)
+		return created.HTMLURL + created.WebURL, nil
+	case resp.StatusCode == http.StatusUnprocessableEntity || resp.StatusCode == http.StatusConflict:
+		// The branch already has an open request, which now shows the new commit
+		return "(existing)", nil
+	default:
+		return "", fmt.Errorf("failed to open pull request: %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+}
+
+// runRollout implements `cc-init rollout`
+func runRollout(args []string) error {
+	cmd := findCommand("rollout")
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	reposFile := fs.String("repos", "", tr("YAML manifest listing the repositories and flags to roll out"))
+	workdir := fs.String("workdir", "", tr("Directory for the clones (default: a temporary directory)"))
+	push := fs.Bool("push", false, tr("Push the rollout branch of every updated repository"))
+	pr := fs.Bool("pr", false, tr("Also open a pull or merge request (implies --push)"))
+	reportFile := fs.String("report-file", "", tr("Also write the JSON summary to this file"))
+	noColor := fs.Bool("no-color", false, tr("Disable colored output"))
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 || *reposFile == "" {
+		fs.Usage()
+		return fmt.Errorf("expected: cc-init rollout --repos <file>")
+	}
+
+	manifest, err := loadRolloutManifest(*reposFile)
+	if err != nil {
+		return err
+	}
+	dir := *workdir
+	if dir == "" {
+		if dir, err = os.MkdirTemp("", "cc-init-rollout-"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+	}
+
+	logger := NewLogger(false, *noColor)
+	opts := rolloutOptions{push: *push, pr: *pr}
+	var results []RolloutResult
+	failed := 0
+	for i, repo := range manifest.Repos {
+		logger.Info("[%d/%d] %s", i+1, len(manifest.Repos), repo.URL)
+		result := rolloutRepo(manifest, repo, filepath.Join(dir, fmt.Sprintf("%03d", i+1)), opts)
+		switch result.Status {
+		case RolloutUpdated:
+			if result.PullRequest != "" {
+				logger.Success("%s: %s %s", repo.URL, tr(result.Status), result.PullRequest)
+			} else {
+				logger.Success("%s: %s", repo.URL, tr(result.Status))
+			}
+		case RolloutUnchanged:
+			logger.Info("%s: %s", repo.URL, tr(result.Status))
+		default:
+			logger.Error("%s: %s", repo.URL, result.Error)
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if *reportFile != "" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*reportFile, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write report file: %w", err)
+		}
+	}
+
+	logger.Blank()
+	logger.Info("Rolled out to %d %s: %d updated, %d unchanged, %d failed", len(results), pluralize("repository", len(results)),
+		len(results)-failed-countStatus(results, RolloutUnchanged), countStatus(results, RolloutUnchanged), failed)
+	if failed > 0 {
+		return fmt.Errorf("rollout failed for %d %s", failed, pluralize("repository", failed))
+	}
+	return nil
+}
+
+// countStatus counts results with the given status
+func countStatus(results []RolloutResult, status string) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
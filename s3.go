@@ -0,0 +1,487 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// s3Credentials are the keys used to sign S3 requests
+type s3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// loadS3Credentials reads credentials from the standard AWS environment
+// variables, falling back to the shared credentials file
+func loadS3Credentials() (s3Credentials, error) {
+	creds := s3Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID != "" && creds.SecretAccessKey != "" {
+		return creds, nil
+	}
+
+	file := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return creds, fmt.Errorf("no AWS credentials: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+		file = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return creds, fmt.Errorf("no AWS credentials: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	defer f.Close()
+
+	// The credentials file is INI: [profile] sections of key = value lines
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section != profile {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(value)
+		}
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return creds, fmt.Errorf("no AWS credentials for profile %q in %s", profile, file)
+	}
+	return creds, nil
+}
+
+// S3FileSystem implements FileSystem on an S3 bucket or a compatible object
+// store. Paths map to object keys without the leading slash; directories
+// exist implicitly as key prefixes. Object stores keep no owners or
+// timestamps of their own choosing, so Chown and Chtimes do nothing, and
+// the mode of a file is kept in its x-amz-meta-mode metadata.
+type S3FileSystem struct {
+	bucket   string
+	root     string
+	region   string
+	endpoint *url.URL
+	creds    s3Credentials
+	client   *http.Client
+}
+
+// OpenS3 returns an S3FileSystem for the target's bucket, configured from
+// the usual AWS environment variables
+func OpenS3(remote *RemoteTarget) (*S3FileSystem, error) {
+	creds, err := loadS3Credentials()
+	if err != nil {
+		return nil, err
+	}
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	// A custom endpoint (MinIO, R2, localstack, ...) uses path-style
+	// addressing; AWS itself uses virtual-hosted buckets
+	raw := os.Getenv("AWS_ENDPOINT_URL_S3")
+	if raw == "" {
+		raw = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	var endpoint *url.URL
+	if raw != "" {
+		endpoint, err = url.Parse(strings.TrimSuffix(raw, "/"))
+		if err != nil || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid S3 endpoint %q", raw)
+		}
+		endpoint.Path += "/" + remote.Host
+	} else {
+		endpoint = &url.URL{Scheme: "https", Host: remote.Host + ".s3." + region + ".amazonaws.com"}
+	}
+
+	fs := &S3FileSystem{
+		bucket:   remote.Host,
+		root:     remote.Path,
+		region:   region,
+		endpoint: endpoint,
+		creds:    creds,
+		client:   &http.Client{Timeout: 60 * time.Second},
+	}
+	resp, err := fs.do(http.MethodHead, "", nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach bucket %s: %w", remote.Host, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to reach bucket %s: %s", remote.Host, resp.Status)
+	}
+	return fs, nil
+}
+
+// Close does nothing; every operation is its own HTTP request
+func (fs *S3FileSystem) Close() error {
+	return nil
+}
+
+// key returns the object key of a path
+func (fs *S3FileSystem) key(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(p)), "/")
+}
+
+// do sends a signed request for key; header and query may be nil
+func (fs *S3FileSystem) do(method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := *fs.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	u.RawPath = s3EscapePath(u.Path)
+	u.RawQuery = strings.ReplaceAll(query.Encode(), "+", "%20")
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	fs.sign(req, body, time.Now().UTC())
+	return fs.client.Do(req)
+}
+
+// sign adds AWS Signature Version 4 headers to req
+func (fs *S3FileSystem) sign(req *http.Request, body []byte, now time.Time) {
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	amzDate := now.Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if fs.creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", fs.creds.SessionToken)
+	}
+
+	// Sign the host and every x-amz-* header
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + fs.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+fs.creds.SecretAccessKey), date)
+	key = hmacSHA256(key, fs.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		fs.creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// s3EscapePath percent-encodes everything but unreserved characters and
+// slashes, as the canonical request requires
+func s3EscapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Error turns an unexpected response into an error, using the S3 error
+// code and message when the body carries them
+func s3Error(op, key string, resp *http.Response) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(resp.Body)
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		return fmt.Errorf("s3 %s %s: %s: %s", op, key, body.Code, body.Message)
+	}
+	return fmt.Errorf("s3 %s %s: %s", op, key, resp.Status)
+}
+
+// s3Object is one entry of a ListObjectsV2 response
+type s3Object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+// list returns the objects under prefix, at most limit of them when limit
+// is positive
+func (fs *S3FileSystem) list(prefix string, limit int) ([]s3Object, error) {
+	var objects []s3Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if limit > 0 {
+			query.Set("max-keys", strconv.Itoa(limit))
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := fs.do(http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+			return nil, s3Error("list", prefix, resp)
+		}
+		var result struct {
+			Contents              []s3Object `xml:"Contents"`
+			IsTruncated           bool       `xml:"IsTruncated"`
+			NextContinuationToken string     `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3 list %s: %w", prefix, err)
+		}
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || limit > 0 || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// isRootOrAncestor reports whether p is the target prefix or one of its
+// parents, which count as directories even before anything is written
+func (fs *S3FileSystem) isRootOrAncestor(p string) bool {
+	key, root := fs.key(p), fs.key(fs.root)
+	return key == "" || key == root || strings.HasPrefix(root, key+"/")
+}
+
+// Exists checks if a file or directory exists
+func (fs *S3FileSystem) Exists(p string) bool {
+	_, err := fs.Stat(p)
+	return err == nil
+}
+
+// CreateDir does nothing beyond checking for a file in the way, since
+// directories exist implicitly
+func (fs *S3FileSystem) CreateDir(p string, perm os.FileMode) error {
+	if info, err := fs.Stat(p); err == nil && !info.IsDir() {
+		return fmt.Errorf("path exists but is not a directory: %s", p)
+	}
+	return nil
+}
+
+// CreateFile creates a file if it doesn't exist
+func (fs *S3FileSystem) CreateFile(p string, content []byte, perm os.FileMode) error {
+	info, err := fs.Stat(p)
+	if err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("path exists but is a directory: %s", p)
+		}
+		return nil
+	}
+	return fs.WriteFile(p, content, perm)
+}
+
+// WriteFile uploads a file, replacing any existing object
+func (fs *S3FileSystem) WriteFile(p string, content []byte, perm os.FileMode) error {
+	key := fs.key(p)
+	header := http.Header{}
+	header.Set("X-Amz-Meta-Mode", octalMode(perm))
+	resp, err := fs.do(http.MethodPut, key, nil, header, content)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("put", key, resp)
+	}
+	return nil
+}
+
+// ReadFile downloads the content of a file
+func (fs *S3FileSystem) ReadFile(p string) ([]byte, error) {
+	key := fs.key(p)
+	resp, err := fs.do(http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &os.PathError{Op: "open", Path: p, Err: os.ErrNotExist}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error("get", key, resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Stat returns file info for an object, or for a prefix that has objects
+// below it
+func (fs *S3FileSystem) Stat(p string) (fs.FileInfo, error) {
+	dir := &listedFileInfo{path: "/" + fs.key(p), mode: os.ModeDir | 0755}
+	if fs.isRootOrAncestor(p) {
+		return dir, nil
+	}
+
+	key := fs.key(p)
+	resp, err := fs.do(http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		mode := os.FileMode(0644)
+		if m, err := strconv.ParseUint(resp.Header.Get("X-Amz-Meta-Mode"), 8, 32); err == nil {
+			mode = os.FileMode(m).Perm()
+		}
+		modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+		return &listedFileInfo{path: "/" + key, size: resp.ContentLength, mode: mode, modTime: modTime}, nil
+	case http.StatusNotFound:
+	default:
+		return nil, fmt.Errorf("s3 head %s: %s", key, resp.Status)
+	}
+
+	objects, err := fs.list(key+"/", 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, &os.PathError{Op: "stat", Path: p, Err: os.ErrNotExist}
+	}
+	return dir, nil
+}
+
+// Walk walks the objects under root in lexical order, like filepath.Walk,
+// with the prefixes between them as directories
+func (fs *S3FileSystem) Walk(root string, fn WalkFunc) error {
+	rootKey := fs.key(root)
+	prefix := ""
+	if rootKey != "" {
+		prefix = rootKey + "/"
+	}
+	objects, err := fs.list(prefix, 0)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+
+	rootPath := "/" + rootKey
+	infos := map[string]os.FileInfo{}
+	for _, obj := range objects {
+		p := "/" + obj.Key
+		if strings.HasSuffix(p, "/") {
+			// Folder markers created by consoles and other tools
+			p = strings.TrimSuffix(p, "/")
+		} else {
+			infos[p] = &listedFileInfo{path: p, size: obj.Size, mode: 0644, modTime: obj.LastModified}
+		}
+		for dir := path.Dir(p); len(dir) >= len(rootPath) && infos[dir] == nil; dir = path.Dir(dir) {
+			infos[dir] = &listedFileInfo{path: dir, mode: os.ModeDir | 0755}
+			if dir == "/" {
+				break
+			}
+		}
+	}
+	if infos[rootPath] == nil {
+		if !fs.isRootOrAncestor(root) {
+			return fn(root, nil, &os.PathError{Op: "lstat", Path: root, Err: os.ErrNotExist})
+		}
+		infos[rootPath] = &listedFileInfo{path: rootPath, mode: os.ModeDir | 0755}
+	}
+	return walkListing(root, infos, fn)
+}
+
+// Chmod does nothing; the mode is recorded when a file is written
+func (fs *S3FileSystem) Chmod(p string, mode os.FileMode) error {
+	return nil
+}
+
+// Chown does nothing; objects have no owner ids
+func (fs *S3FileSystem) Chown(p string, uid, gid int) error {
+	return nil
+}
+
+// Chtimes does nothing; the store sets Last-Modified itself
+func (fs *S3FileSystem) Chtimes(p string, mtime time.Time) error {
+	return nil
+}
+
+// Remove deletes an object; directories are prefixes and vanish with their
+// last object
+func (fs *S3FileSystem) Remove(p string) error {
+	key := fs.key(p)
+	resp, err := fs.do(http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return s3Error("delete", key, resp)
+	}
+	return nil
+}
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// settingsSchemaPath is the embedded JSON schema for Claude Code settings files
+const settingsSchemaPath = "presets/schema/settings.schema.json"
+
+// JSONSchema is the subset of JSON Schema used to describe settings and
+// configuration files: type, properties, additionalProperties,
+// propertyNames, required, items, enum, pattern and local
+// "#/definitions/..." references. Deprecated, unlike in JSON Schema, holds
+// what to use instead.
+type JSONSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Description          string                 `json:"description"`
+	Type                 string                 `json:"type"`
+	Properties           map[string]*JSONSchema `json:"properties"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	PropertyNames        *JSONSchema            `json:"propertyNames"`
+	Required             []string               `json:"required"`
+	Items                *JSONSchema            `json:"items"`
+	Enum                 []interface{}          `json:"enum"`
+	Pattern              string                 `json:"pattern"`
+	Definitions          map[string]*JSONSchema `json:"definitions"`
+	Deprecated           string                 `json:"deprecated"`
+}
+
+// SchemaError is a schema violation at a position in the validated document
+type SchemaError struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error formats the violation as "line:column: path: message"
+func (e SchemaError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", e.Line, e.Column, e.Path, e.Message)
+}
+
+// loadSettingsSchema decodes the embedded settings schema
+func loadSettingsSchema() (*JSONSchema, error) {
+	return loadSchema(settingsSchemaPath)
+}
+
+// loadSchema decodes an embedded schema
+func loadSchema(path string) (*JSONSchema, error) {
+	data, err := presetFS.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema %s: %w", path, err)
+	}
+	var schema JSONSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", path, err)
+	}
+	return &schema, nil
+}
+
+// ValidateSettings checks a settings document against the embedded schema.
+// Malformed JSON is reported as a single SchemaError.
+func ValidateSettings(data []byte) ([]SchemaError, error) {
+	schema, err := loadSettingsSchema()
+	if err != nil {
+		return nil, err
+	}
+	return validateJSONDocument(schema, data), nil
+}
+
+// validateJSONDocument checks data against schema, reporting positions
+func validateJSONDocument(schema *JSONSchema, data []byte) []SchemaError {
+	// Blanking comments keeps offsets, so positions still match the original
+	data = stripJSONC(data)
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		line, col := 1, 1
+		if syntaxErr, ok := err.(*json.SyntaxError); ok {
+			line, col = offsetPosition(data, int(syntaxErr.Offset))
+		}
+		return []SchemaError{{Line: line, Column: col, Message: "invalid JSON: " + err.Error()}}
+	}
+
+	offsets := jsonOffsets(data)
+	v := &schemaValidator{root: schema, position: func(path string) (int, int) {
+		return offsetPosition(data, offsets[path])
+	}}
+	v.validate(schema, doc, "")
+	return v.sortedErrors()
+}
+
+// schemaValidator accumulates violations while walking a decoded document.
+// position locates a document path in the source; scalarStrings accepts any
+// scalar where a string is expected, as YAML decoding does.
+type schemaValidator struct {
+	root          *JSONSchema
+	position      func(path string) (int, int)
+	scalarStrings bool
+	errors        []SchemaError
+}
+
+// fail records a violation at path
+func (v *schemaValidator) fail(path, format string, args ...interface{}) {
+	line, col := v.position(path)
+	v.errors = append(v.errors, SchemaError{Path: path, Line: line, Column: col, Message: fmt.Sprintf(format, args...)})
+}
+
+// sortedErrors returns the violations in document order
+func (v *schemaValidator) sortedErrors() []SchemaError {
+	sort.SliceStable(v.errors, func(i, j int) bool {
+		if v.errors[i].Line != v.errors[j].Line {
+			return v.errors[i].Line < v.errors[j].Line
+		}
+		return v.errors[i].Column < v.errors[j].Column
+	})
+	return v.errors
+}
+
+// resolve follows a "#/definitions/name" reference
+func (v *schemaValidator) resolve(schema *JSONSchema) *JSONSchema {
+	if schema.Ref == "" {
+		return schema
+	}
+	if def, ok := v.root.Definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")]; ok {
+		return def
+	}
+	return &JSONSchema{}
+}
+
+// validate checks value against schema, recursing into objects and arrays
+func (v *schemaValidator) validate(schema *JSONSchema, value interface{}, path string) {
+	schema = v.resolve(schema)
+
+	if schema.Deprecated != "" {
+		v.fail(path, "deprecated; %s", schema.Deprecated)
+	}
+	if v.scalarStrings && schema.Type == "string" {
+		switch value.(type) {
+		case json.Number, bool:
+			value = fmt.Sprint(value)
+		}
+	}
+	if schema.Type != "" && !jsonTypeMatches(schema.Type, value) {
+		v.fail(path, "expected %s, got %s", schema.Type, jsonTypeName(value))
+		return
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		v.fail(path, "%s is not one of %s", formatJSONValue(value), formatEnum(schema.Enum))
+	}
+
+	if s, ok := value.(string); ok && schema.Pattern != "" {
+		if re, err := regexp.Compile(schema.Pattern); err == nil && !re.MatchString(s) {
+			if schema.Description != "" {
+				v.fail(path, "%q is not a valid %s", s, schema.Description)
+			} else {
+				v.fail(path, "%q does not match %s", s, schema.Pattern)
+			}
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := value[name]; !ok {
+				v.fail(path, "missing required key %q", name)
+			}
+		}
+
+		var additional *JSONSchema
+		allowAdditional := true
+		if len(schema.AdditionalProperties) > 0 {
+			if err := json.Unmarshal(schema.AdditionalProperties, &allowAdditional); err != nil {
+				allowAdditional = true
+				additional = &JSONSchema{}
+				json.Unmarshal(schema.AdditionalProperties, additional)
+			}
+		}
+
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := joinJSONPath(path, key)
+			if schema.PropertyNames != nil && len(schema.PropertyNames.Enum) > 0 && !enumContains(schema.PropertyNames.Enum, key) {
+				v.fail(child, "unknown key (expected one of %s)", formatEnum(schema.PropertyNames.Enum))
+				continue
+			}
+			if prop, ok := schema.Properties[key]; ok {
+				v.validate(prop, value[key], child)
+				continue
+			}
+			switch {
+			case additional != nil:
+				v.validate(additional, value[key], child)
+			case !allowAdditional:
+				v.fail(child, "unknown key")
+			}
+		}
+
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range value {
+				v.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+}
+
+// joinJSONPath appends an object key to a dotted document path
+func joinJSONPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// jsonTypeMatches reports whether a decoded value has the given schema type
+func jsonTypeMatches(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return jsonTypeName(value) == schemaType
+	}
+}
+
+// jsonTypeName returns the JSON type of a decoded value
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// enumContains reports whether value equals one of the enum values
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if formatJSONValue(allowed) == formatJSONValue(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatJSONValue renders a decoded value as compact JSON
+func formatJSONValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// formatEnum renders enum values as a comma-separated list
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		values[i] = formatJSONValue(value)
+	}
+	return strings.Join(values, ", ")
+}
+
+// jsonOffsets maps document paths ("permissions.allow[0]") to the byte offset
+// of the object key or array element that introduces them
+func jsonOffsets(data []byte) map[string]int {
+	offsets := map[string]int{"": skipJSONSpace(data, 0)}
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		delim, ok := tok.(json.Delim)
+		if !ok {
+			return nil
+		}
+		switch delim {
+		case '{':
+			for dec.More() {
+				start := skipJSONSpace(data, int(dec.InputOffset()))
+				keyTok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := keyTok.(string)
+				child := joinJSONPath(path, key)
+				offsets[child] = start
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		case '[':
+			for i := 0; dec.More(); i++ {
+				child := fmt.Sprintf("%s[%d]", path, i)
+				offsets[child] = skipJSONSpace(data, int(dec.InputOffset()))
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		}
+		_, err = dec.Token() // closing delimiter
+		return err
+	}
+	walk("")
+	return offsets
+}
+
+// skipJSONSpace advances offset past whitespace and the ',' / ':' separators
+// that json.Decoder leaves before the next token
+func skipJSONSpace(data []byte, offset int) int {
+	for offset < len(data) {
+		switch data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column
+func offsetPosition(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	col := offset - bytes.LastIndexByte(data[:offset], '\n')
+	return line, col
+}
+
+// settingsFilesToValidate returns the settings files that exist in the target
+func (e *Engine) settingsFilesToValidate() []string {
+	var files []string
+	for _, name := range []string{settingsFile, localSettingsFile} {
+		path := filepath.Join(e.config.TargetDir, ".claude", name)
+		if e.fs.Exists(path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// validateSettingsFiles checks the target's settings files against the
+// schema, logging each violation, and returns the number of violations
+func (e *Engine) validateSettingsFiles(logf func(format string, args ...interface{})) (int, error) {
+	problems := 0
+	for _, path := range e.settingsFilesToValidate() {
+		data, err := e.fs.ReadFile(path)
+		if err != nil {
+			return problems, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		errs, err := ValidateSettings(data)
+		if err != nil {
+			return problems, err
+		}
+
+		// Deprecated keys get a migration hint instead of schema errors
+		var deprecated []SettingsMigration
+		if settings, err := ParseSettings(data); err == nil {
+			deprecated = deprecatedSettings(settings)
+		}
+		for _, migration := range deprecated {
+			logf("%s", describeMigration(e.formatPath(path), migration))
+			problems++
+		}
+		for _, schemaErr := range errs {
+			if coveredByMigration(schemaErr.Path, deprecated) {
+				continue
+			}
+			logf("%s:%s", e.formatPath(path), schemaErr.Error())
+			problems++
+		}
+	}
+	return problems, nil
+}
+
+// checkSettings warns about schema violations in the target's settings files,
+// and about settings the managed settings override, after init has written
+// them
+func (e *Engine) checkSettings() error {
+	if _, err := e.validateSettingsFiles(e.logger.Warning); err != nil {
+		return err
+	}
+	return e.checkManagedSettings()
+}
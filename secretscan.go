@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// Secret scan policies for --secret-scan
+const (
+	SecretScanOff   = "off"
+	SecretScanWarn  = "warn"
+	SecretScanError = "error"
+)
+
+// secretPattern recognizes one kind of credential
+type secretPattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+// secretPatterns match credentials by their well-known shapes; they favor
+// precision over recall, since every match interrupts the user
+var secretPatterns = []secretPattern{
+	{"AWS access key ID", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"AWS secret access key", regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[:=]\s*["']?[A-Za-z0-9/+]{40}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"Anthropic API key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+	{"OpenAI API key", regexp.MustCompile(`\bsk-(proj-)?[A-Za-z0-9]{32,}`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"private key", regexp.MustCompile(`-----BEGIN ([A-Z]+ )?PRIVATE KEY-----`)},
+}
+
+// SecretFinding is a line that looks like it holds a credential
+type SecretFinding struct {
+	Line int
+	Kind string
+}
+
+// validateSecretScan checks a --secret-scan policy; empty means warn
+func validateSecretScan(mode string) error {
+	switch mode {
+	case "", SecretScanOff, SecretScanWarn, SecretScanError:
+		return nil
+	default:
+		return fmt.Errorf("unknown --secret-scan policy %q (expected %s, %s or %s)", mode, SecretScanOff, SecretScanWarn, SecretScanError)
+	}
+}
+
+// scanSecrets returns the lines of content that look like credentials, at
+// most one finding per line
+func scanSecrets(content []byte) []SecretFinding {
+	var findings []SecretFinding
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		for _, pattern := range secretPatterns {
+			if pattern.re.Match(line) {
+				findings = append(findings, SecretFinding{Line: i + 1, Kind: pattern.kind})
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// checkSecrets scans content about to be written to targetPath. Findings are
+// logged without the matched text; under the error policy the file is
+// refused.
+func (e *Engine) checkSecrets(targetPath string, content []byte) error {
+	if e.config.SecretScan == SecretScanOff {
+		return nil
+	}
+	findings := scanSecrets(content)
+	for _, finding := range findings {
+		e.logger.Warning("%s:%d looks like it contains a %s", e.formatPath(targetPath), finding.Line, finding.Kind)
+	}
+	if len(findings) > 0 && e.config.SecretScan == SecretScanError {
+		return fmt.Errorf("refusing to write %s: line %d looks like it contains a %s (--secret-scan %s)", e.formatPath(targetPath), findings[0].Line, findings[0].Kind, SecretScanError)
+	}
+	return nil
+}
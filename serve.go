@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Paths below the base URL of an HTTP template source
+const (
+	packIndexPath   = "index.json"
+	packArchivePath = "templates.tar.gz"
+)
+
+// PackIndex describes a template pack served over HTTP: its digest, as
+// printed by `cc-init hash`, its files and the archive holding them
+type PackIndex struct {
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	PackVersion string   `json:"pack_version,omitempty"`
+	Version     string   `json:"version"`
+	Digest      string   `json:"digest"`
+	Files       []string `json:"files"`
+	Archive     string   `json:"archive"`
+}
+
+// buildPackArchive packs the files of a template pack into a tar.gz archive
+// and describes it in an index. Entries carry fixed modes and times, so the
+// archive only changes with the templates.
+func buildPackArchive(pack fs.FS) (*PackIndex, []byte, error) {
+	tm := NewTemplateManager(pack, ".")
+	entries := map[string]*archiveEntry{}
+	var names, files []string
+	err := tm.Walk(func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		names = append(names, p)
+		if entry.IsDir() {
+			entries[p] = &archiveEntry{mode: tm.GetDefaultDirMode(), dir: true, uid: -1, gid: -1, mtime: time.Unix(0, 0)}
+			return nil
+		}
+		data, err := tm.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files = append(files, p)
+		entries[p] = &archiveEntry{content: data, mode: tm.GetDefaultFileMode(p), uid: -1, gid: -1, mtime: time.Unix(0, 0)}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	digest, err := tm.Digest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var archive bytes.Buffer
+	if err := writeTar(&archive, packArchivePath, names, entries); err != nil {
+		return nil, nil, err
+	}
+	index := &PackIndex{Version: version, Digest: digest, Files: files, Archive: packArchivePath}
+	manifest, err := loadPackManifest(pack)
+	if err != nil {
+		return nil, nil, err
+	}
+	if manifest != nil {
+		index.Name = manifest.Name
+		index.Description = manifest.Description
+		index.PackVersion = manifest.Version
+	}
+	return index, archive.Bytes(), nil
+}
+
+// runServe implements `cc-init serve`, which shares the embedded templates
+// or a local pack as an HTTP template source
+func runServe(args []string) error {
+	cmd := findCommand("serve")
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", tr("Address to listen on"))
+	templateDir := fs.String("template-dir", "", tr("Template pack to serve, laid out like .claude (default: the embedded templates)"))
+	set := fs.String("set", "", tr("Embedded template set to serve: ")+strings.Join(templateSetNames(), ", "))
+	noColor := fs.Bool("no-color", false, tr("Disable colored output"))
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %v", positional)
+	}
+
+	host, port, err := net.SplitHostPort(*addr)
+	if err != nil {
+		return fmt.Errorf("invalid --addr %q: %w", *addr, err)
+	}
+	if host == "" {
+		host = "<this host>"
+	}
+
+	pack, err := openPack(*templateDir, *set)
+	if err != nil {
+		return err
+	}
+	// The pack is read once, so index and archive always agree; restart to
+	// publish edits
+	index, archive, err := buildPackArchive(pack)
+	if err != nil {
+		return fmt.Errorf("failed to pack templates: %w", err)
+	}
+	indexJSON, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	logger := NewLogger(false, *noColor)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+packIndexPath, func(w http.ResponseWriter, r *http.Request) {
+		logger.Info("%s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(append(indexJSON, '\n'))
+	})
+	mux.HandleFunc("/"+packArchivePath, func(w http.ResponseWriter, r *http.Request) {
+		logger.Info("%s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Write(archive)
+	})
+
+	logger.Success("Serving %d %s (%s) on %s", len(index.Files), pluralize("template", len(index.Files)), index.Digest, *addr)
+	logger.Info("Use it with: cc-init --template-dir http://%s/", net.JoinHostPort(host, port))
+	return http.ListenAndServe(*addr, mux)
+}
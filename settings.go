@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// settingsFile is the shared project settings file inside .claude
+const settingsFile = "settings.json"
+
+// Permission rule lists inside the settings "permissions" block
+const (
+	PermissionAllow = "allow"
+	PermissionDeny  = "deny"
+)
+
+// permissionRulePattern matches "Tool" or "Tool(specifier)"
+var permissionRulePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*(\(.+\))?$`)
+
+// ruleGroups are named bundles of permission rules usable as "@name" in --allow/--deny
+var ruleGroups = map[string][]string{
+	"git": {
+		"Bash(git status:*)",
+		"Bash(git diff:*)",
+		"Bash(git log:*)",
+		"Bash(git show:*)",
+	},
+	"go": {
+		"Bash(go build:*)",
+		"Bash(go test:*)",
+		"Bash(go vet:*)",
+		"Bash(go fmt:*)",
+		"Bash(go mod tidy)",
+	},
+	"node": {
+		"Bash(npm run:*)",
+		"Bash(npm test:*)",
+		"Bash(npx eslint:*)",
+	},
+	"python": {
+		"Bash(pytest:*)",
+		"Bash(python -m pytest:*)",
+		"Bash(ruff:*)",
+	},
+	"search": {
+		"Bash(ls:*)",
+		"Bash(find:*)",
+		"Bash(grep:*)",
+		"Bash(rg:*)",
+	},
+	"secrets": {
+		"Read(.env)",
+		"Read(.env.*)",
+		"Read(**/*.pem)",
+		"Read(**/*.key)",
+	},
+	"network": {
+		"WebFetch",
+		"Bash(curl:*)",
+		"Bash(wget:*)",
+	},
+}
+
+// PermissionPreset is a curated permission policy selectable with --permissions
+type PermissionPreset struct {
+	Description string
+	Allow       []string
+	Deny        []string
+}
+
+// permissionPresets are the policies accepted by --permissions; rules may
+// reference rule groups with "@name"
+var permissionPresets = map[string]PermissionPreset{
+	"strict": {
+		Description: "Read-only git and search tools; deny network access and secret files",
+		Allow:       []string{"@search", "Bash(git status:*)", "Bash(git diff:*)"},
+		Deny:        []string{"@network", "@secrets", "Bash(git push:*)"},
+	},
+	"standard": {
+		Description: "Search and git inspection tools; deny secret files",
+		Allow:       []string{"@search", "@git"},
+		Deny:        []string{"@secrets"},
+	},
+	"permissive": {
+		Description: "Common build and test tools for Go, Node and Python; deny secret files",
+		Allow:       []string{"@search", "@git", "@go", "@node", "@python"},
+		Deny:        []string{"@secrets"},
+	},
+}
+
+// Settings is a decoded Claude Code settings document
+type Settings map[string]interface{}
+
+// ParseSettings decodes a settings document, treating empty input as an empty
+// object and tolerating JSONC comments and trailing commas
+func ParseSettings(data []byte) (Settings, error) {
+	settings := Settings{}
+	data = stripJSONC(data)
+	if len(bytes.TrimSpace(data)) == 0 {
+		return settings, nil
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("invalid settings JSON: %w", err)
+	}
+	return settings, nil
+}
+
+// Marshal encodes the settings as indented JSON with a trailing newline
+func (s Settings) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode settings: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// AddPermissionRules appends rules to the given permissions list, skipping duplicates
+func (s Settings) AddPermissionRules(kind string, rules []string) {
+	if len(rules) == 0 {
+		return
+	}
+
+	permissions, _ := s["permissions"].(map[string]interface{})
+	if permissions == nil {
+		permissions = map[string]interface{}{}
+		s["permissions"] = permissions
+	}
+
+	existing, _ := permissions[kind].([]interface{})
+	seen := make(map[string]bool, len(existing))
+	for _, rule := range existing {
+		if str, ok := rule.(string); ok {
+			seen[str] = true
+		}
+	}
+	for _, rule := range rules {
+		if seen[rule] {
+			continue
+		}
+		seen[rule] = true
+		existing = append(existing, rule)
+	}
+	permissions[kind] = existing
+}
+
+// jsonEqual reports whether raw decodes to the same JSON value as value
+func jsonEqual(raw []byte, value interface{}) bool {
+	var decoded interface{}
+	if err := json.Unmarshal(stripJSONC(raw), &decoded); err != nil {
+		return false
+	}
+	a, err := json.Marshal(decoded)
+	if err != nil {
+		return false
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(a, b)
+}
+
+// expandPermissionRules resolves "@group" references and validates rule syntax
+func expandPermissionRules(rules []string) ([]string, error) {
+	var expanded []string
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if strings.HasPrefix(rule, "@") {
+			group, ok := ruleGroups[strings.TrimPrefix(rule, "@")]
+			if !ok {
+				return nil, fmt.Errorf("unknown permission rule group %q (available: %s)", rule, strings.Join(ruleGroupNames(), ", "))
+			}
+			expanded = append(expanded, group...)
+			continue
+		}
+		if !permissionRulePattern.MatchString(rule) {
+			return nil, fmt.Errorf("invalid permission rule %q (expected Tool or Tool(specifier))", rule)
+		}
+		expanded = append(expanded, rule)
+	}
+	return expanded, nil
+}
+
+// validatePermissionPreset checks that name is empty or a known preset
+func validatePermissionPreset(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := permissionPresets[name]; !ok {
+		return fmt.Errorf("unknown permissions preset %q (available: %s)", name, strings.Join(permissionPresetNames(), ", "))
+	}
+	return nil
+}
+
+// permissionPresetNames returns the sorted names of the permission presets
+func permissionPresetNames() []string {
+	names := make([]string, 0, len(permissionPresets))
+	for name := range permissionPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ruleGroupNames returns the sorted names of the built-in rule groups
+func ruleGroupNames() []string {
+	names := make([]string, 0, len(ruleGroups))
+	for name := range ruleGroups {
+		names = append(names, "@"+name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// concatStrings joins lists into a new slice
+func concatStrings(lists ...[]string) []string {
+	var out []string
+	for _, list := range lists {
+		out = append(out, list...)
+	}
+	return out
+}
+
+// hasSettingsPatch reports whether any flag contributes to settings.json;
+// stripping what managed settings override and adding the deny rules of the
+// policy also rewrite the template's file
+func (e *Engine) hasSettingsPatch() bool {
+	return len(e.config.Allow) > 0 || len(e.config.Deny) > 0 || len(e.config.Hooks) > 0 ||
+		e.config.PermissionPreset != "" || e.config.Preset != "" || e.config.Statusline != "" || e.config.Migrate ||
+		e.config.ManagedSettings == ManagedStrip || (e.config.Policy != nil && len(e.config.Policy.Deny) > 0)
+}
+
+// generateSettings merges flag-provided settings into .claude/settings.json,
+// starting from the template's settings.json when the pack ships one
+func (e *Engine) generateSettings() error {
+	if !e.hasSettingsPatch() {
+		return nil
+	}
+
+	// Preset rules come first so explicit flags read as additions to the policy
+	preset := permissionPresets[e.config.PermissionPreset]
+	framework := frameworkPresets[e.config.Preset]
+	allow, err := expandPermissionRules(concatStrings(preset.Allow, framework.Allow, e.config.Allow))
+	if err != nil {
+		return err
+	}
+	deny, err := expandPermissionRules(concatStrings(preset.Deny, framework.Deny, e.config.Deny))
+	if err != nil {
+		return err
+	}
+	if e.config.Policy != nil {
+		deny = concatStrings(deny, e.config.Policy.Deny)
+	}
+
+	return e.updateSettings(func(settings Settings) {
+		if e.config.Migrate {
+			for _, migration := range MigrateSettings(settings) {
+				e.logger.Info("Migrated %s: %s", migration.Path, migration.Description)
+			}
+		}
+		settings.AddPermissionRules(PermissionAllow, allow)
+		settings.AddPermissionRules(PermissionDeny, deny)
+		e.applyHookPresets(settings, e.config.Hooks)
+		if e.config.Statusline != "" {
+			settings.SetStatusLine(".claude/" + statuslineScript)
+		}
+	})
+}
+
+// updateSettings applies patch to .claude/settings.json, starting from the
+// template's settings.json when the file does not exist yet
+func (e *Engine) updateSettings(patch func(Settings)) error {
+	targetPath := filepath.Join(e.config.TargetDir, ".claude", settingsFile)
+	return e.updateFile(targetPath, 0644, func(existing []byte) ([]byte, error) {
+		base := existing
+		if base == nil {
+			// Ignore a missing template settings.json; start from an empty object
+			base, _ = e.tmpl.ReadFile(settingsFile)
+		}
+		settings, err := ParseSettings(base)
+		if err != nil {
+			return nil, err
+		}
+		patch(settings)
+		if err := e.stripManagedSettings(settings); err != nil {
+			return nil, err
+		}
+		if existing != nil && jsonEqual(existing, settings) {
+			// Keep the user's formatting when nothing changed
+			return existing, nil
+		}
+		data, err := settings.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		data, dropped := preserveJSONComments(existing, data)
+		if dropped {
+			e.logger.Warning("Comments inside %s could not be preserved; only leading comments were kept", e.formatPath(targetPath))
+		}
+		return data, nil
+	})
+}
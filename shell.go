@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Shells that templates can render variants for with .Shell
+const (
+	ShellBash = "bash"
+	ShellZsh  = "zsh"
+	ShellFish = "fish"
+	ShellPwsh = "pwsh"
+)
+
+// validateShell checks the --shell value; empty means detect
+func validateShell(shell string) error {
+	switch shell {
+	case "", ShellBash, ShellZsh, ShellFish, ShellPwsh:
+		return nil
+	default:
+		return fmt.Errorf("unknown --shell %q (expected %s, %s, %s or %s)", shell, ShellBash, ShellZsh, ShellFish, ShellPwsh)
+	}
+}
+
+// detectShell returns the user's shell from $SHELL, or pwsh on Windows
+// without one. Unknown shells and remote targets, whose users cc-init cannot
+// see, get bash.
+func detectShell(getenv func(string) string, remote bool) string {
+	if remote {
+		return ShellBash
+	}
+	name := strings.TrimSuffix(filepath.Base(getenv("SHELL")), ".exe")
+	switch name {
+	case ShellZsh, ShellFish, ShellBash:
+		return name
+	case "pwsh", "powershell":
+		return ShellPwsh
+	}
+	if runtime.GOOS == "windows" && getenv("SHELL") == "" {
+		return ShellPwsh
+	}
+	return ShellBash
+}
+
+// userShell returns the shell templates render for: --shell, else detected
+func (e *Engine) userShell() string {
+	if e.config.Shell != "" {
+		return e.config.Shell
+	}
+	return detectShell(os.Getenv, e.config.Remote != nil)
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Layout of a snapshot test case directory: the cc-init flags to run with,
+// one per line, and the tree they are expected to produce
+const (
+	snapshotArgsFile  = "args"
+	snapshotGoldenDir = "golden"
+)
+
+// snapshotArgsTemplate is the args file of a case created by --update
+const snapshotArgsTemplate = "# cc-init flags for this case, one per line, e.g.\n# --permissions=strict\n"
+
+// SnapshotCase is a fixture: a named set of flags and its golden tree
+type SnapshotCase struct {
+	Name string
+	Dir  string
+	Args []string
+}
+
+// loadSnapshotCases reads every case below dir, in name order
+func loadSnapshotCases(dir string) ([]SnapshotCase, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var cases []SnapshotCase
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		c := SnapshotCase{Name: entry.Name(), Dir: filepath.Join(dir, entry.Name())}
+		data, err := os.ReadFile(filepath.Join(c.Dir, snapshotArgsFile))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				c.Args = append(c.Args, line)
+			}
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// renderSnapshot runs cc-init with the flags of a case into a new temporary
+// directory and returns it
+func renderSnapshot(templateDir string, c SnapshotCase) (string, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp("", "cc-init-test-")
+	if err != nil {
+		return "", err
+	}
+	// The environment must not add side effects to a test run
+	args := []string{"--target", dir, "--report", ReportQuiet, "--log-level", LogLevelWarn, "--no-color", "--audit-log=", "--notify-url="}
+	if templateDir != "" {
+		args = append(args, "--template-dir", templateDir)
+	}
+	cmd := exec.Command(self, append(args, c.Args...)...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(dir)
+		// Flag errors are followed by the full usage text
+		message, _, _ := strings.Cut(strings.TrimSpace(output.String()), "\n")
+		return "", fmt.Errorf("cc-init failed: %s", message)
+	}
+	return dir, nil
+}
+
+// readSnapshotTree reads the files below root by slash-separated relative
+// path. The lock and provenance files change with every cc-init build, so
+// they are left out.
+func readSnapshotTree(root string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == root {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == lockFile || rel == provenanceFile {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[rel] = data
+		return nil
+	})
+	return files, err
+}
+
+// compareSnapshot lists how got differs from want, one line per file
+func compareSnapshot(want, got map[string][]byte) []string {
+	paths := make([]string, 0, len(want)+len(got))
+	for p := range want {
+		paths = append(paths, p)
+	}
+	for p := range got {
+		if _, ok := want[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var diffs []string
+	for _, p := range paths {
+		expected, inWant := want[p]
+		actual, inGot := got[p]
+		switch {
+		case !inGot:
+			diffs = append(diffs, fmt.Sprintf(tr("%s: missing"), p))
+		case !inWant:
+			diffs = append(diffs, fmt.Sprintf(tr("%s: not in snapshot"), p))
+		case !bytes.Equal(expected, actual):
+			added, removed := 0, 0
+			for _, line := range diffLines(splitLines(string(expected)), splitLines(string(actual))) {
+				switch line.Kind {
+				case '+':
+					added++
+				case '-':
+					removed++
+				}
+			}
+			diffs = append(diffs, fmt.Sprintf(tr("%s: differs (+%d -%d)"), p, added, removed))
+		}
+	}
+	return diffs
+}
+
+// writeSnapshot replaces the golden tree of a case with files
+func writeSnapshot(dir string, files map[string][]byte) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	for rel, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			return err
+		}
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+// runTest implements `cc-init test`, which renders a template pack for each
+// fixture and compares the result with its golden snapshot
+func runTest(args []string) error {
+	cmd := findCommand("test")
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	templateDir := fs.String("template-dir", "", tr("Template pack to test, laid out like .claude (default: the embedded templates)"))
+	fixtures := fs.String("fixtures", "testdata", tr("Directory with one subdirectory per test case"))
+	update := fs.Bool("update", false, tr("Write the rendered trees as the new golden snapshots"))
+	noColor := fs.Bool("no-color", false, tr("Disable colored output"))
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %v", positional)
+	}
+	if *templateDir != "" {
+		if _, err := openPack(*templateDir, ""); err != nil {
+			return err
+		}
+	}
+
+	cases, err := loadSnapshotCases(*fixtures)
+	if err != nil {
+		return err
+	}
+	if len(cases) == 0 {
+		if !*update {
+			return fmt.Errorf("no test cases in %s; run with --update to create one", *fixtures)
+		}
+		c := SnapshotCase{Name: "default", Dir: filepath.Join(*fixtures, "default")}
+		if err := os.MkdirAll(c.Dir, 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(c.Dir, snapshotArgsFile), []byte(snapshotArgsTemplate), 0644); err != nil {
+			return err
+		}
+		cases = append(cases, c)
+	}
+
+	logger := NewLogger(false, *noColor)
+	failed := 0
+	for _, c := range cases {
+		dir, err := renderSnapshot(*templateDir, c)
+		if err != nil {
+			logger.Error("%s: %v", c.Name, err)
+			failed++
+			continue
+		}
+		got, err := readSnapshotTree(dir)
+		os.RemoveAll(dir)
+		if err != nil {
+			return err
+		}
+
+		golden := filepath.Join(c.Dir, snapshotGoldenDir)
+		if *update {
+			if err := writeSnapshot(golden, got); err != nil {
+				return fmt.Errorf("failed to update snapshot of %s: %w", c.Name, err)
+			}
+			logger.Success("%s: updated snapshot", c.Name)
+			continue
+		}
+		want, err := readSnapshotTree(golden)
+		if err != nil {
+			return err
+		}
+		diffs := compareSnapshot(want, got)
+		if len(diffs) == 0 {
+			logger.Success("%s: matches snapshot", c.Name)
+			continue
+		}
+		failed++
+		logger.Error("%s: does not match snapshot", c.Name)
+		for _, diff := range diffs {
+			logger.Info("%s", diff)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test %s failed; run with --update to accept the changes", failed, len(cases), pluralize("case", len(cases)))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// statuslineScript is the installed status line script, relative to .claude
+const statuslineScript = "statusline.sh"
+
+// StatuslineStyle is a built-in status line script
+type StatuslineStyle struct {
+	Description string
+	Script      string
+}
+
+// statuslineStyles lists the styles selectable with --statusline
+var statuslineStyles = map[string]StatuslineStyle{
+	"git": {
+		Description: "Current git branch and model name",
+		Script:      "git.sh",
+	},
+	"cost": {
+		Description: "Session cost and context window usage",
+		Script:      "cost.sh",
+	},
+}
+
+// SetStatusLine points the statusLine setting at command unless one is configured
+func (s Settings) SetStatusLine(command string) {
+	if _, exists := s["statusLine"]; exists {
+		return
+	}
+	s["statusLine"] = map[string]interface{}{
+		"type":    "command",
+		"command": command,
+		"padding": 0,
+	}
+}
+
+// validateStatuslineStyle checks that name is empty or a known style
+func validateStatuslineStyle(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := statuslineStyles[name]; !ok {
+		return fmt.Errorf("unknown statusline style %q (available: %s)", name, strings.Join(statuslineStyleNames(), ", "))
+	}
+	return nil
+}
+
+// statuslineStyleNames returns the sorted names of the status line styles
+func statuslineStyleNames() []string {
+	names := make([]string, 0, len(statuslineStyles))
+	for name := range statuslineStyles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// generateStatusline installs the selected status line script
+func (e *Engine) generateStatusline() error {
+	if e.config.Statusline == "" {
+		return nil
+	}
+
+	style := statuslineStyles[e.config.Statusline]
+	content, err := presetFS.ReadFile(path.Join("presets", "statusline", style.Script))
+	if err != nil {
+		return fmt.Errorf("failed to read statusline style %s: %w", e.config.Statusline, err)
+	}
+	return e.installFile(filepath.Join(e.config.TargetDir, ".claude", statuslineScript), content, 0755)
+}
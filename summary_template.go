@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// summaryFuncs are the helpers available to --summary-format templates
+var summaryFuncs = template.FuncMap{
+	"counts":    describeCounts,
+	"pluralize": pluralize,
+	"join":      strings.Join,
+}
+
+// parseSummaryTemplate parses a --summary-format value. A value starting
+// with @ names a file holding the template.
+func parseSummaryTemplate(format string) (*template.Template, error) {
+	text := format
+	if name, ok := strings.CutPrefix(format, "@"); ok {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read summary template: %w", err)
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New("summary").Funcs(summaryFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid summary format: %w", err)
+	}
+	return tmpl, nil
+}
+
+// TemplateReporter renders the Report through a user-supplied Go template
+type TemplateReporter struct {
+	tmpl   *template.Template
+	writer io.Writer
+}
+
+// Report executes the template, ending the output with a newline
+func (r *TemplateReporter) Report(report *Report) error {
+	var b strings.Builder
+	if err := r.tmpl.Execute(&b, report); err != nil {
+		return fmt.Errorf("failed to render summary: %w", err)
+	}
+	out := b.String()
+	if out != "" && !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err := io.WriteString(r.writer, out)
+	return err
+}
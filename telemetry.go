@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// telemetryURLEnv names the environment variable with the endpoint usage
+// events are sent to; without it nothing leaves the machine
+const telemetryURLEnv = "CC_INIT_TELEMETRY_URL"
+
+// telemetryTimeout bounds how long sending an event can delay exit
+const telemetryTimeout = 2 * time.Second
+
+// TelemetrySettings is the user's telemetry choice, stored outside any
+// project so it applies to every run
+type TelemetrySettings struct {
+	Enabled bool `json:"enabled"`
+}
+
+// TelemetryEvent is all that is sent about a run: which command ran, how
+// long it took and what kind of error it ended with. It never carries
+// paths, file contents or user names.
+type TelemetryEvent struct {
+	Command    string `json:"command"`
+	DurationMS int64  `json:"duration_ms"`
+	ErrorClass string `json:"error_class,omitempty"`
+	Version    string `json:"version"`
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+}
+
+// telemetrySettingsPath returns where the telemetry choice is stored
+func telemetrySettingsPath() (string, error) {
+	dir, err := userConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "telemetry.json"), nil
+}
+
+// loadTelemetrySettings reads the telemetry choice; telemetry is off unless
+// the user turned it on, and DO_NOT_TRACK always turns it off
+func loadTelemetrySettings() TelemetrySettings {
+	var settings TelemetrySettings
+	if os.Getenv("DO_NOT_TRACK") != "" && os.Getenv("DO_NOT_TRACK") != "0" {
+		return settings
+	}
+	path, err := telemetrySettingsPath()
+	if err != nil {
+		return settings
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return settings
+	}
+	json.Unmarshal(data, &settings)
+	return settings
+}
+
+// saveTelemetrySettings stores the telemetry choice
+func saveTelemetrySettings(settings TelemetrySettings) error {
+	path, err := telemetrySettingsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// errorClass reduces the error of a run to a coarse category, so events
+// never carry error messages that may contain paths
+func errorClass(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrChangesPending):
+		return "changes_pending"
+	case errors.Is(err, ErrCheckFailed):
+		return "check_failed"
+	case errors.Is(err, os.ErrNotExist):
+		return "not_exist"
+	case errors.Is(err, os.ErrPermission):
+		return "permission"
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return "timeout"
+	default:
+		return "other"
+	}
+}
+
+// sendTelemetry posts a usage event if the user opted in and an endpoint is
+// configured. It is best effort: failures are ignored.
+func sendTelemetry(command string, duration time.Duration, err error) {
+	endpoint := os.Getenv(telemetryURLEnv)
+	if endpoint == "" || !loadTelemetrySettings().Enabled {
+		return
+	}
+	body, marshalErr := json.Marshal(TelemetryEvent{
+		Command:    command,
+		DurationMS: duration.Milliseconds(),
+		ErrorClass: errorClass(err),
+		Version:    version,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+	})
+	if marshalErr != nil {
+		return
+	}
+	client := &http.Client{Timeout: telemetryTimeout}
+	resp, postErr := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if postErr != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+// runTelemetry implements `cc-init telemetry on|off|status`
+func runTelemetry(args []string) error {
+	cmd := findCommand("telemetry")
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one of on, off, status")
+	}
+
+	switch positional[0] {
+	case "on", "off":
+		if err := saveTelemetrySettings(TelemetrySettings{Enabled: positional[0] == "on"}); err != nil {
+			return fmt.Errorf("failed to save telemetry settings: %w", err)
+		}
+	case "status":
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown telemetry action %q (expected on, off or status)", positional[0])
+	}
+
+	path, _ := telemetrySettingsPath()
+	if loadTelemetrySettings().Enabled {
+		fmt.Printf(tr("Telemetry is on (%s)\n"), path)
+	} else {
+		fmt.Printf(tr("Telemetry is off (%s)\n"), path)
+	}
+	if endpoint := os.Getenv(telemetryURLEnv); endpoint != "" {
+		fmt.Printf(tr("Events are sent to %s\n"), endpoint)
+	} else {
+		fmt.Printf(tr("No events are sent until %s is set\n"), telemetryURLEnv)
+	}
+	return nil
+}
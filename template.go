@@ -1,23 +1,98 @@
 package main
 
 import (
-	"embed"
 	"fmt"
 	"io/fs"
 	"path"
+	"sort"
 	"strings"
 )
 
-// TemplateManager manages the embedded template files
+// defaultTemplateSet names the templates in .claude, which are installed
+// unless --set or the lockfile selects another set
+const defaultTemplateSet = "full"
+
+// templateSetNames lists the embedded template sets: the default set plus
+// every directory below sets/
+func templateSetNames() []string {
+	names := []string{defaultTemplateSet}
+	entries, _ := fs.ReadDir(templateFiles(), "sets")
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != defaultTemplateSet {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateTemplateSet checks a --set name; empty selects the default
+func validateTemplateSet(name string) error {
+	if name == "" {
+		return nil
+	}
+	for _, known := range templateSetNames() {
+		if name == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown template set %q (expected one of: %s)", name, strings.Join(templateSetNames(), ", "))
+}
+
+// openTemplateSet returns the files of an embedded template set
+func openTemplateSet(name string) (fs.FS, error) {
+	if name == "" || name == defaultTemplateSet {
+		return fs.Sub(templateFiles(), ".claude")
+	}
+	if err := validateTemplateSet(name); err != nil {
+		return nil, err
+	}
+	return fs.Sub(templateFiles(), path.Join("sets", name))
+}
+
+// projectTrees are the top-level entries of a template pack installed at the
+// root of the project rather than below .claude
+var projectTrees = map[string]bool{
+	".github":    true,
+	mcpFile:      true,
+	claudeMDFile: true,
+}
+
+// templateTarget returns where a template path is installed, relative to the
+// target directory
+func templateTarget(p string) string {
+	if isTemplateFile(p) {
+		p = strings.TrimSuffix(p, templateSuffix)
+	}
+	top, _, _ := strings.Cut(p, "/")
+	if projectTrees[top] {
+		return p
+	}
+	return ".claude/" + p
+}
+
+// templateSource returns the template path installed at rel, a path relative
+// to the target directory, without a .tmpl suffix; ok is false when no
+// template path maps there
+func templateSource(rel string) (p string, ok bool) {
+	p, ok = strings.CutPrefix(rel, ".claude/")
+	if !ok {
+		p = rel
+	}
+	return p, templateTarget(p) == rel
+}
+
+// TemplateManager manages the template files, embedded or from --template-dir
 type TemplateManager struct {
-	fs     embed.FS
+	fs     fs.FS
 	prefix string
 }
 
-// NewTemplateManager creates a new TemplateManager
-func NewTemplateManager(embedFS embed.FS, prefix string) *TemplateManager {
+// NewTemplateManager creates a new TemplateManager for the templates below
+// prefix in templates
+func NewTemplateManager(templates fs.FS, prefix string) *TemplateManager {
 	return &TemplateManager{
-		fs:     embedFS,
+		fs:     templates,
 		prefix: prefix,
 	}
 }
@@ -48,7 +123,7 @@ func (tm *TemplateManager) Walk(fn func(path string, entry fs.DirEntry, err erro
 // ReadFile reads a file from the embedded filesystem
 func (tm *TemplateManager) ReadFile(relPath string) ([]byte, error) {
 	fullPath := path.Join(tm.prefix, relPath)
-	data, err := tm.fs.ReadFile(fullPath)
+	data, err := fs.ReadFile(tm.fs, fullPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read embedded file %s: %w", fullPath, err)
 	}
@@ -74,7 +149,7 @@ func (tm *TemplateManager) GetFileInfo(relPath string) (fs.FileInfo, error) {
 
 // HasTemplates checks if the template directory exists and has content
 func (tm *TemplateManager) HasTemplates() bool {
-	entries, err := tm.fs.ReadDir(tm.prefix)
+	entries, err := fs.ReadDir(tm.fs, tm.prefix)
 	if err != nil {
 		return false
 	}
@@ -106,6 +181,8 @@ func (tm *TemplateManager) ListTemplates() ([]string, error) {
 
 // GetDefaultFileMode returns the default file mode for a template file
 func (tm *TemplateManager) GetDefaultFileMode(relPath string) fs.FileMode {
+	relPath = strings.TrimSuffix(relPath, templateSuffix)
+
 	// Check if it's a known executable type
 	if strings.HasSuffix(relPath, ".sh") || strings.HasSuffix(relPath, ".bash") {
 		return 0755
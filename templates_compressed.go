@@ -0,0 +1,180 @@
+//go:build compressed
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// templateArchive holds .claude and sets/ packed by `go generate`; release
+// builds embed it instead of the plain files to keep the binary small
+//
+//go:embed templates.tar.gz
+var templateArchive []byte
+
+var (
+	templatesOnce sync.Once
+	templates     fs.FS
+)
+
+// templateFiles returns the embedded templates, decompressing them on first
+// use
+func templateFiles() fs.FS {
+	templatesOnce.Do(func() {
+		files, err := readTemplateArchive(templateArchive)
+		if err != nil {
+			// The archive is generated with the binary, so this is a broken
+			// build rather than a user error
+			panic(fmt.Sprintf("corrupt embedded templates: %v", err))
+		}
+		templates = files
+	})
+	return templates
+}
+
+// readTemplateArchive unpacks a tar.gz archive into memory
+func readTemplateArchive(data []byte) (fs.FS, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	files := archiveFS{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(header.Name)
+		if !fs.ValidPath(name) || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("unsafe path %q in archive", header.Name)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = &archiveFile{data: content, mode: fs.FileMode(header.Mode).Perm()}
+	}
+}
+
+// archiveFS is a read-only fs.FS over the files of an unpacked archive, keyed
+// by slash-separated path; directories are derived from the file paths
+type archiveFS map[string]*archiveFile
+
+// archiveFile is the content and permissions of a file in an archiveFS
+type archiveFile struct {
+	data []byte
+	mode fs.FileMode
+}
+
+// Open opens a file for reading, or a directory for fs.ReadDirFile
+func (a archiveFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if file, ok := a[name]; ok {
+		info := &listedFileInfo{path: name, size: int64(len(file.data)), mode: file.mode}
+		return &openArchiveFile{Reader: bytes.NewReader(file.data), info: info}, nil
+	}
+	entries, err := a.ReadDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &openArchiveDir{info: &listedFileInfo{path: name, mode: fs.ModeDir | 0755}, entries: entries}, nil
+}
+
+// ReadFile returns a copy of the content of a file
+func (a archiveFS) ReadFile(name string) ([]byte, error) {
+	file, ok := a[name]
+	if !ok || !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrNotExist}
+	}
+	return bytes.Clone(file.data), nil
+}
+
+// ReadDir lists a directory, sorted by name
+func (a archiveFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	children := map[string]fs.FileInfo{}
+	for p, file := range a {
+		rest, ok := strings.CutPrefix(p, prefix)
+		if !ok {
+			continue
+		}
+		if child, _, isDir := strings.Cut(rest, "/"); isDir {
+			children[child] = &listedFileInfo{path: child, mode: fs.ModeDir | 0755}
+		} else {
+			children[child] = &listedFileInfo{path: child, size: int64(len(file.data)), mode: file.mode}
+		}
+	}
+	if len(children) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, info := range children {
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// openArchiveFile is a file of an archiveFS opened for reading
+type openArchiveFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (f *openArchiveFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *openArchiveFile) Close() error               { return nil }
+
+// openArchiveDir is a directory of an archiveFS opened for listing
+type openArchiveDir struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+}
+
+func (d *openArchiveDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *openArchiveDir) Close() error               { return nil }
+
+func (d *openArchiveDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: fs.ErrInvalid}
+}
+
+// ReadDir returns the next n entries, or all remaining ones when n <= 0
+func (d *openArchiveDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
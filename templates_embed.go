@@ -0,0 +1,21 @@
+//go:build !compressed
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// embeddedTemplates holds the templates as plain files: the default set in
+// .claude and the sets selectable with --set below sets/. The patterns name
+// the entries of each set, so top-level dotfiles such as .mcp.json and .github
+// are included.
+//
+//go:embed .claude/* sets/*/*
+var embeddedTemplates embed.FS
+
+// templateFiles returns the embedded templates
+func templateFiles() fs.FS {
+	return embeddedTemplates
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// templateFetchTimeout bounds each request to an HTTP template source
+const templateFetchTimeout = 30 * time.Second
+
+// isTemplateURL reports whether --template-dir names an HTTP template source
+// such as one started with `cc-init serve`
+func isTemplateURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// templateCacheDir returns where downloaded template packs are kept
+func templateCacheDir() (string, error) {
+	dir, err := userCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "templates"), nil
+}
+
+// httpGet fetches url and fails on non-2xx responses
+func httpGet(client *http.Client, url string) (io.ReadCloser, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: unexpected status %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// fetchTemplatePack downloads the pack of an HTTP template source into the
+// cache and returns its directory. Packs are stored by digest, so a pack
+// already downloaded is not fetched again.
+func fetchTemplatePack(source string) (string, error) {
+	base := strings.TrimSuffix(strings.TrimSuffix(source, packIndexPath), "/") + "/"
+	client := &http.Client{Timeout: templateFetchTimeout}
+
+	body, err := httpGet(client, base+packIndexPath)
+	if err != nil {
+		return "", err
+	}
+	var index PackIndex
+	err = json.NewDecoder(body).Decode(&index)
+	body.Close()
+	if err != nil {
+		return "", fmt.Errorf("invalid template index at %s: %w", base+packIndexPath, err)
+	}
+	sum, err := packDigestSum(index.Digest)
+	if err != nil {
+		return "", fmt.Errorf("%w at %s", err, base+packIndexPath)
+	}
+
+	cache, err := templateCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cache, sum)
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		touchCacheEntry(cache, sum, source, &index)
+		return dir, nil
+	}
+
+	archive := index.Archive
+	if archive == "" {
+		archive = packArchivePath
+	}
+	body, err = httpGet(client, base+archive)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	return storeTemplatePack(cache, source, base+archive, &index, body)
+}
+
+// packDigestSum returns the hex sum of a pack digest, which names the pack
+// in the cache
+func packDigestSum(digest string) (string, error) {
+	algo, sum, _ := strings.Cut(digest, ":")
+	if algo != "sha256" || sum == "" || strings.ContainsAny(sum, `/\.`) {
+		return "", fmt.Errorf("invalid template digest %q", digest)
+	}
+	return sum, nil
+}
+
+// storeTemplatePack extracts the pack archive read from from into the cache,
+// checks that its templates match the digest of index and returns the
+// directory of the pack
+func storeTemplatePack(cache, source, from string, index *PackIndex, archive io.Reader) (string, error) {
+	sum, err := packDigestSum(index.Digest)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cache, sum)
+	if err := os.MkdirAll(cache, 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.MkdirTemp(cache, ".download-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := extractTarGz(archive, tmp); err != nil {
+		return "", fmt.Errorf("failed to extract %s: %w", from, err)
+	}
+	digest, err := hashSource(tmp)
+	if err != nil {
+		return "", err
+	}
+	if digest != index.Digest {
+		return "", fmt.Errorf("templates from %s do not match their index: got %s, want %s", from, digest, index.Digest)
+	}
+	if err := os.Rename(tmp, dir); err != nil && !os.IsExist(err) {
+		// Another run may have stored the same pack meanwhile
+		if info, statErr := os.Stat(dir); statErr != nil || !info.IsDir() {
+			return "", err
+		}
+	}
+	touchCacheEntry(cache, sum, source, index)
+	return dir, nil
+}
+
+// extractTarGz unpacks the directories and regular files of a tar.gz
+// archive below dir, rejecting entries that would land outside it
+func extractTarGz(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(strings.TrimSuffix(header.Name, "/"))
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("unsafe path %q in archive", header.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(file, tr)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ThemeStyle is the marker and color of one kind of log message
+type ThemeStyle struct {
+	Symbol string `json:"symbol"`
+	Color  string `json:"color"`
+}
+
+// Theme holds the symbols and colors the Logger uses for each kind of message
+type Theme struct {
+	Success  ThemeStyle `json:"success"`
+	Warning  ThemeStyle `json:"warning"`
+	Error    ThemeStyle `json:"error"`
+	Progress ThemeStyle `json:"progress"`
+	Debug    ThemeStyle `json:"debug"`
+
+	// Connectors drawn by --report tree
+	TreeBranch string `json:"tree_branch"`
+	TreeLast   string `json:"tree_last"`
+	TreePipe   string `json:"tree_pipe"`
+}
+
+// themeColors maps the color names accepted in theme files to ANSI codes
+var themeColors = map[string]string{
+	"none":   "",
+	"red":    ColorRed,
+	"green":  ColorGreen,
+	"yellow": ColorYellow,
+	"blue":   ColorBlue,
+	"cyan":   ColorCyan,
+	"gray":   ColorGray,
+}
+
+// defaultTheme uses Unicode symbols
+var defaultTheme = Theme{
+	Success:  ThemeStyle{"✓", "green"},
+	Warning:  ThemeStyle{"⚠", "yellow"},
+	Error:    ThemeStyle{"✗", "red"},
+	Progress: ThemeStyle{"→", "blue"},
+	Debug:    ThemeStyle{"[DEBUG]", "gray"},
+
+	TreeBranch: "├── ",
+	TreeLast:   "└── ",
+	TreePipe:   "│   ",
+}
+
+// asciiTheme is used with --ascii for terminals and fonts without the
+// Unicode symbols
+var asciiTheme = Theme{
+	Success:  ThemeStyle{"[ok]", "green"},
+	Warning:  ThemeStyle{"[warn]", "yellow"},
+	Error:    ThemeStyle{"[error]", "red"},
+	Progress: ThemeStyle{"->", "blue"},
+	Debug:    ThemeStyle{"[DEBUG]", "gray"},
+
+	TreeBranch: "|-- ",
+	TreeLast:   "`-- ",
+	TreePipe:   "|   ",
+}
+
+// themeColorNames returns the accepted color names, sorted
+func themeColorNames() []string {
+	names := make([]string, 0, len(themeColors))
+	for name := range themeColors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadTheme reads a JSON theme file and applies it on top of base; styles
+// and fields the file leaves out keep their base values
+func LoadTheme(path string, base Theme) (Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return base, fmt.Errorf("failed to read theme: %w", err)
+	}
+	theme := base
+	if err := json.Unmarshal(stripJSONC(data), &theme); err != nil {
+		return base, fmt.Errorf("failed to parse theme %s: %w", path, err)
+	}
+	for _, style := range []ThemeStyle{theme.Success, theme.Warning, theme.Error, theme.Progress, theme.Debug} {
+		if _, ok := themeColors[style.Color]; !ok {
+			return base, fmt.Errorf("unknown theme color %q (expected one of: %s)", style.Color, strings.Join(themeColorNames(), ", "))
+		}
+	}
+	return theme, nil
+}
+
+// code returns the ANSI code for the style's color
+func (s ThemeStyle) code() string {
+	return themeColors[s.Color]
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+)
+
+// Timings breaks the duration of a run down by phase
+type Timings struct {
+	Total   time.Duration
+	Resolve time.Duration // reading embedded templates
+	Render  time.Duration // building generated content
+	Write   time.Duration // file system calls on the target
+}
+
+// jsonTimings is the wire format of Timings in the JSON report
+type jsonTimings struct {
+	TotalMS   float64 `json:"total_ms"`
+	ResolveMS float64 `json:"resolve_ms"`
+	RenderMS  float64 `json:"render_ms"`
+	WriteMS   float64 `json:"write_ms"`
+}
+
+// milliseconds converts d to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// TimingFileSystem wraps another FileSystem and accumulates the time spent
+// on each path, to diagnose slow network file systems
+type TimingFileSystem struct {
+	wrapped FileSystem
+	spent   map[string]time.Duration
+	total   time.Duration
+}
+
+// NewTimingFileSystem creates a new TimingFileSystem
+func NewTimingFileSystem(wrapped FileSystem) *TimingFileSystem {
+	return &TimingFileSystem{
+		wrapped: wrapped,
+		spent:   map[string]time.Duration{},
+	}
+}
+
+// track charges the time since start to path
+func (fs *TimingFileSystem) track(path string, start time.Time) {
+	elapsed := time.Since(start)
+	fs.spent[path] += elapsed
+	fs.total += elapsed
+}
+
+// Spent returns the time accumulated for path
+func (fs *TimingFileSystem) Spent(path string) time.Duration {
+	return fs.spent[path]
+}
+
+// Exists delegates to the wrapped filesystem
+func (fs *TimingFileSystem) Exists(path string) bool {
+	defer fs.track(path, time.Now())
+	return fs.wrapped.Exists(path)
+}
+
+// CreateDir delegates to the wrapped filesystem
+func (fs *TimingFileSystem) CreateDir(path string, perm os.FileMode) error {
+	defer fs.track(path, time.Now())
+	return fs.wrapped.CreateDir(path, perm)
+}
+
+// CreateFile delegates to the wrapped filesystem
+func (fs *TimingFileSystem) CreateFile(path string, content []byte, perm os.FileMode) error {
+	defer fs.track(path, time.Now())
+	return fs.wrapped.CreateFile(path, content, perm)
+}
+
+// WriteFile delegates to the wrapped filesystem
+func (fs *TimingFileSystem) WriteFile(path string, content []byte, perm os.FileMode) error {
+	defer fs.track(path, time.Now())
+	return fs.wrapped.WriteFile(path, content, perm)
+}
+
+// ReadFile delegates to the wrapped filesystem
+func (fs *TimingFileSystem) ReadFile(path string) ([]byte, error) {
+	defer fs.track(path, time.Now())
+	return fs.wrapped.ReadFile(path)
+}
+
+// Walk delegates to the wrapped filesystem without timing, since most of a
+// walk is spent in the callback
+func (fs *TimingFileSystem) Walk(root string, fn WalkFunc) error {
+	return fs.wrapped.Walk(root, fn)
+}
+
+// Stat delegates to the wrapped filesystem
+func (fs *TimingFileSystem) Stat(path string) (fs.FileInfo, error) {
+	defer fs.track(path, time.Now())
+	return fs.wrapped.Stat(path)
+}
+
+// Chmod delegates to the wrapped filesystem
+func (fs *TimingFileSystem) Chmod(path string, mode os.FileMode) error {
+	defer fs.track(path, time.Now())
+	return fs.wrapped.Chmod(path, mode)
+}
+
+// Chown delegates to the wrapped filesystem
+func (fs *TimingFileSystem) Chown(path string, uid, gid int) error {
+	defer fs.track(path, time.Now())
+	return fs.wrapped.Chown(path, uid, gid)
+}
+
+// Chtimes delegates to the wrapped filesystem
+func (fs *TimingFileSystem) Chtimes(path string, mtime time.Time) error {
+	defer fs.track(path, time.Now())
+	return fs.wrapped.Chtimes(path, mtime)
+}
+
+// Remove delegates to the wrapped filesystem
+func (fs *TimingFileSystem) Remove(path string) error {
+	defer fs.track(path, time.Now())
+	return fs.wrapped.Remove(path)
+}
+
+// slowestRecords returns up to n records that took the longest, slowest first
+func slowestRecords(records []FileRecord, n int) []FileRecord {
+	sorted := append([]FileRecord(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].DurationMS > sorted[j].DurationMS
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
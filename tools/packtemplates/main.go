@@ -0,0 +1,108 @@
+// Command packtemplates packs the embedded templates, .claude and sets/, into
+// templates.tar.gz for binaries built with the compressed tag:
+//
+//	go generate
+//	go build -tags compressed
+//
+// Entries are sorted and carry fixed times, so the archive only changes with
+// the templates. Like the go:embed patterns, it keeps dotfiles at the top of
+// each set, such as .mcp.json, and skips deeper files starting with . or _.
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// templateTrees are the trees packed into the archive, relative to the
+// repository root, with the depth of the top-level entries of their sets
+var templateTrees = []struct {
+	root  string
+	depth int
+}{
+	{".claude", 1},
+	{"sets", 2},
+}
+
+const archiveFile = "templates.tar.gz"
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "packtemplates: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var buf bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(gz)
+	count, size := 0, 0
+	for _, tree := range templateTrees {
+		err := filepath.WalkDir(tree.root, func(p string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			name := entry.Name()
+			depth := strings.Count(filepath.ToSlash(p), "/")
+			if depth > tree.depth && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			header := &tar.Header{Name: filepath.ToSlash(p), ModTime: time.Unix(0, 0), Format: tar.FormatPAX}
+			if entry.IsDir() {
+				header.Typeflag = tar.TypeDir
+				header.Name += "/"
+				header.Mode = 0755
+				return tw.WriteHeader(header)
+			}
+			if !entry.Type().IsRegular() {
+				return fmt.Errorf("%s is not a regular file", p)
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			header.Typeflag = tar.TypeReg
+			header.Mode = int64(info.Mode().Perm())
+			header.Size = int64(len(data))
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			count++
+			size += len(data)
+			_, err = tw.Write(data)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := os.WriteFile(archiveFile, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("packtemplates: packed %d files, %d bytes into %s (%d bytes)\n", count, size, archiveFile, buf.Len())
+	return nil
+}
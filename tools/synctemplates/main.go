@@ -0,0 +1,190 @@
+// Command synctemplates refreshes the embedded .claude templates from the
+// upstream repository pinned in templates.upstream.json. It runs from the
+// repository root through go generate:
+//
+//	go generate
+//
+// The pin file looks like
+//
+//	{"repo": "https://github.com/acme/claude-templates", "ref": "v1.4.0", "path": ".claude"}
+//
+// Without a pin file the templates are maintained in this repository and the
+// command does nothing.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Files the generator reads and writes, relative to the repository root
+const (
+	pinFile      = "templates.upstream.json"
+	templatesDir = ".claude"
+	versionFile  = "templates_version.go"
+)
+
+// Pin names the upstream templates: a git repository, a tag, branch or
+// commit, and the directory inside the repository holding the templates
+type Pin struct {
+	Repo string `json:"repo"`
+	Ref  string `json:"ref"`
+	Path string `json:"path"`
+}
+
+// junkFiles are editor and OS artifacts that never belong in the templates
+var junkFiles = map[string]bool{
+	".DS_Store":   true,
+	"Thumbs.db":   true,
+	"desktop.ini": true,
+	".gitkeep":    true,
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "synctemplates: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	data, err := os.ReadFile(pinFile)
+	if os.IsNotExist(err) {
+		fmt.Printf("synctemplates: no %s; templates are maintained in this repository\n", pinFile)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var pin Pin
+	if err := json.Unmarshal(data, &pin); err != nil {
+		return fmt.Errorf("invalid %s: %w", pinFile, err)
+	}
+	if pin.Repo == "" || pin.Ref == "" {
+		return fmt.Errorf("%s must set repo and ref", pinFile)
+	}
+	if pin.Path == "" {
+		pin.Path = templatesDir
+	}
+
+	checkout, err := os.MkdirTemp("", "synctemplates-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(checkout)
+	commit, err := fetch(checkout, pin)
+	if err != nil {
+		return err
+	}
+
+	src := filepath.Join(checkout, filepath.FromSlash(pin.Path))
+	if info, err := os.Stat(src); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s has no directory %s at %s", pin.Repo, pin.Path, pin.Ref)
+	}
+	if err := os.RemoveAll(templatesDir); err != nil {
+		return err
+	}
+	count, err := copyTemplates(src, templatesDir)
+	if err != nil {
+		return err
+	}
+	if err := writeVersion(pin.Repo + "@" + commit); err != nil {
+		return err
+	}
+	fmt.Printf("synctemplates: synced %d files from %s@%s (%s)\n", count, pin.Repo, pin.Ref, commit)
+	return nil
+}
+
+// fetch checks out the pinned ref into dir and returns its commit
+func fetch(dir string, pin Pin) (string, error) {
+	steps := [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth", "1", pin.Repo, pin.Ref},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	}
+	for _, args := range steps {
+		if _, err := git(dir, args...); err != nil {
+			return "", err
+		}
+	}
+	return git(dir, "rev-parse", "HEAD")
+}
+
+// git runs git in dir and returns its trimmed output
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// copyTemplates copies the regular files below src into dst, dropping junk
+// files and normalizing modes: 0755 for directories and shell scripts, 0644
+// for everything else
+func copyTemplates(src, dst string) (int, error) {
+	count := 0
+	err := filepath.WalkDir(src, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() && name == ".git" {
+			return filepath.SkipDir
+		}
+		if junkFiles[name] || strings.HasPrefix(name, "._") {
+			return nil
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if entry.IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			return os.Chmod(target, 0755)
+		}
+		if !entry.Type().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", rel)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		mode := os.FileMode(0644)
+		if ext := filepath.Ext(name); ext == ".sh" || ext == ".bash" {
+			mode = 0755
+		}
+		if err := os.WriteFile(target, data, mode); err != nil {
+			return err
+		}
+		count++
+		return os.Chmod(target, mode)
+	})
+	return count, err
+}
+
+// writeVersion records the synced source in the cc-init package
+func writeVersion(source string) error {
+	content := fmt.Sprintf(`// Code generated by tools/synctemplates; DO NOT EDIT.
+
+package main
+
+// templatesUpstream is the repository and commit the embedded templates were
+// synced from; empty when they are maintained in this repository
+const templatesUpstream = %q
+`, source)
+	return os.WriteFile(versionFile, []byte(content), 0644)
+}
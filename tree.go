@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// treeNode is one path component of the tree printed by TreeReporter
+type treeNode struct {
+	name     string
+	isDir    bool
+	action   string
+	children map[string]*treeNode
+}
+
+// child returns the named child of n, creating it if needed
+func (n *treeNode) child(name string) *treeNode {
+	if n.children == nil {
+		n.children = map[string]*treeNode{}
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = &treeNode{name: name}
+		n.children[name] = c
+	}
+	return c
+}
+
+// buildTree arranges the recorded paths into a tree. Directories that were
+// not processed themselves, such as .github above a workflow, have no action.
+func buildTree(records []FileRecord) *treeNode {
+	root := &treeNode{name: ".", isDir: true}
+	for _, rec := range records {
+		node := root
+		parts := strings.Split(rec.Path, "/")
+		for i, part := range parts {
+			node = node.child(part)
+			if i < len(parts)-1 {
+				node.isDir = true
+			}
+		}
+		node.isDir = node.isDir || rec.IsDir
+		node.action = rec.Action
+	}
+	return root
+}
+
+// TreeReporter prints the touched paths as a tree annotated with what
+// happened to each, followed by the console summary
+type TreeReporter struct {
+	logger *Logger
+	writer io.Writer
+}
+
+// Report renders the tree and then the console summary
+func (r *TreeReporter) Report(report *Report) error {
+	if len(report.Stats.Records) > 0 {
+		var b strings.Builder
+		b.WriteString("\n.\n")
+		r.writeChildren(&b, buildTree(report.Stats.Records), "")
+		if _, err := io.WriteString(r.writer, b.String()); err != nil {
+			return err
+		}
+	}
+	return (&ConsoleReporter{logger: r.logger}).Report(report)
+}
+
+// writeChildren writes the children of node sorted by name, each line
+// prefixed by the connectors of its ancestors
+func (r *TreeReporter) writeChildren(b *strings.Builder, node *treeNode, indent string) {
+	names := make([]string, 0, len(node.children))
+	for name := range node.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	theme := r.logger.theme
+	for i, name := range names {
+		child := node.children[name]
+		connector, next := theme.TreeBranch, indent+theme.TreePipe
+		if i == len(names)-1 {
+			connector = theme.TreeLast
+			next = indent + strings.Repeat(" ", utf8.RuneCountInString(theme.TreeLast))
+		}
+
+		label := child.name
+		if child.isDir {
+			label += "/"
+		}
+		if child.action != "" {
+			label += " " + r.logger.colorize(actionColor(child.action), "("+tr(child.action)+")")
+		}
+		fmt.Fprintf(b, "%s%s%s\n", indent, connector, label)
+		r.writeChildren(b, child, next)
+	}
+}
+
+// actionColor is the annotation color for a recorded action
+func actionColor(action string) string {
+	switch action {
+	case ActionCreated:
+		return ColorGreen
+	case ActionUpdated:
+		return ColorYellow
+	case ActionFailed, ActionRemoved:
+		return ColorRed
+	default:
+		return ColorGray
+	}
+}
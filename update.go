@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+	"path/filepath"
+)
+
+// baseDir keeps the content of each template file as cc-init last wrote it,
+// the common ancestor when --update merges a newer template into a file that
+// was edited since; it gitignores itself
+const baseDir = ".claude/.base"
+
+// previousLock returns the lockfile as it was before this run, or nil
+func (e *Engine) previousLock() *Lock {
+	if !e.lockLoaded {
+		e.lockLoaded = true
+		e.prevLock, _ = e.readLock()
+	}
+	return e.prevLock
+}
+
+// ensureIgnored makes dir, relative to the target, gitignore itself
+func (e *Engine) ensureIgnored(dir string) error {
+	ignore := filepath.Join(e.config.TargetDir, filepath.FromSlash(dir), ".gitignore")
+	if e.fs.Exists(ignore) {
+		return nil
+	}
+	return e.fs.WriteFile(ignore, []byte("*\n"), 0644)
+}
+
+// finalContent returns content as cc-init writes it to targetPath: with its
+// header, formatted by .editorconfig and with normalized line endings
+func (e *Engine) finalContent(targetPath string, content []byte) ([]byte, error) {
+	content, err := e.addHeader(targetPath, content)
+	if err != nil {
+		return nil, err
+	}
+	return e.normalizeLineEndings(targetPath, e.applyEditorConfig(targetPath, content)), nil
+}
+
+// saveBase records content as the base of the template file at targetPath
+func (e *Engine) saveBase(targetPath string, content []byte) error {
+	rel := filepath.ToSlash(e.formatPath(targetPath))
+	if e.config.DryRun || filepath.IsAbs(rel) {
+		return nil
+	}
+	if err := e.ensureIgnored(baseDir); err != nil {
+		return err
+	}
+	return e.fs.WriteFile(filepath.Join(e.config.TargetDir, filepath.FromSlash(path.Join(baseDir, rel))), content, 0644)
+}
+
+// readBase returns the recorded base of the template file at targetPath
+func (e *Engine) readBase(targetPath string) ([]byte, bool) {
+	rel := filepath.ToSlash(e.formatPath(targetPath))
+	data, err := e.fs.ReadFile(filepath.Join(e.config.TargetDir, filepath.FromSlash(path.Join(baseDir, rel))))
+	return data, err == nil
+}
+
+// updateTemplate brings an existing file up to date with its template for
+// --update. Files unchanged since cc-init wrote them are replaced; edited
+// files get the template changes merged in, against the recorded base when
+// there is one. Conflicting hunks are resolved as resolveConflicts decides.
+func (e *Engine) updateTemplate(targetPath string, content []byte, mode fs.FileMode) error {
+	existing, err := e.fs.ReadFile(targetPath)
+	if err != nil {
+		// installFile reports directories in the way
+		return e.installFile(targetPath, nil, 0)
+	}
+	theirs, err := e.finalContent(targetPath, content)
+	if err != nil {
+		e.record(targetPath, false, ActionFailed)
+		return err
+	}
+	if bytes.Equal(existing, theirs) {
+		if err := e.saveBase(targetPath, theirs); err != nil {
+			return err
+		}
+		return e.installFile(targetPath, nil, 0)
+	}
+
+	merged := theirs
+	rel := filepath.ToSlash(e.formatPath(targetPath))
+	// Unresolved conflict markers hold local lines, so such files count as edited
+	if lock := e.previousLock(); lock == nil || lock.Files[rel] != contentDigest(existing) || hasConflictMarkers(string(existing)) {
+		ours, other := splitLines(string(existing)), splitLines(string(theirs))
+		var chunks []mergeChunk
+		base, ok := e.readBase(targetPath)
+		if ok {
+			chunks = merge3(splitLines(string(base)), ours, other)
+		} else {
+			chunks = merge2(ours, other)
+		}
+		if n := countConflicts(chunks); n > 0 {
+			return e.resolveConflicts(targetPath, mode, base, existing, theirs, chunks)
+		}
+		merged = []byte(renderMerge(chunks))
+		if !bytes.Equal(merged, existing) {
+			e.logger.Info("Merged the template changes into %s", rel)
+		}
+	}
+
+	err = e.updateFile(targetPath, mode, func([]byte) ([]byte, error) {
+		return merged, nil
+	})
+	if err != nil {
+		return err
+	}
+	return e.saveBase(targetPath, theirs)
+}
+
+// resolveConflicts handles a merge with conflicting hunks. --conflict ours,
+// theirs or union settles them and --conflict markers writes the file with
+// conflict markers. Without --conflict, in a terminal the file is written with
+// markers and the merge tool is offered until they are gone; otherwise the
+// file is left as it is.
+func (e *Engine) resolveConflicts(targetPath string, mode fs.FileMode, base, existing, theirs []byte, chunks []mergeChunk) error {
+	rel := filepath.ToSlash(e.formatPath(targetPath))
+	n := countConflicts(chunks)
+	switch e.config.Conflict {
+	case ConflictOurs, ConflictTheirs, ConflictUnion:
+		chunks = settleConflicts(chunks, e.config.Conflict)
+		e.logger.Info("Settled %d conflicting %s in %s with --conflict %s", n, pluralize("hunk", n), rel, e.config.Conflict)
+	case ConflictMarkers:
+		e.logger.Warning("%s has %d conflicting %s between local changes and the template", rel, n, pluralize("hunk", n))
+	default:
+		if e.interactiveMerge() {
+			break
+		}
+		e.logger.Warning("Not updating %s: %d conflicting %s with local changes; run cc-init --update in a terminal to merge them, or pass --conflict", rel, n, pluralize("hunk", n))
+		return e.installFile(targetPath, nil, 0)
+	}
+
+	err := e.updateFile(targetPath, mode, func([]byte) ([]byte, error) {
+		return []byte(renderMerge(chunks)), nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := e.saveBase(targetPath, theirs); err != nil {
+		return err
+	}
+	if e.config.Conflict != "" {
+		return nil
+	}
+	e.logger.Warning("%s has %d conflicting %s between local changes and the template", rel, n, pluralize("hunk", n))
+	return e.runMergeTool(targetPath, base, existing, theirs)
+}
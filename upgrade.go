@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Upgrade outcomes of a project
+const (
+	UpgradeClean   = "clean"
+	UpgradeBehind  = "behind"
+	UpgradeDirty   = "dirty"
+	UpgradeUpdated = "updated"
+	UpgradeFailed  = "failed"
+)
+
+// UpgradeResult is the outcome of one project. Changes counts the files the
+// update creates, updates or removes; Dirty is set when the checkout has
+// uncommitted changes.
+type UpgradeResult struct {
+	Project string `json:"project"`
+	Status  string `json:"status"`
+	Changes int    `json:"changes"`
+	Dirty   bool   `json:"dirty,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// upgradeOptions are the command-line options of `cc-init upgrade`
+type upgradeOptions struct {
+	status     bool
+	allowDirty bool
+	extra      []string
+}
+
+// runSelfReport runs this binary on dir with args and returns its JSON report
+func runSelfReport(self, dir string, args []string) (*jsonReport, error) {
+	run := append([]string{"--target", dir, "--yes", "--report", ReportJSON, "--log-level", LogLevelError, "--no-color"}, args...)
+	cmd := exec.Command(self, run...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s", msg)
+		}
+		return nil, err
+	}
+	var report jsonReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		return nil, fmt.Errorf("cannot read the report of cc-init: %w", err)
+	}
+	return &report, nil
+}
+
+// upgradeProject works out whether a project is behind the templates and,
+// unless only the status is asked for, updates it. Checkouts with
+// uncommitted changes are left alone unless allowDirty is set.
+func upgradeProject(self, dir string, opts upgradeOptions) UpgradeResult {
+	result := UpgradeResult{Project: dir, Status: UpgradeFailed}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		result.Error = "no such directory"
+		return result
+	}
+	// Directories outside git have no uncommitted changes to protect
+	if status, err := gitRun(dir, "status", "--porcelain"); err == nil && status != "" {
+		result.Dirty = true
+	}
+
+	report, err := runSelfReport(self, dir, append([]string{"--update", "--dry-run"}, opts.extra...))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Changes = report.FilesCreated + report.FilesUpdated + report.FilesRemoved
+	switch {
+	case result.Changes == 0:
+		result.Status = UpgradeClean
+		return result
+	case opts.status:
+		result.Status = UpgradeBehind
+		return result
+	case result.Dirty && !opts.allowDirty:
+		result.Status = UpgradeDirty
+		return result
+	}
+
+	report, err = runSelfReport(self, dir, append([]string{"--update"}, opts.extra...))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = UpgradeUpdated
+	result.Changes = report.FilesCreated + report.FilesUpdated + report.FilesRemoved
+	return result
+}
+
+// upgradeDetails describes a result for the table of `cc-init upgrade`
+func upgradeDetails(r UpgradeResult) string {
+	files := pluralize("file", r.Changes)
+	switch r.Status {
+	case UpgradeBehind:
+		if r.Dirty {
+			return fmt.Sprintf(tr("%d %s to update; uncommitted changes"), r.Changes, files)
+		}
+		return fmt.Sprintf(tr("%d %s to update"), r.Changes, files)
+	case UpgradeDirty:
+		return fmt.Sprintf(tr("%d %s to update; commit or stash the uncommitted changes, or pass --allow-dirty"), r.Changes, files)
+	case UpgradeUpdated:
+		return fmt.Sprintf(tr("%d %s changed"), r.Changes, files)
+	case UpgradeFailed:
+		// Only the first line; cc-init printed the rest
+		first, _, _ := strings.Cut(r.Error, "\n")
+		return first
+	}
+	return ""
+}
+
+// writeUpgradeTable prints the consolidated table of an upgrade
+func writeUpgradeTable(results []UpgradeResult) {
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		rows = append(rows, []string{r.Project, tr(r.Status), upgradeDetails(r)})
+	}
+	writeTable(os.Stdout, []string{tr("PROJECT"), tr("STATUS"), tr("DETAILS")}, rows)
+}
+
+// runUpgrade implements `cc-init upgrade`: it runs cc-init --update in the
+// given projects, or with --all in every project of the user configuration,
+// and prints which were behind, dirty or clean
+func runUpgrade(args []string) error {
+	cmd := findCommand("upgrade")
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	all := fs.Bool("all", false, tr("Upgrade every project listed under projects in the user configuration"))
+	statusOnly := fs.Bool("status", false, tr("Only report which projects are behind, without updating them"))
+	allowDirty := fs.Bool("allow-dirty", false, tr("Also update checkouts with uncommitted changes"))
+	reportFile := fs.String("report-file", "", tr("Also write the JSON summary to this file"))
+	noColor := fs.Bool("no-color", false, tr("Disable colored output"))
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags after -- are passed to every run.\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	// Flags after -- are cc-init flags for every project
+	var extra []string
+	for i, arg := range args {
+		if arg == "--" {
+			args, extra = args[:i], args[i+1:]
+			break
+		}
+	}
+	projects, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if *all {
+		user, err := loadUserConfig()
+		if err != nil {
+			return err
+		}
+		if len(user.Projects) == 0 {
+			path, _ := userConfigPath()
+			return fmt.Errorf("no projects listed in %s", path)
+		}
+		projects = append(projects, user.Projects...)
+	}
+	if len(projects) == 0 {
+		fs.Usage()
+		return fmt.Errorf("expected: cc-init upgrade --all, or the projects to upgrade")
+	}
+
+	// Run this binary so every cc-init flag works exactly as on the command line
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	logger := NewLogger(false, *noColor)
+	opts := upgradeOptions{status: *statusOnly, allowDirty: *allowDirty, extra: extra}
+	results := make([]UpgradeResult, 0, len(projects))
+	failed := 0
+	for i, project := range projects {
+		logger.Info("[%d/%d] %s", i+1, len(projects), project)
+		result := upgradeProject(self, expandHome(project), opts)
+		result.Project = project
+		if result.Status == UpgradeFailed {
+			logger.Error("%s: %s", project, result.Error)
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if *reportFile != "" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*reportFile, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write report file: %w", err)
+		}
+	}
+
+	logger.Blank()
+	writeUpgradeTable(results)
+	if failed > 0 {
+		return fmt.Errorf("upgrade failed for %d %s", failed, pluralize("project", failed))
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// userConfigFile is the personal cc-init configuration, in userConfigDir
+const userConfigFile = "config.yaml"
+
+// UserConfig is the content of the user configuration file
+type UserConfig struct {
+	// Projects are the checkouts `cc-init upgrade --all` brings up to date;
+	// a leading ~/ stands for the home directory
+	Projects []string `yaml:"projects"`
+	// Locale is the language of messages when CC_INIT_LANG is not set
+	Locale string `yaml:"locale"`
+	// Defaults are values for flags not given on the command line, keyed
+	// by flag name without dashes; lists give repeatable flags several values
+	Defaults map[string]interface{} `yaml:"defaults"`
+	// Aliases map a name to the arguments it stands for, e.g.
+	// company-init: --template-dir https://templates.example.com/ --yes
+	Aliases map[string]string `yaml:"aliases"`
+}
+
+// userConfigPath returns where the user configuration is read from
+func userConfigPath() (string, error) {
+	dir, err := userConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, userConfigFile), nil
+}
+
+// loadUserConfig reads the user configuration; a missing file yields an
+// empty configuration
+func loadUserConfig() (*UserConfig, error) {
+	config := &UserConfig{}
+	path, err := userConfigPath()
+	if err != nil {
+		return config, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// applyUserLocale switches messages to the locale of the user configuration
+// unless CC_INIT_LANG chose one. An unreadable configuration is reported
+// when the defaults are applied.
+func applyUserLocale() {
+	if os.Getenv("CC_INIT_LANG") != "" {
+		return
+	}
+	if user, err := loadUserConfig(); err == nil && user.Locale != "" {
+		currentLocale = normalizeLocale(user.Locale)
+	}
+}
+
+// expandHome replaces a leading ~/ with the home directory
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(filepath.ToSlash(path), "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, filepath.FromSlash(rest))
+}
+
+// setUserDefault sets the default of a flag in the user configuration,
+// keeping the rest of the file and its comments, and returns the path of
+// the file
+func setUserDefault(name, value string) (string, error) {
+	path, err := userConfigPath()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("invalid %s: expected a mapping", path)
+	}
+	// mapping returns the value of key in node, adding it when missing
+	mapping := func(node *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i+1]
+			}
+		}
+		value := &yaml.Node{Kind: kind}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+		return value
+	}
+	defaults := mapping(root, "defaults", yaml.MappingNode)
+	if defaults.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("invalid %s: defaults is not a mapping", path)
+	}
+	flag := mapping(defaults, name, yaml.ScalarNode)
+	*flag = yaml.Node{Kind: yaml.ScalarNode, Value: value, LineComment: flag.LineComment}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import "fmt"
+
+// runValidate implements `cc-init validate`
+func runValidate(args []string) error {
+	config := &Config{}
+	fs := newCommandFlagSet(findCommand("validate"), config)
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %v", positional)
+	}
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	engine, err := NewEngine(templateFiles(), config)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+	files := engine.settingsFilesToValidate()
+	if len(files) == 0 {
+		return fmt.Errorf("no settings files found in %s", config.TargetDir)
+	}
+
+	problems, err := engine.validateSettingsFiles(engine.logger.Error)
+	if err != nil {
+		return err
+	}
+	if err := engine.checkManagedSettings(); err != nil {
+		return err
+	}
+	if problems > 0 {
+		return fmt.Errorf("found %d settings %s", problems, pluralize("problem", problems))
+	}
+	engine.logger.Success("%d settings %s passed validation", len(files), pluralize("file", len(files)))
+	return nil
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Variable types of pack.yaml
+const (
+	VarString = "string"
+	VarBool   = "bool"
+	VarChoice = "choice"
+)
+
+// varCommandTimeout bounds the command of a computed variable
+const varCommandTimeout = 30 * time.Second
+
+// varNamePattern keeps variable names usable as {{ .name }} in templates
+var varNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// PackVariable is a value templates of the pack can use as {{ .Name }}
+type PackVariable struct {
+	Name string `yaml:"name"`
+	// Type is string (default), bool or choice
+	Type string `yaml:"type"`
+	// Help is shown when prompting
+	Help string `yaml:"help"`
+	// Default is used when no value is given; without one the variable is
+	// required
+	Default string `yaml:"default"`
+	// Choices lists the values of a choice variable
+	Choices []string `yaml:"choices"`
+	// Pattern is a regular expression string values must match in full
+	Pattern string `yaml:"pattern"`
+	// MinLength and MaxLength bound the length of string values in
+	// characters; zero means no bound
+	MinLength int `yaml:"min_length"`
+	MaxLength int `yaml:"max_length"`
+	// Command computes the value from its output, run by the shell in the
+	// target directory; it only runs with --allow-exec
+	Command string `yaml:"command"`
+	// Message replaces the generic error for values that break these rules,
+	// e.g. "use the lowercase GitHub organization name"
+	Message string `yaml:"message"`
+}
+
+// validateVariables checks the variable declarations of a manifest
+func validateVariables(vars []PackVariable) error {
+	seen := map[string]bool{}
+	for _, v := range vars {
+		if !varNamePattern.MatchString(v.Name) {
+			return fmt.Errorf("invalid variable name %q (expected letters, digits and _)", v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("variable %s is declared twice", v.Name)
+		}
+		seen[v.Name] = true
+		switch v.Type {
+		case "", VarString:
+		case VarBool:
+			if _, err := parseVarBool(v.Default); v.Default != "" && err != nil {
+				return fmt.Errorf("variable %s: default %q is not a boolean", v.Name, v.Default)
+			}
+		case VarChoice:
+			if len(v.Choices) == 0 {
+				return fmt.Errorf("variable %s: choice variables need choices", v.Name)
+			}
+			if v.Default != "" && !containsString(v.Choices, v.Default) {
+				return fmt.Errorf("variable %s: default %q is not one of its choices", v.Name, v.Default)
+			}
+		default:
+			return fmt.Errorf("variable %s: unknown type %q (expected %s, %s or %s)", v.Name, v.Type, VarString, VarBool, VarChoice)
+		}
+		if v.Pattern != "" {
+			if _, err := regexp.Compile(v.Pattern); err != nil {
+				return fmt.Errorf("variable %s: invalid pattern: %w", v.Name, err)
+			}
+		}
+		if v.MinLength < 0 || v.MaxLength < 0 || (v.MaxLength > 0 && v.MinLength > v.MaxLength) {
+			return fmt.Errorf("variable %s: invalid length bounds %d..%d", v.Name, v.MinLength, v.MaxLength)
+		}
+		if v.Type != VarBool && v.Default != "" {
+			if problem := checkVarRules(v, v.Default); problem != "" {
+				return fmt.Errorf("variable %s: default %q is invalid: %s", v.Name, v.Default, problem)
+			}
+		}
+	}
+	return nil
+}
+
+// parseVarAssignments parses --var values such as org=acme
+func parseVarAssignments(values []string) (map[string]string, error) {
+	vars := map[string]string{}
+	for _, value := range values {
+		name, val, ok := strings.Cut(value, "=")
+		if !ok || !varNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid --var %q (expected NAME=VALUE)", value)
+		}
+		vars[name] = val
+	}
+	return vars, nil
+}
+
+// parseVarBool accepts the usual spellings of yes and no
+func parseVarBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "y", "yes", "on":
+		return true, nil
+	case "n", "no", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// convertVar checks a raw value against the declaration and returns what
+// templates see: a bool for bool variables, otherwise the string
+func convertVar(v PackVariable, raw string) (interface{}, error) {
+	switch v.Type {
+	case VarBool:
+		b, err := parseVarBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %q is not a boolean", v.Name, raw)
+		}
+		return b, nil
+	case VarChoice:
+		if !containsString(v.Choices, raw) {
+			return nil, fmt.Errorf("variable %s: %q is not one of %s", v.Name, raw, strings.Join(v.Choices, ", "))
+		}
+	}
+	if problem := checkVarRules(v, raw); problem != "" {
+		if v.Message != "" {
+			problem = v.Message
+		}
+		return nil, fmt.Errorf("variable %s: %q is invalid: %s", v.Name, raw, problem)
+	}
+	return raw, nil
+}
+
+// checkVarRules describes how raw breaks the pattern and length rules of a
+// variable, or returns "" when it passes
+func checkVarRules(v PackVariable, raw string) string {
+	length := utf8.RuneCountInString(raw)
+	switch {
+	case v.MinLength > 0 && length < v.MinLength:
+		return fmt.Sprintf("at least %d characters required", v.MinLength)
+	case v.MaxLength > 0 && length > v.MaxLength:
+		return fmt.Sprintf("at most %d characters allowed", v.MaxLength)
+	}
+	if v.Pattern != "" {
+		// validateVariables has already compiled the pattern
+		re, _ := regexp.Compile("^(?:" + v.Pattern + ")$")
+		if re != nil && !re.MatchString(raw) {
+			return fmt.Sprintf("must match %s", v.Pattern)
+		}
+	}
+	return ""
+}
+
+// Answers pre-supplies the questions cc-init would ask, read from --answers
+type Answers struct {
+	// Variables maps variable names to their values
+	Variables map[string]interface{} `yaml:"variables"`
+	// Conflict settles conflicting hunks when --conflict is not given
+	Conflict string `yaml:"conflict"`
+	// Confirm answers the yes/no questions of a run
+	Confirm AnswerConfirmations `yaml:"confirm"`
+	values  map[string]string
+}
+
+// AnswerConfirmations answers the yes/no questions cc-init asks before it
+// acts; a question left out is asked as usual
+type AnswerConfirmations struct {
+	// Prune answers whether to remove the orphaned files --prune lists
+	Prune *bool `yaml:"prune"`
+	// MergeTool answers whether to open the merge tool on conflicting files
+	MergeTool *bool `yaml:"merge_tool"`
+	// Commands answers, by variable name, whether to run the command of a
+	// computed variable
+	Commands map[string]bool `yaml:"commands"`
+}
+
+// loadAnswers reads an --answers file; scalar values of any YAML type are
+// taken as their text, so strict: false and strict: "false" agree
+func loadAnswers(path string) (*Answers, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var answers Answers
+	if err := yaml.Unmarshal(data, &answers); err != nil {
+		return nil, fmt.Errorf("invalid answers file %s: %w", path, err)
+	}
+	answers.values = map[string]string{}
+	for name, value := range answers.Variables {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("invalid answers file %s: variable %s must be a single value", path, name)
+		case nil:
+			answers.values[name] = ""
+		default:
+			answers.values[name] = fmt.Sprint(value)
+		}
+	}
+	if err := validateConflictStrategy(answers.Conflict); err != nil {
+		return nil, fmt.Errorf("invalid answers file %s: %w", path, err)
+	}
+	return &answers, nil
+}
+
+// confirmPrune returns the answer to the --prune question, if there is one
+func (a *Answers) confirmPrune() (yes, ok bool) {
+	if a == nil || a.Confirm.Prune == nil {
+		return false, false
+	}
+	return *a.Confirm.Prune, true
+}
+
+// confirmMergeTool returns the answer to the merge tool question, if there
+// is one
+func (a *Answers) confirmMergeTool() (yes, ok bool) {
+	if a == nil || a.Confirm.MergeTool == nil {
+		return false, false
+	}
+	return *a.Confirm.MergeTool, true
+}
+
+// confirmCommand returns whether to run the command of computed variable
+// name, if the answers say
+func (a *Answers) confirmCommand(name string) (yes, ok bool) {
+	if a == nil {
+		return false, false
+	}
+	yes, ok = a.Confirm.Commands[name]
+	return yes, ok
+}
+
+// stdinIsTerminal reports whether questions can be asked on stdin
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// resolveVariables gives every variable of the pack a value: --var, else
+// the --answers file, else an answer when running interactively, else the
+// default. Answers are kept in the configuration, so --watch asks only once.
+func (e *Engine) resolveVariables() error {
+	if e.manifest == nil || len(e.manifest.Variables) == 0 {
+		return nil
+	}
+	if err := validateVariables(e.manifest.Variables); err != nil {
+		return fmt.Errorf("invalid %s: %w", packManifestFile, err)
+	}
+	given := map[string]string{}
+	if e.config.Answers != nil {
+		for name, value := range e.config.Answers.values {
+			given[name] = value
+		}
+	}
+	// validateConfig has already checked the assignments
+	assigned, _ := parseVarAssignments(e.config.Vars)
+	for name, value := range assigned {
+		given[name] = value
+	}
+	interactive := stdinIsTerminal() && !e.config.CI && !e.config.Yes
+	var input *bufio.Reader
+	if interactive {
+		input = bufio.NewReader(os.Stdin)
+	}
+
+	e.vars = map[string]interface{}{}
+	for _, v := range e.manifest.Variables {
+		raw, ok := given[v.Name]
+		if !ok && interactive && v.Command == "" {
+			answer, err := promptVariable(os.Stderr, input, v)
+			if err != nil {
+				return err
+			}
+			raw, ok = answer, true
+			e.config.Vars = append(e.config.Vars, v.Name+"="+answer)
+		}
+		if !ok && v.Command != "" {
+			output, err := e.computeVariable(v, input)
+			if err != nil {
+				return err
+			}
+			raw, ok = output, true
+		}
+		if !ok && v.Default != "" {
+			raw, ok = v.Default, true
+		}
+		if !ok {
+			return fmt.Errorf("variable %s has no value; pass --var %s=VALUE or answer it in --answers", v.Name, v.Name)
+		}
+		value, err := convertVar(v, raw)
+		if err != nil {
+			return err
+		}
+		e.vars[v.Name] = value
+	}
+	return nil
+}
+
+// computeVariable runs the command of a computed variable and returns its
+// trimmed output. The command is printed before it runs, and nothing runs
+// without --allow-exec. The command only runs once confirmed, by the answers
+// file or, with input, at a prompt; a declined command falls back to the
+// default.
+func (e *Engine) computeVariable(v PackVariable, input *bufio.Reader) (string, error) {
+	if !e.config.AllowExec {
+		return "", fmt.Errorf("variable %s is computed by running %q; pass --allow-exec to allow it, or --var %s=VALUE", v.Name, v.Command, v.Name)
+	}
+	if e.config.Remote != nil {
+		return "", fmt.Errorf("variable %s is computed by a command, which cannot run on remote targets; pass --var %s=VALUE", v.Name, v.Name)
+	}
+	run, answered := e.config.Answers.confirmCommand(v.Name)
+	if !answered && input != nil {
+		answer, err := promptVariable(os.Stderr, input, PackVariable{
+			Name:    v.Name,
+			Type:    VarBool,
+			Help:    fmt.Sprintf(tr("Run %s to compute variable %s?"), v.Command, v.Name),
+			Default: "no",
+		})
+		if err != nil {
+			return "", err
+		}
+		run, _ = parseVarBool(answer)
+		answered = true
+	}
+	if answered && !run {
+		if v.Default != "" {
+			return v.Default, nil
+		}
+		return "", fmt.Errorf("variable %s is computed by running %q, which was declined; pass --var %s=VALUE", v.Name, v.Command, v.Name)
+	}
+	e.logger.Info("Running %s for variable %s", v.Command, v.Name)
+	ctx, cancel := context.WithTimeout(context.Background(), varCommandTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", v.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", v.Command)
+	}
+	cmd.Dir = e.config.TargetDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if v.Default != "" {
+			e.logger.Warning("Command for variable %s failed, using the default %q: %v", v.Name, v.Default, err)
+			return v.Default, nil
+		}
+		return "", fmt.Errorf("command for variable %s failed: %v: %s", v.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// promptVariable asks for a variable until the answer fits its declaration;
+// an empty answer takes the default
+func promptVariable(w io.Writer, r *bufio.Reader, v PackVariable) (string, error) {
+	label := v.Name
+	if v.Help != "" {
+		label = v.Help
+	}
+	for {
+		switch v.Type {
+		case VarBool:
+			hint := "y/n"
+			if b, err := parseVarBool(v.Default); v.Default != "" && err == nil {
+				hint = map[bool]string{true: "Y/n", false: "y/N"}[b]
+			}
+			fmt.Fprintf(w, "? %s [%s]: ", label, hint)
+		case VarChoice:
+			fmt.Fprintf(w, "? %s\n", label)
+			for i, choice := range v.Choices {
+				fmt.Fprintf(w, "  %d) %s\n", i+1, choice)
+			}
+			fmt.Fprint(w, tr("  Choose"))
+			if v.Default != "" {
+				fmt.Fprintf(w, " [%s]", v.Default)
+			}
+			fmt.Fprint(w, ": ")
+		default:
+			fmt.Fprintf(w, "? %s", label)
+			if v.Default != "" {
+				fmt.Fprintf(w, " [%s]", v.Default)
+			}
+			fmt.Fprint(w, ": ")
+		}
+
+		line, err := r.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("no answer for variable %s: %w", v.Name, err)
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = v.Default
+		}
+		if v.Type == VarChoice {
+			if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(v.Choices) {
+				answer = v.Choices[n-1]
+			}
+		}
+		if answer == "" {
+			fmt.Fprint(w, tr("  A value is required\n"))
+			continue
+		}
+		if _, err := convertVar(v, answer); err != nil {
+			fmt.Fprintf(w, "  %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
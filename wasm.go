@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// Exports of a WASM module in a template pack. Values cross the boundary as
+// JSON: cc-init writes the input into memory from cc_init_alloc, and the
+// function returns the location of its output as ptr<<32 | len.
+const (
+	wasmAllocExport  = "cc_init_alloc"
+	wasmPolicyExport = "cc_init_resolve_conflict"
+	wasmFuncPrefix   = "cc_init_fn_"
+)
+
+// Limits of the WASM sandbox: linear memory and the time per call
+const (
+	wasmMemoryLimitPages = 256 // 16 MiB
+	wasmCallTimeout      = 5 * time.Second
+)
+
+// Conflict policy actions
+const (
+	ConflictKeep      = "keep"
+	ConflictOverwrite = "overwrite"
+	ConflictMerge     = "merge"
+)
+
+// ConflictInput is passed to a conflict policy for a template whose target
+// already exists with different content
+type ConflictInput struct {
+	Path     string `json:"path"`
+	Template string `json:"template"`
+	Existing string `json:"existing"`
+}
+
+// ConflictResolution is returned by a conflict policy: keep the existing
+// file, overwrite it with the template, or replace it with merged content
+type ConflictResolution struct {
+	Action  string `json:"action"`
+	Content string `json:"content,omitempty"`
+}
+
+// wasmFuncResult is returned by a template function
+type wasmFuncResult struct {
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// wasmModule is an instantiated WASM module of a template pack
+type wasmModule struct {
+	name   string
+	module api.Module
+	memory api.Memory
+	alloc  api.Function
+}
+
+// WASMPlugins runs the WASM modules of a template pack. Modules get WASI
+// without arguments, environment, files or clocks beyond the defaults, so
+// they can only compute on the input they are given.
+type WASMPlugins struct {
+	runtime wazero.Runtime
+	funcs   map[string]*wasmModule
+	policy  *wasmModule
+}
+
+// LoadWASMPlugins compiles and instantiates the modules listed in pack.yaml
+func LoadWASMPlugins(pack fs.FS, paths []string) (*WASMPlugins, error) {
+	ctx := context.Background()
+	config := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(wasmMemoryLimitPages).
+		WithCloseOnContextDone(true)
+	p := &WASMPlugins{runtime: wazero.NewRuntimeWithConfig(ctx, config), funcs: map[string]*wasmModule{}}
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, p.runtime); err != nil {
+		p.Close()
+		return nil, err
+	}
+	for _, name := range paths {
+		if err := p.load(ctx, pack, path.Clean(name)); err != nil {
+			p.Close()
+			return nil, fmt.Errorf("failed to load WASM module %s: %w", name, err)
+		}
+	}
+	return p, nil
+}
+
+// load instantiates one module and registers its exports
+func (p *WASMPlugins) load(ctx context.Context, pack fs.FS, name string) error {
+	code, err := fs.ReadFile(pack, name)
+	if err != nil {
+		return err
+	}
+	compiled, err := p.runtime.CompileModule(ctx, code)
+	if err != nil {
+		return err
+	}
+	config := wazero.NewModuleConfig().WithName(name).WithStartFunctions("_initialize")
+	module, err := p.runtime.InstantiateModule(ctx, compiled, config)
+	if err != nil {
+		return err
+	}
+	// Calls write the input into and read the output from the linear memory,
+	// so a module without one, or with exports of other signatures, is
+	// refused here rather than failing in the middle of a run
+	if len(module.ExportedMemoryDefinitions()) == 0 {
+		return fmt.Errorf("module exports no memory")
+	}
+	exports := compiled.ExportedFunctions()
+	m := &wasmModule{name: name, module: module, memory: module.Memory(), alloc: module.ExportedFunction(wasmAllocExport)}
+	if m.alloc == nil {
+		return fmt.Errorf("module does not export %s", wasmAllocExport)
+	}
+	if err := checkWASMSignature(wasmAllocExport, exports[wasmAllocExport], 1); err != nil {
+		return err
+	}
+
+	for export, def := range exports {
+		if export == wasmPolicyExport || strings.HasPrefix(export, wasmFuncPrefix) {
+			if err := checkWASMSignature(export, def, 2); err != nil {
+				return err
+			}
+		}
+		switch {
+		case export == wasmPolicyExport:
+			if p.policy != nil {
+				return fmt.Errorf("%s already provides the conflict policy", p.policy.name)
+			}
+			p.policy = m
+		case strings.HasPrefix(export, wasmFuncPrefix):
+			fn := strings.TrimPrefix(export, wasmFuncPrefix)
+			if other, ok := p.funcs[fn]; ok {
+				return fmt.Errorf("%s already provides the function %s", other.name, fn)
+			}
+			p.funcs[fn] = m
+		}
+	}
+	return nil
+}
+
+// checkWASMSignature refuses an export that does not take params integers
+// and return one, as the calling convention of cc-init expects
+func checkWASMSignature(export string, def api.FunctionDefinition, params int) error {
+	if len(def.ParamTypes()) != params || len(def.ResultTypes()) != 1 {
+		return fmt.Errorf("%s takes %d parameters and returns %d values; expected %d and 1", export, len(def.ParamTypes()), len(def.ResultTypes()), params)
+	}
+	return nil
+}
+
+// Close releases the runtime and every module
+func (p *WASMPlugins) Close() error {
+	return p.runtime.Close(context.Background())
+}
+
+// Functions returns the names of the template functions the modules provide
+func (p *WASMPlugins) Functions() []string {
+	names := make([]string, 0, len(p.funcs))
+	for name := range p.funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasPolicy reports whether a module provides a conflict policy
+func (p *WASMPlugins) HasPolicy() bool {
+	return p.policy != nil
+}
+
+// CallFunction runs a template function with its arguments
+func (p *WASMPlugins) CallFunction(name string, args ...string) (string, error) {
+	m, ok := p.funcs[name]
+	if !ok {
+		return "", fmt.Errorf("no WASM module provides the function %s", name)
+	}
+	var result wasmFuncResult
+	if err := m.call(wasmFuncPrefix+name, map[string][]string{"args": args}, &result); err != nil {
+		return "", err
+	}
+	if result.Error != "" {
+		return "", fmt.Errorf("%s: %s", name, result.Error)
+	}
+	return result.Result, nil
+}
+
+// ResolveConflict asks the conflict policy what to do with an existing file
+func (p *WASMPlugins) ResolveConflict(input ConflictInput) (*ConflictResolution, error) {
+	var resolution ConflictResolution
+	if err := p.policy.call(wasmPolicyExport, input, &resolution); err != nil {
+		return nil, err
+	}
+	switch resolution.Action {
+	case ConflictKeep, ConflictOverwrite, ConflictMerge:
+		return &resolution, nil
+	}
+	return nil, fmt.Errorf("%s: unknown conflict action %q", p.policy.name, resolution.Action)
+}
+
+// resolveConflict applies the conflict policy of the pack to a template whose
+// target already exists; identical files are skipped without asking
+func (e *Engine) resolveConflict(sourcePath, targetPath string, content []byte, mode fs.FileMode) error {
+	existing, err := e.fs.ReadFile(targetPath)
+	if err != nil || bytes.Equal(existing, content) {
+		// installFile reports directories in the way and skips the rest
+		return e.installFile(targetPath, nil, 0)
+	}
+	resolution, err := e.wasm.ResolveConflict(ConflictInput{
+		Path:     templateTarget(sourcePath),
+		Template: string(content),
+		Existing: string(existing),
+	})
+	if err == nil {
+		switch resolution.Action {
+		case ConflictKeep:
+			return e.installFile(targetPath, nil, 0)
+		case ConflictMerge:
+			content = []byte(resolution.Content)
+		}
+		err = e.updateFile(targetPath, mode, func([]byte) ([]byte, error) {
+			return content, nil
+		})
+	}
+	if err != nil {
+		e.logger.Error("%v", err)
+		e.stats.Errors = append(e.stats.Errors, err)
+	}
+	return err
+}
+
+// call passes input as JSON to an exported function and decodes its output
+func (m *wasmModule) call(export string, input, output interface{}) error {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), wasmCallTimeout)
+	defer cancel()
+
+	results, err := m.alloc.Call(ctx, uint64(len(data)))
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", m.name, wasmAllocExport, err)
+	}
+	ptr := uint32(results[0])
+	if !m.memory.Write(ptr, data) {
+		return fmt.Errorf("%s: %s returned an invalid buffer", m.name, wasmAllocExport)
+	}
+	results, err = m.module.ExportedFunction(export).Call(ctx, uint64(ptr), uint64(len(data)))
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", m.name, export, err)
+	}
+	out, ok := m.memory.Read(uint32(results[0]>>32), uint32(results[0]))
+	if !ok {
+		return fmt.Errorf("%s: %s returned an invalid buffer", m.name, export)
+	}
+	if err := json.Unmarshal(out, output); err != nil {
+		return fmt.Errorf("%s: %s returned invalid JSON: %w", m.name, export, err)
+	}
+	return nil
+}
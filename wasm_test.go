@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// wasmTestModule assembles a module exporting cc_init_alloc with the type
+// allocType and cc_init_fn_x, with or without an exported memory. Type 0 is
+// (i32) -> i32 and type 1 is (i32, i32) -> i64; both functions return 0.
+func wasmTestModule(memory bool, allocType byte) []byte {
+	section := func(id byte, content ...byte) []byte {
+		return append([]byte{id, byte(len(content))}, content...)
+	}
+	name := func(s string) []byte { return append([]byte{byte(len(s))}, s...) }
+
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	module = append(module, section(1, 0x02, 0x60, 0x01, 0x7f, 0x01, 0x7f, 0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e)...)
+	module = append(module, section(3, 0x02, allocType, 0x01)...)
+	if memory {
+		module = append(module, section(5, 0x01, 0x00, 0x01)...)
+	}
+	exports := []byte{0x02}
+	if memory {
+		exports = append(append([]byte{0x03}, name("memory")...), 0x02, 0x00)
+	}
+	exports = append(append(exports, name(wasmAllocExport)...), 0x00, 0x00)
+	exports = append(append(exports, name(wasmFuncPrefix+"x")...), 0x00, 0x01)
+	module = append(module, section(7, exports...)...)
+	allocBody := []byte{0x04, 0x00, 0x41, 0x00, 0x0b} // i32.const 0
+	if allocType == 1 {
+		allocBody = []byte{0x04, 0x00, 0x42, 0x00, 0x0b} // i64.const 0
+	}
+	code := append([]byte{0x02}, allocBody...)
+	code = append(code, 0x04, 0x00, 0x42, 0x00, 0x0b)
+	return append(module, section(10, code...)...)
+}
+
+func TestLoadWASMPluginsChecksModules(t *testing.T) {
+	tests := []struct {
+		name      string
+		module    []byte
+		loadError string
+	}{
+		{"no memory", wasmTestModule(false, 0), "exports no memory"},
+		{"alloc signature", wasmTestModule(true, 1), wasmAllocExport + " takes 2 parameters"},
+		{"valid", wasmTestModule(true, 0), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pack := fstest.MapFS{"plugin.wasm": {Data: tt.module}}
+			plugins, err := LoadWASMPlugins(pack, []string{"plugin.wasm"})
+			if tt.loadError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.loadError) {
+					t.Fatalf("LoadWASMPlugins = %v, want an error containing %q", err, tt.loadError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer plugins.Close()
+			// The function returns an empty buffer, which is not JSON
+			if _, err := plugins.CallFunction("x"); err == nil || !strings.Contains(err.Error(), "invalid JSON") {
+				t.Fatalf("CallFunction = %v, want invalid JSON", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// watchInterval is how often --watch polls the template pack for changes
+const watchInterval = 500 * time.Millisecond
+
+// packSignature summarizes the paths, sizes and modification times of the
+// files below dir; it changes whenever a file is saved, added or removed
+func packSignature(dir string) (string, error) {
+	var b strings.Builder
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s\x00%d\x00%d\n", p, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return b.String(), err
+}
+
+// runWatch applies the templates of --template-dir, then applies them again,
+// overwriting earlier output, whenever a file in the pack changes. It only
+// returns when the pack can no longer be read.
+func runWatch(templateFS fs.FS, config *Config) error {
+	config.Overwrite = true
+	logger := NewLogger(config.Verbose, config.NoColor)
+	last := ""
+	for {
+		signature, err := packSignature(config.TemplateDir)
+		if err != nil {
+			return fmt.Errorf("failed to watch %s: %w", config.TemplateDir, err)
+		}
+		if signature != last {
+			if last != "" {
+				logger.Blank()
+				logger.Info("Template pack changed, applying again")
+			}
+			last = signature
+
+			engine, err := NewEngine(templateFS, config)
+			if err != nil {
+				return err
+			}
+			err = engine.Run()
+			if closeErr := engine.Close(); err == nil {
+				err = closeErr
+			}
+			// A broken template must not end the session; fixing it triggers
+			// the next run
+			if err != nil {
+				fmt.Fprintf(os.Stderr, tr("Error: %v\n"), err)
+			}
+			logger.Info("Watching %s for changes (press Ctrl+C to stop)", config.TemplateDir)
+		}
+		time.Sleep(watchInterval)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// windowsReservedNames are device names Windows refuses as file names, with
+// or without an extension. COM and LPT also take the superscript digits ¹, ²
+// and ³, which Windows counts as 1, 2 and 3.
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true, "CONIN$": true, "CONOUT$": true,
+	"COM0": true, "COM1": true, "COM2": true, "COM3": true, "COM4": true,
+	"COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"COM¹": true, "COM²": true, "COM³": true,
+	"LPT0": true, "LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true,
+	"LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+	"LPT¹": true, "LPT²": true, "LPT³": true,
+}
+
+// windowsReservedName reports whether name is a reserved device name such as
+// "con" or "nul.txt"
+func windowsReservedName(name string) bool {
+	base, _, _ := strings.Cut(name, ".")
+	return windowsReservedNames[strings.ToUpper(strings.TrimRight(base, " "))]
+}
+
+// checkWindowsPath reports why a slash-separated relative path cannot be
+// created on Windows, or nil if it can
+func checkWindowsPath(rel string) error {
+	for _, part := range strings.Split(rel, "/") {
+		switch {
+		case windowsReservedName(part):
+			return fmt.Errorf("%s: %q is a reserved device name on Windows", rel, part)
+		case strings.ContainsAny(part, `<>:"\|?*`):
+			return fmt.Errorf("%s: %q contains characters not allowed on Windows", rel, part)
+		case (part != "." && part != ".." && strings.HasSuffix(part, ".")) || strings.HasSuffix(part, " "):
+			return fmt.Errorf("%s: %q ends with a dot or space, which Windows strips", rel, part)
+		}
+	}
+	return nil
+}
+
+// windowsMaxDirPath is the longest path Windows creates a directory at
+// without the \\?\ prefix: MAX_PATH less room for an 8.3 file name
+const windowsMaxDirPath = 248
+
+// windowsLongPath rewrites an absolute Windows path in the \\?\ form,
+// which lifts the MAX_PATH limit. That form is passed to the file system
+// as is, so separators are normalized to backslashes and "." and ".."
+// elements resolved first. Paths already in the form are returned
+// unchanged, and relative paths only get backslashes.
+func windowsLongPath(p string) string {
+	if strings.HasPrefix(p, `\\?\`) {
+		return p
+	}
+	p = strings.ReplaceAll(p, "/", `\`)
+	var prefix, rest string
+	switch {
+	case strings.HasPrefix(p, `\\`):
+		// UNC path \\server\share\...
+		parts := strings.SplitN(p[2:], `\`, 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return p
+		}
+		prefix = `\\?\UNC\` + parts[0] + `\` + parts[1]
+		if len(parts) == 3 {
+			rest = parts[2]
+		}
+	case len(p) >= 3 && p[1] == ':' && p[2] == '\\' && isASCIILetter(p[0]):
+		prefix, rest = `\\?\`+p[:2], p[3:]
+	default:
+		return p
+	}
+
+	var elems []string
+	for _, elem := range strings.Split(rest, `\`) {
+		switch elem {
+		case "", ".":
+		case "..":
+			if len(elems) > 0 {
+				elems = elems[:len(elems)-1]
+			}
+		default:
+			elems = append(elems, elem)
+		}
+	}
+	return prefix + `\` + strings.Join(elems, `\`)
+}
+
+// isASCIILetter reports whether c is a drive letter
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// longPath prepares a path for the file system calls of OSFileSystem: on
+// Windows, paths too long for MAX_PATH get the \\?\ prefix and the others
+// use backslashes throughout; elsewhere paths are returned unchanged
+func longPath(p string) string {
+	if runtime.GOOS != "windows" {
+		return p
+	}
+	if len(p) < windowsMaxDirPath {
+		return filepath.Clean(p)
+	}
+	return windowsLongPath(p)
+}
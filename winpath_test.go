@@ -0,0 +1,143 @@
+package main
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWindowsReservedName(t *testing.T) {
+	tests := []struct {
+		name     string
+		reserved bool
+	}{
+		{"CON", true},
+		{"con", true},
+		{"nul.txt", true},
+		{"NUL.tar.gz", true},
+		{"aux ", true},
+		{"Prn .md", true},
+		{"COM0", true},
+		{"com9.log", true},
+		{"LPT0", true},
+		{"lpt5", true},
+		{"COM¹", true},
+		{"lpt³.txt", true},
+		{"CONIN$", true},
+		{"conout$.txt", true},
+		{"COM10", false},
+		{"LPT", false},
+		{"console", false},
+		{"connect.md", false},
+		{"nul_device", false},
+		{"CLAUDE.md", false},
+		{"COM⁴", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := windowsReservedName(tt.name); got != tt.reserved {
+			t.Errorf("windowsReservedName(%q) = %v, want %v", tt.name, got, tt.reserved)
+		}
+	}
+}
+
+func TestCheckWindowsPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string // a substring of the error, or "" for none
+	}{
+		{"agents/spec-executor.md", ""},
+		{"commands/ask.md", ""},
+		{"./hooks/../settings.json", ""},
+		{".claude/settings.local.json", ""},
+		{"agents/con.md", "reserved device name"},
+		{"AUX/notes.md", "reserved device name"},
+		{"hooks/lpt¹.sh", "reserved device name"},
+		{"commands/what?.md", "characters not allowed"},
+		{"commands/a:b.md", "characters not allowed"},
+		{`commands\ask.md`, "characters not allowed"},
+		{"agents/pipe|name.md", "characters not allowed"},
+		{"agents/trailing.", "ends with a dot or space"},
+		{"agents/trailing /x.md", "ends with a dot or space"},
+	}
+	for _, tt := range tests {
+		err := checkWindowsPath(tt.path)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("checkWindowsPath(%q) = %v, want nil", tt.path, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("checkWindowsPath(%q) = %v, want an error containing %q", tt.path, err, tt.want)
+		}
+	}
+}
+
+func TestWindowsLongPath(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{`C:\repo\.claude\agents\a.md`, `\\?\C:\repo\.claude\agents\a.md`},
+		{`C:/repo/.claude/agents/a.md`, `\\?\C:\repo\.claude\agents\a.md`},
+		{`c:\repo/.claude\agents/a.md`, `\\?\c:\repo\.claude\agents\a.md`},
+		{`C:\repo\.\.claude\hooks\..\agents\\a.md`, `\\?\C:\repo\.claude\agents\a.md`},
+		{`C:\..\repo`, `\\?\C:\repo`},
+		{`C:\`, `\\?\C:\`},
+		{`\\server\share\repo\.claude`, `\\?\UNC\server\share\repo\.claude`},
+		{`//server/share/repo/.claude`, `\\?\UNC\server\share\repo\.claude`},
+		{`\\server\share`, `\\?\UNC\server\share\`},
+		{`\\?\C:\already\long`, `\\?\C:\already\long`},
+		{`\\?\UNC\server\share\x`, `\\?\UNC\server\share\x`},
+		{`repo/.claude/agents`, `repo\.claude\agents`},
+		{`C:relative\path`, `C:relative\path`},
+		{`\\server`, `\\server`},
+	}
+	for _, tt := range tests {
+		if got := windowsLongPath(tt.path); got != tt.want {
+			t.Errorf("windowsLongPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLongPathOnlyRewritesOnWindows(t *testing.T) {
+	long := filepath.Join(t.TempDir(), strings.Repeat("d", windowsMaxDirPath))
+	got := longPath(long)
+	if runtime.GOOS != "windows" {
+		if got != long {
+			t.Fatalf("longPath changed %q to %q outside Windows", long, got)
+		}
+		return
+	}
+	if !strings.HasPrefix(got, `\\?\`) {
+		t.Fatalf("longPath(%q) = %q, want the \\\\?\\ prefix", long, got)
+	}
+	short := `C:/repo/.claude`
+	if got := longPath(short); got != `C:\repo\.claude` {
+		t.Fatalf("longPath(%q) = %q, want backslashes", short, got)
+	}
+}
+
+func TestOSFileSystemBeyondMaxPath(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("MAX_PATH only limits paths on Windows")
+	}
+	dir := t.TempDir()
+	deep := dir
+	for len(deep) < 300 {
+		deep = filepath.Join(deep, strings.Repeat("x", 40))
+	}
+	path := filepath.Join(deep, "settings.json")
+	fs := NewOSFileSystem()
+	if err := fs.WriteFile(path, []byte("{}\n"), 0644); err != nil {
+		t.Fatalf("WriteFile beyond MAX_PATH: %v", err)
+	}
+	content, err := fs.ReadFile(path)
+	if err != nil || string(content) != "{}\n" {
+		t.Fatalf("ReadFile beyond MAX_PATH = %q, %v", content, err)
+	}
+	if !fs.Exists(path) {
+		t.Fatalf("Exists(%q) = false", path)
+	}
+	if err := fs.Remove(path); err != nil {
+		t.Fatalf("Remove beyond MAX_PATH: %v", err)
+	}
+}
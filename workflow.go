@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// workflowsDir is where GitHub Actions workflows live in the target
+const workflowsDir = ".github/workflows"
+
+// actionUsesPattern matches the `uses:` line of a workflow step that names
+// an action in a GitHub repository, capturing the repository and the ref
+var actionUsesPattern = regexp.MustCompile(`(?m)^(\s*-?\s*uses:\s*)([\w.-]+/[\w.-]+)((?:/[\w./-]+)?)@([\w./-]+)[ \t]*$`)
+
+// commitSHAPattern matches a full commit SHA, which needs no pinning
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// releaseTagPattern matches the version tags actions are released under
+var releaseTagPattern = regexp.MustCompile(`^v\d+(\.\d+)*$`)
+
+// WorkflowPreset is a GitHub Actions workflow shipped with cc-init
+type WorkflowPreset struct {
+	Description string
+	Preset      string
+	File        string
+}
+
+// workflowCatalog lists the workflows available to `cc-init add workflow`
+var workflowCatalog = map[string]WorkflowPreset{
+	"claude-review": {
+		Description: "Review pull requests with Claude",
+		Preset:      "claude-review.yml",
+		File:        "claude.yml",
+	},
+	"claude-triage": {
+		Description: "Label and triage new issues with Claude",
+		Preset:      "claude-triage.yml",
+		File:        "claude-triage.yml",
+	},
+}
+
+// workflowNames returns the sorted names of the workflow presets
+func workflowNames() []string {
+	names := make([]string, 0, len(workflowCatalog))
+	for name := range workflowCatalog {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runAdd implements `cc-init add <kind> <name>`
+func runAdd(args []string) error {
+	config := &Config{}
+	fs := newCommandFlagSet(findCommand("add"), config)
+	defaultUsage := fs.Usage
+	fs.Usage = func() {
+		defaultUsage()
+		fmt.Fprint(fs.Output(), tr("\nWorkflows:\n"))
+		for _, name := range workflowNames() {
+			preset := workflowCatalog[name]
+			fmt.Fprintf(fs.Output(), "  %-16s %s (%s/%s)\n", name, preset.Description, workflowsDir, preset.File)
+		}
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 || positional[0] != "workflow" {
+		fs.Usage()
+		return fmt.Errorf("expected: cc-init add workflow <name>")
+	}
+
+	name := positional[1]
+	if _, ok := workflowCatalog[name]; !ok {
+		return fmt.Errorf("unknown workflow %q (available: %s)", name, strings.Join(workflowNames(), ", "))
+	}
+
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	engine, err := NewEngine(templateFiles(), config)
+	if err != nil {
+		return err
+	}
+	err = engine.Apply(func() error { return engine.addWorkflow(name) })
+	if closeErr := engine.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// addWorkflow installs the named workflow preset into .github/workflows
+func (e *Engine) addWorkflow(name string) error {
+	preset := workflowCatalog[name]
+	content, err := presetFS.ReadFile(path.Join("presets", "workflows", preset.Preset))
+	if err != nil {
+		return fmt.Errorf("failed to read workflow preset %s: %w", name, err)
+	}
+
+	dir := filepath.Join(e.config.TargetDir, filepath.FromSlash(workflowsDir))
+	if err := e.processDirectory(dir); err != nil {
+		return err
+	}
+	content = pinWorkflowActions(content, resolveActionRef, e.logger)
+	if err := e.installFile(filepath.Join(dir, preset.File), content, 0644); err != nil {
+		return err
+	}
+
+	e.logger.Info("Add the ANTHROPIC_API_KEY repository secret before enabling %s", preset.File)
+	return nil
+}
+
+// pinWorkflowActions rewrites each action a workflow uses by tag to the
+// commit the tag points at, keeping the release in a comment, so a moved tag
+// cannot change what the workflow runs. An action whose tag cannot be
+// resolved keeps it, with a warning.
+func pinWorkflowActions(content []byte, resolve func(repo, ref string) (string, string, error), logger *Logger) []byte {
+	return actionUsesPattern.ReplaceAllFunc(content, func(line []byte) []byte {
+		m := actionUsesPattern.FindSubmatch(line)
+		prefix, repo, subpath, ref := string(m[1]), string(m[2]), string(m[3]), string(m[4])
+		if commitSHAPattern.MatchString(ref) {
+			return line
+		}
+		sha, tag, err := resolve(repo, ref)
+		if err != nil {
+			logger.Warning("Could not pin %s@%s to a commit, so it stays on the tag: %v", repo, ref, err)
+			return line
+		}
+		return []byte(fmt.Sprintf("%s%s%s@%s # %s", prefix, repo, subpath, sha, tag))
+	})
+}
+
+// resolveActionRef asks GitHub for the commit a tag of an action's
+// repository points at, and the most specific release tag on that commit
+func resolveActionRef(repo, ref string) (string, string, error) {
+	out, err := gitRun("", "ls-remote", "--tags", "https://github.com/"+repo)
+	if err != nil {
+		return "", "", err
+	}
+	return parseActionTags(out, ref)
+}
+
+// parseActionTags finds in the output of `git ls-remote --tags` the commit
+// ref points at, and the release tag with the most components on it: v1
+// becomes the v1.2.3 it currently points at
+func parseActionTags(out, ref string) (string, string, error) {
+	commits := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "refs/tags/") {
+			continue
+		}
+		tag := strings.TrimPrefix(fields[1], "refs/tags/")
+		// An annotated tag is listed again with ^{}, for the commit it tags
+		if peeled := strings.TrimSuffix(tag, "^{}"); peeled != tag {
+			commits[peeled] = fields[0]
+		} else if _, ok := commits[tag]; !ok {
+			commits[tag] = fields[0]
+		}
+	}
+	sha, ok := commits[ref]
+	if !ok {
+		return "", "", fmt.Errorf("no tag %s", ref)
+	}
+	best := ref
+	for tag, commit := range commits {
+		if commit != sha || !releaseTagPattern.MatchString(tag) {
+			continue
+		}
+		if n, m := strings.Count(tag, "."), strings.Count(best, "."); n > m || n == m && compareVersions(tag, best) > 0 {
+			best = tag
+		}
+	}
+	return sha, best, nil
+}
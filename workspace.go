@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Managed CLAUDE.md sections written by --workspaces: the package list at the
+// root and the facts of each package in its own directory
+const (
+	workspaceSection = "workspace"
+	packageSection   = "package"
+)
+
+// Workspace is a monorepo whose packages are listed by a workspace manifest
+type Workspace struct {
+	// Kind names the manifest: go.work, pnpm, npm or Cargo
+	Kind string
+	// Packages are the member directories, relative to the root
+	Packages []string
+}
+
+// WorkspacePackage is an analyzed member of a workspace
+type WorkspacePackage struct {
+	Dir string
+	*ProjectInfo
+}
+
+// packageMDHeader starts a newly created CLAUDE.md of a workspace package
+const packageMDHeader = `# CLAUDE.md
+
+Guidance for this package. Shared conventions live in the CLAUDE.md at the
+workspace root, which Claude Code also reads.
+`
+
+// workspaceSectionTemplate lists the packages at the workspace root
+var workspaceSectionTemplate = template.Must(template.New("workspace").Funcs(template.FuncMap{"join": strings.Join}).Parse(`## Workspace
+
+This is a {{.Kind}} workspace. Each package has its own CLAUDE.md with its
+build and test commands; run them from the package directory.
+{{range .Packages}}
+- ` + "`{{.Dir}}/`" + `{{if .ModulePath}} ({{.ModulePath}}){{end}}{{if .Languages}}: {{join .Languages ", "}}{{end}}
+{{- end}}
+`))
+
+// packageSectionTemplate renders the facts of one workspace package
+var packageSectionTemplate = template.Must(template.New("package").Funcs(template.FuncMap{"join": strings.Join}).Parse(`## Package
+
+` + "`{{.Dir}}`" + `{{if .ModulePath}} is ` + "`{{.ModulePath}}`" + `{{end}}{{if .Languages}}, written in {{join .Languages ", "}}{{end}}.
+{{- if or .BuildCommands .TestCommands .LintCommands}}
+{{with .BuildCommands}}
+- **Build**: ` + "`{{index . 0}}`" + `
+{{- end}}
+{{- with .TestCommands}}
+- **Test**: ` + "`{{index . 0}}`" + `
+{{- end}}
+{{- with .LintCommands}}
+- **Lint**: ` + "`{{index . 0}}`" + `
+{{- end}}
+{{- end}}
+`))
+
+// goWorkUsePattern matches a single-line `use ./dir` directive of go.work
+var goWorkUsePattern = regexp.MustCompile(`^use\s+(\S+)$`)
+
+// cargoMembersPattern matches the members array of a Cargo workspace
+var cargoMembersPattern = regexp.MustCompile(`(?s)\[workspace\][^\[]*?members\s*=\s*\[([^\]]*)\]`)
+
+// quotedPattern matches a double-quoted TOML string
+var quotedPattern = regexp.MustCompile(`"([^"]+)"`)
+
+// DetectWorkspace reads the workspace manifest of dir: go.work,
+// pnpm-workspace.yaml, the workspaces of package.json, or the [workspace]
+// table of Cargo.toml. It returns nil when dir is not a workspace root.
+func DetectWorkspace(dir string) (*Workspace, error) {
+	var kind string
+	var patterns []string
+	if data, err := os.ReadFile(filepath.Join(dir, "go.work")); err == nil {
+		kind, patterns = "go.work", goWorkUses(data)
+	} else if data, err := os.ReadFile(filepath.Join(dir, "pnpm-workspace.yaml")); err == nil {
+		var manifest struct {
+			Packages []string `yaml:"packages"`
+		}
+		if err := yaml.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("invalid pnpm-workspace.yaml: %w", err)
+		}
+		kind, patterns = "pnpm", manifest.Packages
+	} else if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil && bytes.Contains(data, []byte(`"workspaces"`)) {
+		var pkg struct {
+			Workspaces json.RawMessage `json:"workspaces"`
+		}
+		if err := json.Unmarshal(data, &pkg); err != nil {
+			return nil, fmt.Errorf("invalid package.json: %w", err)
+		}
+		// workspaces is either a list or {"packages": [...]}
+		if err := json.Unmarshal(pkg.Workspaces, &patterns); err != nil {
+			var nested struct {
+				Packages []string `json:"packages"`
+			}
+			if err := json.Unmarshal(pkg.Workspaces, &nested); err != nil {
+				return nil, fmt.Errorf("invalid workspaces in package.json: %w", err)
+			}
+			patterns = nested.Packages
+		}
+		kind = "npm"
+	} else if data, err := os.ReadFile(filepath.Join(dir, "Cargo.toml")); err == nil {
+		m := cargoMembersPattern.FindSubmatch(data)
+		if m == nil {
+			return nil, nil
+		}
+		for _, q := range quotedPattern.FindAllSubmatch(m[1], -1) {
+			patterns = append(patterns, string(q[1]))
+		}
+		kind = "Cargo"
+	} else {
+		return nil, nil
+	}
+
+	ws := &Workspace{Kind: kind}
+	seen := map[string]bool{}
+	var excluded []string
+	for _, pattern := range patterns {
+		if p, ok := strings.CutPrefix(pattern, "!"); ok {
+			excluded = append(excluded, filepath.Clean(p))
+			continue
+		}
+		// Globs match one level: packages/** is read as packages/*
+		pattern = strings.ReplaceAll(filepath.Clean(filepath.FromSlash(pattern)), "**", "*")
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace pattern %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			rel, err := filepath.Rel(dir, match)
+			if err != nil || rel == "." || !filepath.IsLocal(rel) || seen[rel] {
+				continue
+			}
+			if info, err := os.Stat(match); err != nil || !info.IsDir() {
+				continue
+			}
+			seen[rel] = true
+			ws.Packages = append(ws.Packages, rel)
+		}
+	}
+	ws.Packages = filterExcluded(ws.Packages, excluded)
+	sort.Strings(ws.Packages)
+	return ws, nil
+}
+
+// goWorkUses returns the directories of the use directives of go.work
+func goWorkUses(data []byte) []string {
+	var dirs []string
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case line == "use (":
+			inBlock = true
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			dirs = append(dirs, line)
+		default:
+			if m := goWorkUsePattern.FindStringSubmatch(line); m != nil {
+				dirs = append(dirs, m[1])
+			}
+		}
+	}
+	return dirs
+}
+
+// filterExcluded drops the packages matching a negated workspace pattern
+func filterExcluded(packages, excluded []string) []string {
+	var kept []string
+	for _, p := range packages {
+		drop := false
+		for _, pattern := range excluded {
+			if ok, _ := filepath.Match(strings.ReplaceAll(pattern, "**", "*"), p); ok {
+				drop = true
+			}
+		}
+		if !drop {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// analyzePackage inspects a workspace member. Lockfiles live at the root of
+// JavaScript workspaces, so members take the package manager of the root.
+func analyzePackage(root *ProjectInfo, dir, rel string) WorkspacePackage {
+	info := AnalyzeProject(dir)
+	if info.PackageManager == "npm" && root.PackageManager != "" && root.PackageManager != "npm" {
+		for _, commands := range [][]string{info.BuildCommands, info.TestCommands, info.LintCommands} {
+			for i, command := range commands {
+				if rest, ok := strings.CutPrefix(command, "npm run "); ok {
+					commands[i] = root.PackageManager + " run " + rest
+				}
+			}
+		}
+		info.PackageManager = root.PackageManager
+	}
+	return WorkspacePackage{Dir: filepath.ToSlash(rel), ProjectInfo: info}
+}
+
+// generateWorkspaces lists the packages of a workspace in the root CLAUDE.md
+// and writes a short CLAUDE.md into each package, for --workspaces
+func (e *Engine) generateWorkspaces() error {
+	if !e.config.Workspaces {
+		return nil
+	}
+	ws, err := DetectWorkspace(e.config.TargetDir)
+	if err != nil {
+		return err
+	}
+	if ws == nil || len(ws.Packages) == 0 {
+		e.logger.Warning("No workspace packages found; --workspaces reads go.work, pnpm-workspace.yaml, package.json workspaces and Cargo.toml")
+		return nil
+	}
+
+	root := AnalyzeProject(e.config.TargetDir)
+	var packages []WorkspacePackage
+	for _, rel := range ws.Packages {
+		packages = append(packages, analyzePackage(root, filepath.Join(e.config.TargetDir, rel), rel))
+	}
+	var buf bytes.Buffer
+	err = workspaceSectionTemplate.Execute(&buf, struct {
+		Kind     string
+		Packages []WorkspacePackage
+	}{ws.Kind, packages})
+	if err != nil {
+		return fmt.Errorf("failed to render workspace section: %w", err)
+	}
+	e.queueSections(ManagedSection{Name: workspaceSection, Body: buf.String()})
+
+	for _, pkg := range packages {
+		buf.Reset()
+		if err := packageSectionTemplate.Execute(&buf, pkg); err != nil {
+			return fmt.Errorf("failed to render package section: %w", err)
+		}
+		body := buf.String()
+		targetPath := filepath.Join(e.config.TargetDir, filepath.FromSlash(pkg.Dir), claudeMDFile)
+		err := e.updateFile(targetPath, 0644, func(existing []byte) ([]byte, error) {
+			doc := string(existing)
+			if existing == nil {
+				doc = packageMDHeader
+			}
+			return []byte(upsertManagedSection(doc, packageSection, body)), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
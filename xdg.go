@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appDirName is the directory cc-init uses inside each base directory
+const appDirName = "cc-init"
+
+// userDir returns the cc-init directory inside the base directory named by
+// env, which on every platform wins when set to an absolute path as the XDG
+// base directory specification asks, and inside fallback otherwise
+func userDir(env string, fallback func() (string, error)) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appDirName), nil
+	}
+	dir, err := fallback()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName), nil
+}
+
+// userConfigDir returns where the user configuration lives:
+// $XDG_CONFIG_HOME/cc-init, else ~/.config/cc-init on Linux,
+// ~/Library/Application Support/cc-init on macOS and %AppData%\cc-init on
+// Windows
+func userConfigDir() (string, error) {
+	return userDir("XDG_CONFIG_HOME", os.UserConfigDir)
+}
+
+// userCacheDir returns where downloads are kept: $XDG_CACHE_HOME/cc-init,
+// else ~/.cache/cc-init on Linux, ~/Library/Caches/cc-init on macOS and
+// %LocalAppData%\cc-init on Windows
+func userCacheDir() (string, error) {
+	return userDir("XDG_CACHE_HOME", os.UserCacheDir)
+}
+
+// userStateDir returns where state that belongs to no project lives:
+// $XDG_STATE_HOME/cc-init, else ~/.local/state/cc-init on Linux,
+// ~/Library/Application Support/cc-init on macOS and
+// %LocalAppData%\cc-init on Windows
+func userStateDir() (string, error) {
+	return userDir("XDG_STATE_HOME", defaultStateDir)
+}
+
+// defaultStateDir is the platform's base directory for state, which Go has
+// no function for
+func defaultStateDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return dir, nil
+		}
+		return "", errors.New("%LocalAppData% is not defined")
+	case "darwin", "ios":
+		return os.UserConfigDir()
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state"), nil
+}
+
+// userPaths returns the locations cc-init uses outside projects, in the
+// order `cc-init paths` prints them
+func userPaths() ([][2]string, error) {
+	config, err := userConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	telemetry, err := telemetrySettingsPath()
+	if err != nil {
+		return nil, err
+	}
+	cache, err := userCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	templates, err := templateCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	state, err := userStateDir()
+	if err != nil {
+		return nil, err
+	}
+	return [][2]string{
+		{"config", config},
+		{"telemetry", telemetry},
+		{"cache", cache},
+		{"templates", templates},
+		{"state", state},
+	}, nil
+}
+
+// runPaths implements `cc-init paths [name]`: it prints the resolved
+// locations, or only the one named, for use in scripts
+func runPaths(args []string) error {
+	cmd := findCommand("paths")
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, tr("Usage: cc-init %s\n\n%s\n\nFlags:\n"), cmd.Usage, cmd.Summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		fs.Usage()
+		return fmt.Errorf("expected at most one location name")
+	}
+	paths, err := userPaths()
+	if err != nil {
+		return err
+	}
+
+	if len(positional) == 1 {
+		for _, p := range paths {
+			if p[0] == positional[0] {
+				fmt.Println(p[1])
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown location %q (expected config, telemetry, cache, templates or state)", positional[0])
+	}
+	rows := make([][]string, 0, len(paths))
+	for _, p := range paths {
+		rows = append(rows, []string{p[0], p[1]})
+	}
+	writeTable(os.Stdout, []string{tr("NAME"), tr("PATH")}, rows)
+	return nil
+}